@@ -0,0 +1,535 @@
+async function c4_init() {
+
+setTimeout(() => {
+    const rows = 6
+    const columns = 7
+
+    class C4Player {
+        name: string
+        wins: number
+        connected: boolean
+
+        constructor(name: string, connected: boolean) {
+          this.name = name
+          this.connected = connected
+          this.wins = 0
+        }
+    }
+
+    class State {
+        player1: C4Player
+        player2: C4Player
+        board: number[][]
+        status: number
+        ties: number
+
+        constructor() {
+           this.player1 = new C4Player("", false)
+           this.player2 = new C4Player("", false)
+           this.board = empty_board()
+           this.ties = 0
+           this.status = 0
+        }
+    }
+
+    // The event types and error codes come from the schema in
+    // internal/protocol, see protocol.ts
+    const C4EventType = Protocol.connectfour.EventType
+    type C4EventType = Protocol.connectfour.EventType
+
+    type C4Event = {
+        type: C4EventType,
+        data?: any,
+        isError?: boolean,
+        error?: C4EventError,
+        seq?: number
+    }
+
+    // The error of an event the server refused, the codes are shared by
+    // every game
+    type C4EventError = Protocol.ws.Error
+
+    let c4_socket = new WebSocket(Protocol.socketURL(Protocol.connectfour.Path))
+
+    // A player that loses its socket joins its room again with the sequence
+    // number of the last event it got, the server replays what it missed or
+    // sends the whole state
+    let c4_room_code = ""
+    let c4_last_seq = 0
+    // c4_seq_lost is set once an event came out of order, the next rejoin
+    // asks for the whole state instead of what was missed
+    let c4_seq_lost = false
+    let c4_leaving = false
+
+    let c4_create_btn = document.getElementById("c4_create_btn") as HTMLButtonElement
+    let c4_join_btn = document.getElementById("c4_join_btn") as HTMLButtonElement
+    let c4_find_btn = document.getElementById("c4_find_btn") as HTMLButtonElement
+    let c4_spectate_btn = document.getElementById("c4_spectate_btn") as HTMLButtonElement
+    let c4_code_input = document.getElementById("c4_code") as HTMLInputElement
+    let c4_allow_spectators = document.getElementById("c4_allow_spectators") as HTMLInputElement
+    let c4_spectators_label = document.getElementById("c4_spectators_label") as HTMLParagraphElement
+    let c4_code_label = document.getElementById("c4_code_label") as HTMLParagraphElement
+
+    let scoreboard = document.getElementById("c4_scoreboard") as HTMLDivElement
+    let player1_label = document.getElementById("c4_player1_label") as HTMLParagraphElement
+    let player1_wins = document.getElementById("c4_player1_wins") as HTMLParagraphElement
+    let player2_label = document.getElementById("c4_player2_label") as HTMLParagraphElement
+    let player2_wins = document.getElementById("c4_player2_wins") as HTMLParagraphElement
+    let ties_label = document.getElementById("c4_ties") as HTMLParagraphElement
+    ties_label.style.color = "green"
+
+    let c4_chat = document.getElementById("c4_chat") as HTMLDivElement
+    let c4_chat_messages = document.getElementById("c4_chat_messages") as HTMLDivElement
+    let c4_chat_form = document.getElementById("c4_chat_form") as HTMLFormElement
+    let c4_chat_input = document.getElementById("c4_chat_input") as HTMLInputElement
+
+    let board_el = document.getElementById("c4_board") as HTMLDivElement
+    let cells = document.querySelectorAll<HTMLDivElement>('#c4_board .cell')
+
+    c4_create_btn.addEventListener("click", c4_create_game)
+    c4_join_btn.addEventListener("click", c4_join_game)
+    c4_find_btn.addEventListener("click", c4_find_match)
+    c4_spectate_btn.addEventListener("click", c4_spectate_game)
+    c4_chat_form.addEventListener("submit", c4_send_chat)
+
+    let state = new State()
+
+    let clicked_create = false
+    let spectating = false
+    let searching = false
+
+    function empty_board(): number[][] {
+        return Array.from({ length: rows }, () => new Array(columns).fill(0))
+    }
+
+    function c4_create_game(): void {
+        c4_send_event({
+            type: C4EventType.CreateGame,
+            data: {
+                allow_spectators: c4_allow_spectators.checked,
+            }
+        })
+        clicked_create = true
+    }
+
+    function c4_join_game(): void {
+        c4_send_event({
+            type: C4EventType.JoinGame,
+            data: {
+                code: c4_code_input?.value
+            }
+        })
+        clicked_create = true
+    }
+
+    function c4_spectate_game(): void {
+        c4_send_event({
+            type: C4EventType.SpectateGame,
+            data: {
+                code: c4_code_input?.value
+            }
+        })
+    }
+
+    function c4_find_match(): void {
+        c4_send_event({
+            type: searching ? C4EventType.CancelFindMatch : C4EventType.FindMatch,
+        })
+    }
+
+    function c4_set_searching(queued: boolean): void {
+        searching = queued
+        c4_find_btn.textContent = searching ? "Cancel Search" : "Find Match"
+    }
+
+    function c4_send_chat(e: SubmitEvent): void {
+        e.preventDefault()
+        const text = c4_chat_input.value.trim()
+        if (text == "") {
+            return
+        }
+        c4_send_event({
+            type: C4EventType.PlayerSendMessage,
+            data: { text: text }
+        })
+        c4_chat_input.value = ""
+    }
+
+    function c4_send_event(ev: C4Event): void {
+        c4_socket.send(JSON.stringify(ev))
+    }
+
+    function c4_on_message(e: MessageEvent): void {
+        const event = JSON.parse(e.data) as C4Event
+        if (!event.type) {
+            throw new Error("Receive event without type")
+        }
+        if (event.isError !== undefined) {
+            c4_handle_event_error(event)
+            return
+        }
+        c4_track_seq(event)
+        c4_handle_event(event)
+    }
+
+    // c4_track_seq keeps the last sequence number got, the ones sent along
+    // the whole state start over from there
+    function c4_track_seq(event: C4Event): void {
+        switch (event.type) {
+            case C4EventType.JoinedGame:
+            case C4EventType.StateUpdate:
+            case C4EventType.SpectateGame:
+                c4_last_seq = event.seq ?? 0
+                c4_seq_lost = false
+                break
+            default:
+                if (event.seq && event.seq < c4_last_seq) {
+                    c4_seq_lost = true
+                }
+                if (!c4_seq_lost) {
+                    c4_last_seq = Math.max(c4_last_seq, event.seq ?? 0)
+                }
+        }
+    }
+
+    function c4_on_close(e: CloseEvent): void {
+        if (e.code == Protocol.CloseProtocolMismatch) {
+            c4_show_notification("The game was updated, reload the page to keep playing")
+            return
+        }
+        if (c4_leaving || c4_room_code == "" || spectating) {
+            return
+        }
+        setTimeout(c4_reconnect, 1000)
+    }
+
+    function c4_reconnect(): void {
+        c4_socket = new WebSocket(Protocol.socketURL(Protocol.connectfour.Path))
+        c4_socket.addEventListener("message", c4_on_message)
+        c4_socket.addEventListener("close", c4_on_close)
+        c4_socket.addEventListener("open", () => {
+            c4_send_event({
+                type: C4EventType.JoinGame,
+                data: {
+                    code: c4_room_code,
+                    last_seq: c4_seq_lost ? 0 : c4_last_seq,
+                }
+            })
+        })
+    }
+
+    c4_socket.addEventListener("message", c4_on_message)
+    c4_socket.addEventListener("close", c4_on_close)
+
+    // Accepting the invite of a friend comes here with the room to join
+    const c4_invite_code = new URLSearchParams(window.location.search).get("join")
+    if (c4_invite_code) {
+        history.replaceState(null, "", window.location.pathname)
+        c4_socket.addEventListener("open", () => {
+            c4_code_input.value = c4_invite_code
+            c4_join_game()
+        }, { once: true })
+    }
+
+    function c4_handle_event(event: C4Event): void {
+        switch (event.type) {
+            case C4EventType.JoinedGame:
+                c4_set_searching(false)
+                handle_c4_joined_game(event)
+                break
+            case C4EventType.FindMatch:
+            case C4EventType.CancelFindMatch:
+                c4_set_searching(event.data?.queued ?? false)
+                break
+            case C4EventType.SpectateGame:
+                spectating = true
+                handle_c4_joined_game({ ...event, data: event.data.state })
+                handle_c4_spectators_update(event)
+                break
+            case C4EventType.SpectatorsUpdate:
+                handle_c4_spectators_update(event)
+                break
+            case C4EventType.OtherPlayerJoined:
+                state.player2.name = event.data.username
+                state.player2.connected = event.data.connected
+                state.player2.wins = event.data.wins
+                update_scoreboard()
+                break
+            case C4EventType.StateUpdate:
+                set_state(event.data)
+                break
+            case C4EventType.DiscDropped:
+                update_cell(event.data.row, event.data.col, event.data.value)
+                break
+            case C4EventType.Tie:
+                handle_c4_result(event, "It's a Tie!", "")
+                break
+            case C4EventType.Victory:
+            case C4EventType.Defeat:
+                handle_c4_finished(event)
+                break
+            case C4EventType.PlayerDisconnected:
+            case C4EventType.PlayerReconnected:
+                handle_c4_player_connection(event)
+                break
+            case C4EventType.ReconnectCountdown:
+                countdown_label.innerText = event.data.username + " left, " + event.data.seconds + "s to come back"
+                board_el.insertAdjacentElement("afterend", countdown_label)
+                break
+            case C4EventType.Forfeit:
+                handle_c4_forfeit(event)
+                break
+            case C4EventType.PlayerSendMessage:
+                c4_add_chat_message(event.data)
+                break
+            case C4EventType.ChatHistory:
+                c4_chat_messages.replaceChildren()
+                for (const message of event.data.messages ?? []) {
+                    c4_add_chat_message(message)
+                }
+                c4_chat.classList.remove("is-hidden")
+                break
+            default:
+                console.log("Unknown event")
+                console.log(event)
+                break
+        }
+    }
+
+    function c4_handle_event_error(event: C4Event): void {
+        console.log("Event gave an error: " + event.type)
+        switch (event.error?.code) {
+            case Protocol.ws.ErrorCode.NotYourTurn:
+                c4_show_notification("Wait for your turn")
+                return
+            case Protocol.ws.ErrorCode.ColumnFull:
+                c4_show_notification("That column is full")
+                return
+            case Protocol.ws.ErrorCode.GameNotRunning:
+                c4_show_notification("Waiting for the other player")
+                return
+        }
+        const message = event.error?.message ?? event.data?.message
+        if (message) {
+            c4_show_notification(message)
+        }
+    }
+
+    function handle_c4_spectators_update(event: C4Event): void {
+        if (event.data) {
+            const count: number = event.data.spectators
+            c4_spectators_label.innerText = count > 0 ? count + " watching" : ""
+        }
+    }
+
+    function handle_c4_joined_game(event: C4Event): void {
+        const menu = document.getElementById("c4_room_menu") as HTMLDivElement
+        menu.style.display = "none"
+        const options = document.getElementById("c4_room_options") as HTMLDivElement
+        options.style.display = "none"
+
+        c4_code_label.innerText = event.data.code
+        c4_room_code = event.data.code
+        if (clicked_create) {
+            c4_show_notification(event.data.code)
+            navigator.clipboard.writeText(event.data.code)
+        }
+        set_state(event.data)
+    }
+
+    function set_state(data: any): void {
+        for (const [player, from] of [[state.player1, data.player1], [state.player2, data.player2]]) {
+            player.name = from?.username ?? ""
+            player.connected = from?.connected ?? false
+            player.wins = from?.wins ?? 0
+        }
+        state.board = data.board
+        state.status = data.status
+        state.ties = data.ties
+        update_scoreboard()
+        update_board()
+    }
+
+    async function handle_c4_finished(event: C4Event): Promise<void> {
+        state.player1.wins = event.data.player1.wins
+        state.player2.wins = event.data.player2.wins
+        const winner = event.data.winner == 1 ? event.data.player1 : event.data.player2
+        let result = event.type == C4EventType.Victory ? "You Win!" : "You Lose!"
+        if (spectating) {
+            result = winner.username + " Wins!"
+        }
+        const rated = event.type == C4EventType.Victory ? winner.username : (event.data.winner == 1 ? event.data.player2 : event.data.player1).username
+        await handle_c4_result(event, result, rating_delta_text(event.data.ratings, rated))
+    }
+
+    // handle_c4_result shows the last disc and the line it made, then the
+    // board is cleared for the next game
+    async function handle_c4_result(event: C4Event, result: string, rating: string): Promise<void> {
+        if (event.data.ties !== undefined) {
+            state.ties = event.data.ties
+        }
+        update_cell(event.data.row, event.data.col, event.data.value)
+        await show_result(result + rating, event.data.line ?? [])
+        state.board = empty_board()
+        update_board()
+        update_scoreboard()
+    }
+
+    function handle_c4_player_connection(event: C4Event): void {
+        countdown_label.remove()
+        for (const player of [state.player1, state.player2]) {
+            if (player.name == event.data.username) {
+                player.connected = event.type == C4EventType.PlayerReconnected
+                player.wins = event.data.wins
+            }
+        }
+        update_scoreboard()
+    }
+
+    const countdown_label = document.createElement("p") as HTMLParagraphElement
+    countdown_label.classList.add("has-text-danger")
+
+    // handle_c4_forfeit gives the game to the player left, the slot of the
+    // one that forfeited is free for someone else
+    async function handle_c4_forfeit(event: C4Event): Promise<void> {
+        countdown_label.remove()
+        const winner = event.data.winner == 1 ? event.data.player1 : event.data.player2
+        const result = spectating ? winner.username + " Wins by forfeit!" : event.data.forfeited + " forfeited, you win!"
+        await show_result(result + rating_delta_text(event.data.ratings, winner.username), [])
+
+        const left = event.data.winner == 1 ? state.player2 : state.player1
+        left.name = ""
+        left.connected = false
+        state.player1.wins = 0
+        state.player2.wins = 0
+        state.ties = 0
+        state.board = empty_board()
+        update_board()
+        update_scoreboard()
+    }
+
+    function c4_add_chat_message(message: { from: string, text: string }): void {
+        const line = document.createElement("p")
+        const from = document.createElement("strong")
+        from.textContent = message.from + ": "
+        line.append(from, message.text)
+        c4_chat_messages.append(line)
+        c4_chat_messages.scrollTop = c4_chat_messages.scrollHeight
+    }
+
+    function c4_show_notification(message: string) {
+        const notification = document.createElement("div")
+        notification.innerText = message
+        notification.style.position = "fixed"
+        notification.style.bottom = "20px"
+        notification.style.left = "50%"
+        notification.style.transform = "translateX(-50%)"
+        notification.style.backgroundColor = "#333"
+        notification.style.color = "#fff"
+        notification.style.padding = "10px 20px"
+        notification.style.borderRadius = "5px"
+        notification.style.opacity = "0"
+        notification.style.transition = "opacity 0.3s"
+        notification.style.fontSize = "12px"
+
+        document.body.appendChild(notification)
+        setTimeout(() => notification.style.opacity = "1", 10)
+        setTimeout(() => {
+            notification.style.opacity = "0"
+            setTimeout(() => notification.remove(), 300)
+        }, 2000)
+    }
+
+    const waiting_label = document.createElement("p") as HTMLParagraphElement
+    waiting_label.innerText = "Waiting for 2nd player..."
+
+    function update_scoreboard(): void {
+        if (state.player1.name != "" && state.player2.name == "") {
+            board_el.insertAdjacentElement("afterend", waiting_label)
+            scoreboard.style.visibility = "hidden"
+            return
+        }
+        waiting_label.remove()
+        scoreboard.style.visibility = state.player1.name == "" ? "hidden" : "visible"
+
+        player1_label.innerText = state.player1.name.toUpperCase() + " (RED)"
+        player1_wins.innerText = state.player1.wins.toString()
+        player1_label.style.color = state.player1.connected ? "green" : "red"
+
+        player2_label.innerText = state.player2.name.toUpperCase() + " (YELLOW)"
+        player2_wins.innerText = state.player2.wins.toString()
+        player2_label.style.color = state.player2.connected ? "green" : "red"
+
+        ties_label.innerText = state.ties.toString()
+    }
+
+    function update_board(): void {
+        for (let row = 0; row < rows; row++) {
+            for (let col = 0; col < columns; col++) {
+                paint_cell(row, col, state.board[row][col])
+            }
+        }
+    }
+
+    function update_cell(row: number, col: number, value: number): void {
+        state.board[row][col] = value
+        paint_cell(row, col, value)
+    }
+
+    function paint_cell(row: number, col: number, value: number): void {
+        const cell = cells[row * columns + col]
+        cell.classList.toggle("red", value == 1)
+        cell.classList.toggle("yellow", value == 2)
+    }
+
+    update_scoreboard()
+
+    cells.forEach((cell) => {
+        cell.addEventListener("click", () => {
+            if (spectating) {
+                return
+            }
+            c4_send_event({
+                type: C4EventType.DropDisc,
+                data: {
+                    col: Number(cell.dataset.col),
+                }
+            })
+        })
+    })
+
+    // rating_delta_text formats the rating change of a player sent with the game result
+    function rating_delta_text(ratings: any, username: string): string {
+        if (!ratings || !ratings[username]) {
+            return ""
+        }
+        const change = ratings[username]
+        const sign = change.delta >= 0 ? "+" : ""
+        return " (" + change.rating + ", " + sign + change.delta + ")"
+    }
+
+    async function show_result(result: string, line: { row: number, col: number }[]): Promise<void> {
+        return new Promise((resolve) => {
+            const overlay = document.getElementById("c4_result_overlay") as HTMLDivElement
+            const text = document.getElementById("c4_result_text") as HTMLParagraphElement
+            text.textContent = result
+            overlay.classList.remove("is-hidden")
+
+            const winning = line.map(({ row, col }) => cells[row * columns + col])
+            winning.forEach((cell) => cell.classList.add("winning-cell"))
+
+            setTimeout(() => {
+                overlay.classList.add("is-hidden")
+                winning.forEach((cell) => cell.classList.remove("winning-cell"))
+                resolve()
+            }, 1500)
+        })
+    }
+
+    document.body.addEventListener('htmx:afterOnLoad', function() {
+        c4_leaving = true
+        c4_socket.close()
+    });
+
+    }, 200);
+}
@@ -0,0 +1,143 @@
+async function friends_init() {
+
+setTimeout(() => {
+    // The event types come from the schema in internal/protocol, see
+    // protocol.ts
+    const FriendsEventType = Protocol.presence.EventType
+    type FriendsEventType = Protocol.presence.EventType
+
+    type FriendsEvent = {
+        type: FriendsEventType,
+        data?: any,
+        isError?: boolean
+    }
+
+    type Invite = Protocol.presence.Invite
+    type InviteAnswer = Protocol.presence.InviteAnswer
+
+    // The navbar comes back with every full page swap, one socket is enough
+    const friends_window = window as any
+    if (friends_window.friends_socket) {
+        return
+    }
+
+    let friends_socket: WebSocket
+    // accepting is the invite accepted in this tab, the answer coming back
+    // takes it to the room
+    let accepting = ""
+
+    function invite_key(invite: Invite): string {
+        return invite.from + "/" + invite.game + "/" + invite.code
+    }
+
+    function friends_connect(): void {
+        friends_socket = new WebSocket(Protocol.socketURL(Protocol.presence.Path))
+        friends_window.friends_socket = friends_socket
+        friends_socket.addEventListener("message", friends_on_message)
+        friends_socket.addEventListener("close", (e: CloseEvent) => {
+            // A page of another version keeps quiet until it is reloaded
+            if (e.code != Protocol.CloseProtocolMismatch) {
+                setTimeout(friends_connect, 3000)
+            }
+        })
+    }
+
+    function friends_on_message(e: MessageEvent): void {
+        const event = JSON.parse(e.data) as FriendsEvent
+        if (event.isError) {
+            return
+        }
+        switch (event.type) {
+            case FriendsEventType.Invite:
+                show_invite(event.data as Invite)
+                break
+            case FriendsEventType.InviteAnswer:
+                show_answer(event.data as InviteAnswer)
+                break
+            case FriendsEventType.InviteAnswered:
+                handle_invite_answered(event.data as InviteAnswer)
+                break
+        }
+    }
+
+    function invites_div(): HTMLDivElement {
+        return document.getElementById("friends_invites") as HTMLDivElement
+    }
+
+    function show_invite(invite: Invite): void {
+        const notification = document.createElement("div")
+        notification.classList.add("notification", "is-info")
+        notification.dataset.invite = invite_key(invite)
+
+        const text = document.createElement("p")
+        text.textContent = invite.from + " invited you to " + invite.game + " room " + invite.code
+
+        const buttons = document.createElement("div")
+        buttons.classList.add("buttons", "mt-2")
+        const accept = document.createElement("button")
+        accept.classList.add("button", "is-success", "is-small")
+        accept.textContent = "Accept"
+        accept.addEventListener("click", () => answer_invite(invite, true))
+        const decline = document.createElement("button")
+        decline.classList.add("button", "is-small")
+        decline.textContent = "Decline"
+        decline.addEventListener("click", () => answer_invite(invite, false))
+        buttons.append(accept, decline)
+
+        notification.append(text, buttons)
+        invites_div()?.append(notification)
+    }
+
+    function answer_invite(invite: Invite, accepted: boolean): void {
+        remove_invite(invite_key(invite))
+        const answer: InviteAnswer = { ...invite, accepted: accepted }
+        friends_socket.send(JSON.stringify({
+            type: FriendsEventType.InviteAnswer,
+            data: answer
+        }))
+        if (accepted) {
+            // An invite to a room that closed meanwhile gets no answer back,
+            // joining tells why
+            accepting = invite_key(invite)
+            setTimeout(() => join_invite(invite), 1000)
+        }
+    }
+
+    function join_invite(invite: Invite): void {
+        if (accepting != invite_key(invite)) {
+            return
+        }
+        accepting = ""
+        // The game page joins the room in the join query, like typing the
+        // code in
+        window.location.href = "/" + invite.game + "/home?join=" + encodeURIComponent(invite.code)
+    }
+
+    function remove_invite(key: string): void {
+        invites_div()?.querySelectorAll("[data-invite]").forEach((el) => {
+            if ((el as HTMLElement).dataset.invite == key) {
+                el.remove()
+            }
+        })
+    }
+
+    // handle_invite_answered drops the invite from every tab of the invited
+    // user, the tab that accepted it goes to the room
+    function handle_invite_answered(answer: InviteAnswer): void {
+        remove_invite(invite_key(answer))
+        if (answer.accepted) {
+            join_invite(answer)
+        }
+    }
+
+    function show_answer(answer: InviteAnswer): void {
+        const notification = document.createElement("div")
+        notification.classList.add("notification", answer.accepted ? "is-success" : "is-warning")
+        notification.textContent = answer.to + (answer.accepted ? " accepted" : " declined") + " your invite to " + answer.game
+        invites_div()?.append(notification)
+        setTimeout(() => notification.remove(), 5000)
+    }
+
+    friends_connect()
+    }, 200);
+}
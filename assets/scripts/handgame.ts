@@ -0,0 +1,387 @@
+async function hg_init() {
+
+setTimeout(() => {
+    class HgPlayer {
+        name: string
+        wins: number
+        connected: boolean
+        committed: boolean
+
+        constructor(name: string, connected: boolean) {
+            this.name = name
+            this.connected = connected
+            this.wins = 0
+            this.committed = false
+        }
+    }
+
+    class HgState {
+        code: string
+        player_num: number
+        player1: HgPlayer
+        player2: HgPlayer
+        best_of: number
+        round: number
+        ties: number
+        status: number
+        my_hand: number
+
+        constructor() {
+            this.code = ""
+            this.player_num = 0
+            this.player1 = new HgPlayer("", false)
+            this.player2 = new HgPlayer("", false)
+            this.best_of = 3
+            this.round = 1
+            this.ties = 0
+            this.status = 0
+            this.my_hand = 0
+        }
+    }
+
+    // The event types come from the schema in internal/protocol, see
+    // protocol.ts
+    const HgEventType = Protocol.handgame.EventType
+    type HgEventType = Protocol.handgame.EventType
+
+    type HgEvent = {
+        type: HgEventType,
+        data?: any,
+        isError?: boolean
+    }
+
+    const hand_names = ["", "Rock", "Paper", "Scissors"]
+
+    let hg_socket = new WebSocket(Protocol.socketURL(Protocol.handgame.Path))
+    hg_socket.addEventListener("close", (e: CloseEvent) => {
+        if (e.code == Protocol.CloseProtocolMismatch) {
+            hg_status_label.innerText = "The game was updated, reload the page to keep playing"
+        }
+    })
+
+    let hg_create_btn = document.getElementById("hg_create_btn") as HTMLButtonElement
+    let hg_join_btn = document.getElementById("hg_join_btn") as HTMLButtonElement
+    let hg_rematch_btn = document.getElementById("hg_rematch_btn") as HTMLButtonElement
+    let hg_best_of = document.getElementById("hg_best_of") as HTMLSelectElement
+
+    let hg_arena = document.getElementById("hg_arena") as HTMLDivElement
+    let hg_rooms = document.getElementById("hg_rooms") as HTMLDivElement
+    let hg_code_label = document.getElementById("hg_code_label") as HTMLParagraphElement
+    let hg_round_label = document.getElementById("hg_round_label") as HTMLParagraphElement
+    let hg_status_label = document.getElementById("hg_status_label") as HTMLParagraphElement
+
+    let player1_label = document.getElementById("hg_player1_label") as HTMLParagraphElement
+    let player1_wins = document.getElementById("hg_player1_wins") as HTMLParagraphElement
+    let player2_label = document.getElementById("hg_player2_label") as HTMLParagraphElement
+    let player2_wins = document.getElementById("hg_player2_wins") as HTMLParagraphElement
+    let ties_label = document.getElementById("hg_ties") as HTMLParagraphElement
+
+    let hand_btns = document.querySelectorAll('#hg_hands .hg-hand')
+
+    let hg_chat = document.getElementById("hg_chat") as HTMLDivElement
+    let hg_chat_messages = document.getElementById("hg_chat_messages") as HTMLDivElement
+    let hg_chat_form = document.getElementById("hg_chat_form") as HTMLFormElement
+    let hg_chat_input = document.getElementById("hg_chat_input") as HTMLInputElement
+
+    let state = new HgState()
+
+    hg_create_btn.addEventListener("click", () => {
+        hg_send_event({
+            type: HgEventType.CreateGame,
+            data: {
+                best_of: parseInt(hg_best_of.value),
+            }
+        })
+    })
+
+    hg_join_btn.addEventListener("click", () => {
+        const code_input = document.getElementById("hg_code") as HTMLInputElement
+        hg_join_game(code_input.value)
+    })
+
+    hg_rooms.addEventListener("click", (e) => {
+        const target = e.target as HTMLElement
+        if (target.classList.contains("hg-room-join") && target.dataset.code) {
+            hg_join_game(target.dataset.code)
+        }
+    })
+
+    hg_rematch_btn.addEventListener("click", () => {
+        hg_send_event({ type: HgEventType.RequestRematch })
+        hg_rematch_btn.disabled = true
+    })
+
+    hand_btns.forEach((btn) => {
+        btn.addEventListener("click", () => {
+            const hand = parseInt((btn as HTMLElement).dataset.hand ?? "0")
+            hg_send_event({
+                type: HgEventType.CommitHand,
+                data: {
+                    hand: hand,
+                }
+            })
+        })
+    })
+
+    hg_chat_form.addEventListener("submit", (e) => {
+        e.preventDefault()
+        const text = hg_chat_input.value.trim()
+        if (text == "") {
+            return
+        }
+        hg_send_event({
+            type: HgEventType.ChatMessage,
+            data: {
+                text: text,
+            }
+        })
+        hg_chat_input.value = ""
+    })
+
+    function hg_join_game(code: string): void {
+        if (code == "") {
+            return
+        }
+        hg_send_event({
+            type: HgEventType.JoinGame,
+            data: {
+                code: code,
+            }
+        })
+    }
+
+    function hg_send_event(ev: HgEvent): void {
+        hg_socket.send(JSON.stringify(ev))
+    }
+
+    hg_socket.addEventListener("message", (e) => {
+        const event = JSON.parse(e.data) as HgEvent
+        if (!event.type) {
+            console.log("Receive event without type")
+            return
+        }
+        if (event.isError) {
+            hg_handle_event_error(event)
+            return
+        }
+        hg_handle_event(event)
+    })
+
+    // Accepting the invite of a friend comes here with the room to join
+    const hg_invite_code = new URLSearchParams(window.location.search).get("join")
+    if (hg_invite_code) {
+        history.replaceState(null, "", window.location.pathname)
+        hg_socket.addEventListener("open", () => hg_join_game(hg_invite_code), { once: true })
+    }
+
+    function hg_handle_event(event: HgEvent): void {
+        switch (event.type) {
+            case HgEventType.JoinedGame:
+            case HgEventType.StateUpdate:
+                handle_hg_state(event)
+                break
+            case HgEventType.OtherPlayerJoined:
+                handle_hg_other_player_joined(event)
+                break
+            case HgEventType.HandCommitted:
+                state.my_hand = event.data.hand
+                update_hands()
+                break
+            case HgEventType.OpponentCommitted:
+                hg_status_label.innerText = "Your opponent has picked a hand"
+                break
+            case HgEventType.RoundResult:
+                handle_hg_round_result(event)
+                break
+            case HgEventType.MatchFinished:
+                handle_hg_match_finished(event)
+                break
+            case HgEventType.RematchRequested:
+                if (event.data.username != my_player().name) {
+                    hg_status_label.innerText = event.data.username + " wants a rematch"
+                }
+                break
+            case HgEventType.PlayerDisconnected:
+            case HgEventType.PlayerReconnected:
+                handle_hg_player_connection(event)
+                break
+            case HgEventType.ReconnectCountdown:
+                hg_status_label.innerText = event.data.username + " left, " + event.data.seconds + "s to come back"
+                break
+            case HgEventType.Forfeit:
+                if (event.data.forfeited != my_player().name) {
+                    hg_status_label.innerText = event.data.forfeited + " did not come back, you won the match! Waiting for 2nd player..."
+                }
+                break
+            case HgEventType.ChatMessage:
+                hg_add_chat_message(event.data)
+                break
+            case HgEventType.ChatHistory:
+                handle_hg_chat_history(event)
+                break
+            default:
+                console.log("Unknown event")
+                console.log(event)
+                break
+        }
+    }
+
+    function hg_handle_event_error(event: HgEvent): void {
+        console.log("Event gave an error: " + event.type)
+        if (event.data) {
+            alert(event.data.message)
+        }
+    }
+
+    // handle_hg_chat_history replaces the chat with the scrollback of the
+    // room, it comes with every join
+    function handle_hg_chat_history(event: HgEvent): void {
+        hg_chat_messages.replaceChildren()
+        for (const message of event.data.messages ?? []) {
+            hg_add_chat_message(message)
+        }
+        hg_chat.classList.remove("is-hidden")
+    }
+
+    function hg_add_chat_message(message: { from: string, text: string }): void {
+        const line = document.createElement("p")
+        const from = document.createElement("strong")
+        from.textContent = message.from + ": "
+        line.append(from, message.text)
+        hg_chat_messages.append(line)
+        hg_chat_messages.scrollTop = hg_chat_messages.scrollHeight
+    }
+
+    function handle_hg_state(event: HgEvent): void {
+        const data = event.data
+        state.code = data.code
+        state.player_num = data.player_num
+        state.best_of = data.best_of
+        state.round = data.round
+        state.ties = data.ties
+        state.status = data.status
+        state.my_hand = data.my_hand
+
+        set_player(state.player1, data.player1)
+        set_player(state.player2, data.player2)
+
+        const menu = document.getElementById("hg_room_menu") as HTMLDivElement
+        menu.style.display = "none"
+        hg_rooms.classList.add("is-hidden")
+        hg_arena.classList.remove("is-hidden")
+        hg_code_label.innerText = state.code
+
+        hg_rematch_btn.disabled = false
+        hg_rematch_btn.classList.toggle("is-hidden", state.status != 2)
+        hg_status_label.innerText = state.status == 0 ? "Waiting for 2nd player..." : ""
+
+        update_scoreboard()
+        update_hands()
+    }
+
+    function handle_hg_other_player_joined(event: HgEvent): void {
+        const other = state.player_num == 1 ? state.player2 : state.player1
+        set_player(other, event.data)
+        state.status = 1
+        hg_status_label.innerText = ""
+        update_scoreboard()
+    }
+
+    function handle_hg_round_result(event: HgEvent): void {
+        const round = event.data.round
+        set_player(state.player1, event.data.player1)
+        set_player(state.player2, event.data.player2)
+        state.ties = event.data.ties
+        state.round = round.number + 1
+        state.my_hand = 0
+
+        const mine = state.player_num == 1 ? round.hand1 : round.hand2
+        const theirs = state.player_num == 1 ? round.hand2 : round.hand1
+        let result = "Tie"
+        if (round.winner == state.player_num) {
+            result = "You win the round"
+        } else if (round.winner != 0) {
+            result = "You lose the round"
+        }
+        hg_status_label.innerText = hand_names[mine] + " vs " + hand_names[theirs] + " - " + result
+
+        update_scoreboard()
+        update_hands()
+    }
+
+    function handle_hg_match_finished(event: HgEvent): void {
+        state.status = 2
+        if (event.data.winner == state.player_num) {
+            hg_status_label.innerText = "You won the match!"
+        } else {
+            hg_status_label.innerText = "You lost the match!"
+        }
+        hg_rematch_btn.disabled = false
+        hg_rematch_btn.classList.remove("is-hidden")
+        update_hands()
+    }
+
+    function handle_hg_player_connection(event: HgEvent): void {
+        if (event.data.username == state.player1.name) {
+            set_player(state.player1, event.data)
+        } else if (event.data.username == state.player2.name) {
+            set_player(state.player2, event.data)
+        }
+        if (event.type == HgEventType.PlayerReconnected) {
+            hg_status_label.innerText = ""
+        }
+        update_scoreboard()
+    }
+
+    // set_player empties the slot of a player that left for good
+    function set_player(player: HgPlayer, data: any): void {
+        if (!data) {
+            player.name = ""
+            player.wins = 0
+            player.connected = false
+            player.committed = false
+            return
+        }
+        player.name = data.username
+        player.wins = data.wins
+        player.connected = data.connected
+        player.committed = data.committed
+    }
+
+    function my_player(): HgPlayer {
+        return state.player_num == 1 ? state.player1 : state.player2
+    }
+
+    function update_scoreboard(): void {
+        player1_label.innerText = state.player1.name.toUpperCase()
+        player1_wins.innerText = state.player1.wins.toString()
+        player1_label.style.color = state.player1.connected ? "green" : "red"
+
+        player2_label.innerText = state.player2.name.toUpperCase()
+        player2_wins.innerText = state.player2.wins.toString()
+        player2_label.style.color = state.player2.connected ? "green" : "red"
+
+        ties_label.innerText = state.ties.toString()
+        hg_round_label.innerText = "Round " + state.round + " - Best of " + state.best_of
+    }
+
+    function update_hands(): void {
+        hand_btns.forEach((btn) => {
+            const el = btn as HTMLButtonElement
+            const hand = parseInt(el.dataset.hand ?? "0")
+            el.disabled = state.status != 1 || state.my_hand != 0
+            el.classList.toggle("is-primary", hand == state.my_hand)
+        })
+    }
+
+    // the rooms table is polled through htmx too, only leaving the page closes the socket
+    document.body.addEventListener('htmx:afterOnLoad', function(event) {
+        const target = (event as CustomEvent).detail.target as HTMLElement
+        if (target && target.id == "hg_rooms_table") {
+            return
+        }
+        hg_socket.close()
+    });
+
+    }, 200);
+}
@@ -0,0 +1,87 @@
+async function lobby_init() {
+
+setTimeout(() => {
+    // The event types come from the schema in internal/protocol, see
+    // protocol.ts
+    const LobbyEventType = Protocol.presence.EventType
+    type LobbyEventType = Protocol.presence.EventType
+
+    type LobbyEvent = {
+        type: LobbyEventType,
+        data?: any,
+        isError?: boolean
+    }
+
+    type LobbyActivity = Protocol.presence.Activity
+    type LobbyUser = Protocol.presence.User
+    type LobbyRoom = Protocol.presence.Room
+
+    let lobby_socket: WebSocket
+
+    let lobby_users = document.getElementById("lobby_users") as HTMLUListElement
+    let lobby_rooms = document.getElementById("lobby_rooms") as HTMLUListElement
+
+    function lobby_connect(): void {
+        lobby_socket = new WebSocket(Protocol.socketURL(Protocol.presence.Path))
+        lobby_socket.addEventListener("message", lobby_on_message)
+        lobby_socket.addEventListener("close", (e: CloseEvent) => {
+            // The home page may have been left, htmx swaps the lists away
+            if (e.code != Protocol.CloseProtocolMismatch && document.body.contains(lobby_users)) {
+                setTimeout(lobby_connect, 3000)
+            }
+        })
+    }
+
+    function lobby_on_message(e: MessageEvent): void {
+        const event = JSON.parse(e.data) as LobbyEvent
+        if (event.isError || event.type != LobbyEventType.LobbyUpdate) {
+            return
+        }
+        if (!document.body.contains(lobby_users)) {
+            lobby_socket.close()
+            return
+        }
+        render_users(event.data.users ?? [])
+        render_rooms(event.data.rooms ?? [])
+    }
+
+    // activity_text words the activity like the home page does
+    function activity_text(user: LobbyUser): string {
+        if (user.activities.length == 0) {
+            return "idle"
+        }
+        return user.activities.map((activity) => {
+            const verb = activity.spectating ? "watching " : "in "
+            return verb + activity.game + " room " + activity.code
+        }).join(", ")
+    }
+
+    function render_users(users: LobbyUser[]): void {
+        lobby_users.replaceChildren()
+        for (const user of users) {
+            const item = document.createElement("li")
+            const name = document.createElement("strong")
+            name.textContent = user.username
+            const activity = document.createElement("span")
+            activity.classList.add("has-text-grey")
+            activity.textContent = " " + activity_text(user)
+            item.append(name, activity)
+            lobby_users.append(item)
+        }
+    }
+
+    function render_rooms(rooms: LobbyRoom[]): void {
+        lobby_rooms.replaceChildren()
+        for (const room of rooms) {
+            const item = document.createElement("li")
+            const link = document.createElement("a")
+            link.href = "/" + room.game + "/home"
+            link.textContent = room.game
+            item.append(link, " " + room.code + " by " + room.host + " (" + room.players + "/" + room.max_players + ")")
+            lobby_rooms.append(item)
+        }
+    }
+
+    lobby_connect()
+    }, 200);
+}
@@ -15,6 +15,7 @@ type SocketEvent = {
     from?: string,
     to?: string,
     isError?: Boolean,
+    seq?: number,
 }
 
 enum Direction {
@@ -30,28 +31,10 @@ enum GameStatus {
     NotStarted,
 }
 
-enum EventType {
-    Ping = 98,
-    Pong = 99,
-    
-    GameSettings = 0,
-    Message = 1,
-
-    CreateRoom = 21,
-    CreatedRoom = 22,
-
-    JoinRoom = 23,
-    JoinedRoom = 24,
-    PlayerJoinedRoom = 25,
-
-    PaddleMoved = 35,
-
-    BallShot = 36,
-    BallUpdate = 37,
-    Goal = 38,
-
-    PlayerDisconnected = 4,
-}
+// The event types come from the schema in internal/protocol, see
+// protocol.ts
+const EventType = Protocol.pong.EventType
+type EventType = Protocol.pong.EventType
 
 type Label = {
     x: number,
@@ -331,24 +314,60 @@ class GameState {
 const canvas_width = 640
 const canvas_height = 360
 
-const socket = new WebSocket("ws://localhost:8080/ws/pong");
+// The server sends ball updates and goals as protobuf binary frames when
+// the protobuf subprotocol is negotiated, json is used otherwise
+const subprotocol_protobuf = "pong.protobuf.v1"
+const subprotocol_json = "pong.json.v1"
+
+let socket = new WebSocket(Protocol.socketURL(Protocol.pong.Path), [subprotocol_protobuf, subprotocol_json]);
+socket.binaryType = "arraybuffer"
+
+// A player that loses its socket joins its room again with the sequence
+// number of the last event it got, the server replays what it missed or
+// sends the whole game state
+let last_seq = 0
+// seq_lost is set once an event came out of order, the next rejoin asks for
+// the whole state instead of what was missed
+let seq_lost = false
+let room_code = ""
 
 const room_form = document.getElementById("room-menu") as HTMLDivElement;
 const room_info_div = document.getElementById("roomInfo") as HTMLDivElement;
 
 const join_btn = document.getElementById("joinBtn") as HTMLButtonElement;
 const create_btn = document.getElementById("createBtn") as HTMLButtonElement;
+const find_btn = document.getElementById("findBtn") as HTMLButtonElement;
+const spectate_btn = document.getElementById("spectateBtn") as HTMLButtonElement;
+
+const room_options_div = document.getElementById("roomOptions") as HTMLDivElement;
+const allow_spectators_input = document.getElementById("allowSpectators") as HTMLInputElement;
+const ai_level_select = document.getElementById("aiLevel") as HTMLSelectElement;
+const spectators_label = document.getElementById("spectatorsLabel") as HTMLParagraphElement;
 
 const code_input = document.getElementById("code") as HTMLInputElement;
 
 const canvas = document.getElementById("gameCanvas") as HTMLCanvasElement;
 
+const chat_div = document.getElementById("pong_chat") as HTMLDivElement;
+const chat_messages = document.getElementById("pong_chat_messages") as HTMLDivElement;
+const chat_form = document.getElementById("pong_chat_form") as HTMLFormElement;
+const chat_input = document.getElementById("pong_chat_input") as HTMLInputElement;
+
 let game_state: GameState;
 let raf: number;
 let ms: number = 52;
 
 join_btn?.addEventListener("click", join_game);
 create_btn?.addEventListener("click", create_room)
+find_btn?.addEventListener("click", find_match)
+spectate_btn?.addEventListener("click", spectate_room)
+chat_form?.addEventListener("submit", send_chat)
+// Typing in the chat must not move the paddle nor shoot the ball
+chat_input?.addEventListener("keydown", (event) => event.stopPropagation())
+chat_input?.addEventListener("keyup", (event) => event.stopPropagation())
+
+let searching: boolean = false
+let spectating: boolean = false
 
 
 function measure_latency() {
@@ -430,7 +449,7 @@ function update(deltaTime: any) {
 }
 
 window.addEventListener("keydown", async (event) => {
-  if (game_state.status === GameStatus.Running) {
+  if (game_state.status === GameStatus.Running && !spectating) {
     if (event.key === "w") {
       game_state.p1.paddle.keys.up = true;
       let counter = 40
@@ -453,7 +472,7 @@ window.addEventListener("keydown", async (event) => {
 });
 
 window.addEventListener("keydown", (event) => {
-   if (event.key == "Space" || event.key == ' ') {
+   if ((event.key == "Space" || event.key == ' ') && !spectating) {
        console.log("Shot")
        const e: SocketEvent = {
            type:EventType.BallShot,
@@ -464,6 +483,9 @@ window.addEventListener("keydown", (event) => {
 });
 
 window.addEventListener("keyup", (event) => {
+    if (spectating) {
+        return
+    }
     if(event.key == "w" && game_state.status == GameStatus.Running) { 
         game_state.p1.paddle.keys.up = false
         const ev: SocketEvent = {
@@ -506,21 +528,82 @@ function animate(time: any) {
     raf = window.requestAnimationFrame(animate);
 }
 
-socket.addEventListener("open", () => {
-    measure_latency()
-});
-
-socket.addEventListener("message", (e) => {
-    const event = parse_event(e.data)
+function on_message(e: MessageEvent): void {
+    const event = e.data instanceof ArrayBuffer ? decode_frame(e.data) : parse_event(e.data)
     if (event.isError !== undefined) {
         if (event.isError == true) {
             handle_event_error(event)
             return
         }
     }       
+    track_seq(event)
     handle_event(event)
+}
+
+// track_seq keeps the last sequence number got, the ones sent along the
+// whole game state start over from there
+function track_seq(event: SocketEvent): void {
+    switch (event.type) {
+        case EventType.CreatedRoom:
+        case EventType.JoinedRoom:
+        case EventType.SyncGameState:
+        case EventType.SpectateRoom:
+            last_seq = event.seq ?? 0
+            seq_lost = false
+            break
+        default:
+            if (event.seq && event.seq < last_seq) {
+                seq_lost = true
+            }
+            if (!seq_lost) {
+                last_seq = Math.max(last_seq, event.seq ?? 0)
+            }
+    }
+}
+
+function on_close(e: CloseEvent): void {
+    if (e.code == Protocol.CloseProtocolMismatch) {
+        alert("The game was updated, reload the page to keep playing")
+        return
+    }
+    if (room_code == "" || spectating) {
+        return
+    }
+    setTimeout(reconnect, 1000)
+}
+
+function reconnect(): void {
+    socket = new WebSocket(Protocol.socketURL(Protocol.pong.Path), [subprotocol_protobuf, subprotocol_json]);
+    socket.binaryType = "arraybuffer"
+    socket.addEventListener("message", on_message)
+    socket.addEventListener("close", on_close)
+    socket.addEventListener("open", () => {
+        send_event({
+            type: EventType.JoinRoom,
+            data: {
+                code: room_code,
+                last_seq: seq_lost ? 0 : last_seq,
+            }
+        })
+    })
+}
+
+socket.addEventListener("open", () => {
+    measure_latency()
 });
 
+// Accepting the invite of a friend comes here with the room to join
+const invite_code = new URLSearchParams(window.location.search).get("join")
+if (invite_code) {
+    history.replaceState(null, "", window.location.pathname)
+    socket.addEventListener("open", () => {
+        code_input.value = invite_code
+        join_game()
+    }, { once: true })
+}
+socket.addEventListener("message", on_message)
+socket.addEventListener("close", on_close)
+
 function parse_event(data: any): SocketEvent {
     const event_data = JSON.parse(data);
     const event: SocketEvent = event_data as SocketEvent;
@@ -532,17 +615,146 @@ function parse_event(data: any): SocketEvent {
     return event
 }
 
+// A binary frame is one byte with the event type followed by the protobuf
+// message of that event (see pong_game.proto), it is decoded into the same
+// shape the json events have
+function decode_frame(buffer: ArrayBuffer): SocketEvent {
+    const bytes = new Uint8Array(buffer)
+    if (bytes.length < 1) {
+        throw new Error("Receive empty binary frame")
+    }
+    const type = bytes[0] as EventType
+    const message = new ProtoReader(bytes.subarray(1))
+
+    switch (type) {
+        case EventType.BallUpdate: {
+            const ball = decode_ball(message)
+            return { type: type, data: { x: ball.x, y: ball.y } } as SocketEvent
+        }
+        case EventType.Goal: {
+            const scores = decode_scores(message)
+            return { type: type, data: { player1_score: scores[0], player2_score: scores[1] } } as SocketEvent
+        }
+        default:
+            throw new Error("Receive binary frame with unknown type: " + type)
+    }
+}
+
+// ProtoReader reads the few wire types used by pong_game.proto
+class ProtoReader {
+    bytes: Uint8Array
+    view: DataView
+    pos: number = 0
+
+    constructor(bytes: Uint8Array) {
+        this.bytes = bytes
+        this.view = new DataView(bytes.buffer, bytes.byteOffset, bytes.byteLength)
+    }
+
+    done(): boolean {
+        return this.pos >= this.bytes.length
+    }
+
+    varint(): number {
+        let result = 0
+        let shift = 0
+        let byte: number
+        do {
+            byte = this.bytes[this.pos++]
+            result += (byte & 0x7f) * Math.pow(2, shift)
+            shift += 7
+        } while (byte & 0x80)
+        return result
+    }
+
+    float(): number {
+        const value = this.view.getFloat32(this.pos, true)
+        this.pos += 4
+        return value
+    }
+
+    sub(): ProtoReader {
+        const length = this.varint()
+        const reader = new ProtoReader(this.bytes.subarray(this.pos, this.pos + length))
+        this.pos += length
+        return reader
+    }
+
+    skip(wire_type: number): void {
+        switch (wire_type) {
+            case 0: this.varint(); break
+            case 1: this.pos += 8; break
+            case 2: this.pos += this.varint(); break
+            case 5: this.pos += 4; break
+            default: throw new Error("Unknown wire type: " + wire_type)
+        }
+    }
+}
+
+function decode_ball(reader: ProtoReader): { x: number, y: number, vx: number, vy: number } {
+    const ball = { x: 0, y: 0, vx: 0, vy: 0 }
+    while (!reader.done()) {
+        const tag = reader.varint()
+        switch (tag) {
+            case (1 << 3) | 5: ball.x = reader.float(); break
+            case (2 << 3) | 5: ball.y = reader.float(); break
+            case (3 << 3) | 5: ball.vx = reader.float(); break
+            case (4 << 3) | 5: ball.vy = reader.float(); break
+            default: reader.skip(tag & 7)
+        }
+    }
+    return ball
+}
+
+function decode_player_score(reader: ProtoReader): number {
+    let score = 0
+    while (!reader.done()) {
+        const tag = reader.varint()
+        if (tag == ((3 << 3) | 0)) {
+            score = reader.varint()
+        } else {
+            reader.skip(tag & 7)
+        }
+    }
+    return score
+}
+
+function decode_scores(reader: ProtoReader): [number, number] {
+    const scores: [number, number] = [0, 0]
+    while (!reader.done()) {
+        const tag = reader.varint()
+        switch (tag) {
+            case (1 << 3) | 2: scores[0] = decode_player_score(reader.sub()); break
+            case (2 << 3) | 2: scores[1] = decode_player_score(reader.sub()); break
+            default: reader.skip(tag & 7)
+        }
+    }
+    return scores
+}
+
 function handle_event(event: SocketEvent): void {
     switch (event.type) {
         case EventType.Pong:
             handle_pong(event)
             break;
         case EventType.CreatedRoom:
+            set_searching(false)
             handle_room_created(event)
             break;
         case EventType.JoinedRoom:
+            set_searching(false)
             handle_joined(event)
             break;
+        case EventType.FindMatch:
+        case EventType.CancelFindMatch:
+            handle_queue_update(event)
+            break;
+        case EventType.SpectateRoom:
+            handle_spectate_room(event)
+            break;
+        case EventType.SpectatorsUpdate:
+            handle_spectators_update(event)
+            break;
         case EventType.PlayerJoinedRoom:
             handle_player_joined(event)
             break;
@@ -555,9 +767,28 @@ function handle_event(event: SocketEvent): void {
         case EventType.Goal:
             handle_goal(event);
             break;
-        case EventType.PlayerDisconnected:
+        case EventType.GameFinished:
+            handle_game_finished(event);
+            break;
+        case EventType.UserDisconnected:
             handle_player_disconnect(event)
             break
+        case EventType.ReconnectCountdown:
+            handle_reconnect_countdown(event)
+            break
+        case EventType.PlayerReconnected:
+            handle_player_reconnected(event)
+            break
+        case EventType.SyncGameState:
+            set_searching(false)
+            handle_sync_game_state(event)
+            break
+        case EventType.Message:
+            add_chat_message(event.data)
+            break
+        case EventType.ChatHistory:
+            handle_chat_history(event)
+            break
         default:
             console.log("Unknown Event type: " + event.type)
             console.log(event)
@@ -573,6 +804,11 @@ function handle_event_error(event: SocketEvent): void {
         case EventType.Ping:
             console.log("Could not ping")
             break
+        case EventType.Message:
+            if (event.data) {
+                alert(event.data.message)
+            }
+            break
         default:
             console.log("Unknown Event type: " + event.type)
             console.log(event)
@@ -580,6 +816,38 @@ function handle_event_error(event: SocketEvent): void {
     }
 }
 
+function send_chat(e: SubmitEvent): void {
+    e.preventDefault()
+    const text = chat_input.value.trim()
+    if (text == "") {
+        return
+    }
+    send_event({
+        type: EventType.Message,
+        data: { text: text }
+    })
+    chat_input.value = ""
+}
+
+// handle_chat_history replaces the chat with the scrollback of the room, it
+// comes with every join
+function handle_chat_history(event: SocketEvent): void {
+    chat_messages.replaceChildren()
+    for (const message of event.data.messages ?? []) {
+        add_chat_message(message)
+    }
+    chat_div.classList.remove("is-hidden")
+}
+
+function add_chat_message(message: { from: string, text: string }): void {
+    const line = document.createElement("p")
+    const from = document.createElement("strong")
+    from.textContent = message.from + ": "
+    line.append(from, message.text)
+    chat_messages.append(line)
+    chat_messages.scrollTop = chat_messages.scrollHeight
+}
+
 function send_event(ev: SocketEvent): void {
     socket.send(JSON.stringify(ev))
 }
@@ -591,6 +859,58 @@ function handle_player_disconnect(event: SocketEvent): void {
     }
 }
 
+const countdown_label = document.createElement("p") as HTMLParagraphElement
+countdown_label.classList.add("has-text-danger")
+
+// handle_reconnect_countdown shows how long a player that dropped has left to
+// come back before it forfeits
+function handle_reconnect_countdown(event: SocketEvent): void {
+    if (event.data) {
+        countdown_label.innerText = event.data.username + " left, " + event.data.seconds + "s to come back"
+        room_info_div.insertAdjacentElement("beforeend", countdown_label)
+    }
+}
+
+function handle_player_reconnected(event: SocketEvent): void {
+    countdown_label.remove()
+    if (event.data) {
+        console.log("player " + event.data.username + " is back")
+        game_state.p2.isConnected = true
+    }
+}
+
+// handle_sync_game_state puts a player that came back where it was
+function handle_sync_game_state(event: SocketEvent): void {
+    if (!event.data) {
+        return
+    }
+    const state = event.data.state
+    const is_player_1 = state.player1 && state.player1.username == event.data.username
+    const me = is_player_1 ? state.player1 : state.player2
+    const other = is_player_1 ? state.player2 : state.player1
+    if (!me || !other) {
+        return
+    }
+    // A socket that reconnected is already showing the room
+    if (room_code != event.data.code) {
+        handle_joined({
+            ...event,
+            data: {
+                code: event.data.code,
+                username: event.data.username,
+                player: other.username,
+                is_player_1: !is_player_1,
+            },
+        })
+    }
+    game_state.p2.isConnected = other.connected
+    game_state.p1.paddle.move(me.paddle.position.y)
+    game_state.p2.paddle.move(other.paddle.position.y)
+    game_state.p1.score = state.player1.points
+    game_state.p2.score = state.player2.points
+    game_state.update_scores()
+}
+
 function handle_pong(event: SocketEvent): void {
     if (event.data) {
         const endTime = performance.now();
@@ -605,6 +925,7 @@ function handle_joined(event: SocketEvent): void {
     if(event.data) {
         console.log(event)
         room_form.style.display = "none"
+        room_options_div.style.display = "none"
         var roomTitle = document.createElement("h1")
         roomTitle.classList.add("subtitle")
         roomTitle.classList.add("is-4")
@@ -617,6 +938,7 @@ function handle_joined(event: SocketEvent): void {
         game_state.p2.isConnected = true
         game_state.p2.username = event.data.player        
         game_state.code = event.data.code
+        room_code = event.data.code
         if (event.data.is_player_1) {
             game_state.swap_players()
         } else {
@@ -642,7 +964,9 @@ function handle_player_joined(event: SocketEvent): void {
 }
 
 function handle_room_created(event: SocketEvent): void {
+    room_options_div.style.display = "none"
     game_state.code = event.data.code
+    room_code = event.data.code
     if (event.data) {
         game_state.p1.username = event.data.username
     }
@@ -691,6 +1015,10 @@ function showNotification(message: string) {
 
 function handle_paddle_move(event: SocketEvent): void {
     if(event.data){
+        if (spectating && event.data.username == game_state.p1.username) {
+            game_state.p1.paddle.move(event.data.y)
+            return
+        }
         game_state.p2.paddle.move(event.data.y)
     }
 }
@@ -716,13 +1044,117 @@ function handle_goal(event: SocketEvent): void {
     }
 }
 
+function handle_game_finished(event: SocketEvent): void {
+    if(event.data) {
+        game_state.p1.score = event.data.player1_score
+        game_state.p2.score = event.data.player2_score
+        game_state.update_scores()
+        console.log("Game finished, winner: " + event.data.winner)
+        if (event.data.forfeited) {
+            countdown_label.remove()
+            showNotification(event.data.forfeited + " forfeited, " + event.data.winner + " wins")
+        }
+    }
+}
+
+// How quickly the computer paddle reacts and how far off it aims
+const ai_levels: Record<string, { reaction_ms: number, error: number }> = {
+    easy: { reaction_ms: 300, error: 40 },
+    normal: { reaction_ms: 150, error: 15 },
+    hard: { reaction_ms: 60, error: 5 },
+}
+
 function create_room(): void {
     const event = {
         type: EventType.CreateRoom,
+        data: {
+            allow_spectators: allow_spectators_input.checked,
+            ai: ai_levels[ai_level_select?.value ?? ""],
+        }
     }
     send_event(event)    
 }
 
+function spectate_room(): void {
+    let code: string = code_input?.value
+    if (code == "") {
+        console.log("Empty code");
+        return
+    }
+    const event: SocketEvent = {
+        type: EventType.SpectateRoom,
+        data: {
+            code: code,
+        }
+    }
+    send_event(event)
+}
+
+// handle_spectate_room draws the room as it is, the left paddle being player1
+function handle_spectate_room(event: SocketEvent): void {
+    if (!event.data) {
+        return
+    }
+    spectating = true
+    room_form.style.display = "none"
+    room_options_div.style.display = "none"
+    var roomTitle = document.createElement("h1")
+    roomTitle.classList.add("subtitle")
+    roomTitle.classList.add("is-4")
+    roomTitle.innerHTML = "Watching: " + event.data.code
+    room_info_div.insertAdjacentElement("afterbegin", roomTitle)
+    canvas.style.visibility = "visible"
+
+    const state = event.data.state
+    game_state.code = event.data.code
+    if (state.player1) {
+        game_state.p1.username = state.player1.username
+        game_state.p1.score = state.player1.points
+        game_state.p1.isConnected = true
+        game_state.p1.paddle.move(state.player1.paddle.position.y)
+    }
+    if (state.player2) {
+        game_state.p2.username = state.player2.username
+        game_state.p2.score = state.player2.points
+        game_state.p2.isConnected = true
+        game_state.p2.paddle.move(state.player2.paddle.position.y)
+        game_state.p2.label.x = game_state.width - game_state.p2.get_label_width(game_state.ctx) - 10
+    }
+    game_state.update_scores()
+    game_state.status = GameStatus.Running
+    update_spectators_label(event.data.spectators)
+}
+
+function handle_spectators_update(event: SocketEvent): void {
+    if (event.data) {
+        update_spectators_label(event.data.spectators)
+    }
+}
+
+function update_spectators_label(count: number): void {
+    spectators_label.innerText = count > 0 ? count + " watching" : ""
+}
+
+function find_match(): void {
+    const event: SocketEvent = {
+        type: searching ? EventType.CancelFindMatch : EventType.FindMatch,
+    }
+    send_event(event)
+}
+
+function handle_queue_update(event: SocketEvent): void {
+    if (event.data) {
+        set_searching(event.data.queued)
+    }
+}
+
+function set_searching(queued: boolean): void {
+    searching = queued
+    if (find_btn) {
+        find_btn.textContent = searching ? "Cancel Search" : "Find Match"
+    }
+}
+
 function join_game(): void {
     let code: string = code_input?.value
     if (code == "") {
@@ -0,0 +1,697 @@
+// Code generated by protocolgen from the schema in internal/protocol. DO NOT EDIT.
+
+namespace Protocol {
+    export const Version = 1
+    export const VersionParam = "v"
+    // CloseProtocolMismatch closes the sockets of clients of another version
+    export const CloseProtocolMismatch = 4001
+
+    // socketURL is the address of the socket at path for clients of this version
+    export function socketURL(path: string): string {
+        const scheme = window.location.protocol == "https:" ? "wss://" : "ws://"
+        return scheme + window.location.host + path + "?" + VersionParam + "=" + Version
+    }
+
+    export namespace chat {
+        export interface Message {
+            from: string
+            text: string
+            sent_at: string
+        }
+    }
+
+    export namespace connectfour {
+        export const Path = "/ws/connectfour"
+
+        export enum EventType {
+            CreateGame = 1,
+            JoinGame = 2,
+            JoinedGame = 22,
+            OtherPlayerJoined = 23,
+            DropDisc = 3,
+            PlayerSendMessage = 4,
+            PlayerDisconnected = 5,
+            PlayerReconnected = 6,
+            DiscDropped = 8,
+            StateUpdate = 9,
+            Tie = 10,
+            Victory = 11,
+            Defeat = 12,
+            FindMatch = 13,
+            CancelFindMatch = 14,
+            SpectateGame = 15,
+            SpectatorsUpdate = 16,
+            ReconnectCountdown = 17,
+            Forfeit = 18,
+            ChatHistory = 19,
+            UserDisconnected = 97,
+            Ping = 98,
+            Pong = 99,
+        }
+
+        // ClientData is the data clients send with each event
+        export type ClientData = {
+            [EventType.CreateGame]: gameroom.EventDataCreateRoom
+            [EventType.JoinGame]: gameroom.EventDataJoin
+            [EventType.DropDisc]: connectfour.EventDataDrop
+            [EventType.PlayerSendMessage]: gameroom.EventDataChat
+            [EventType.FindMatch]: null
+            [EventType.CancelFindMatch]: null
+            [EventType.SpectateGame]: gameroom.EventDataCode
+            [EventType.Ping]: ws.EventPingPongData
+        }
+
+        // ServerData is the data clients get with each event
+        export type ServerData = {
+            [EventType.JoinedGame]: connectfour.GameState
+            [EventType.OtherPlayerJoined]: gameroom.DuelPlayer
+            [EventType.PlayerSendMessage]: chat.Message
+            [EventType.PlayerDisconnected]: gameroom.DuelPlayer
+            [EventType.PlayerReconnected]: gameroom.DuelPlayer
+            [EventType.DiscDropped]: connectfour.EventDataDiscDropped
+            [EventType.StateUpdate]: connectfour.GameState
+            [EventType.Tie]: gameroom.EventDataDuelTie
+            [EventType.Victory]: gameroom.EventDataDuelFinish
+            [EventType.Defeat]: gameroom.EventDataDuelFinish
+            [EventType.FindMatch]: gameroom.EventDataQueue
+            [EventType.CancelFindMatch]: gameroom.EventDataQueue
+            [EventType.SpectateGame]: gameroom.EventDataSpectate
+            [EventType.SpectatorsUpdate]: gameroom.EventDataSpectators
+            [EventType.ReconnectCountdown]: gameroom.EventDataCountdown
+            [EventType.Forfeit]: gameroom.EventDataDuelForfeit
+            [EventType.ChatHistory]: gameroom.EventDataChatHistory
+            [EventType.UserDisconnected]: ws.EventDataUser
+            [EventType.Pong]: ws.EventPingPongData
+        }
+
+        export interface EventDataDiscDropped {
+            row: number
+            col: number
+            value: number
+        }
+
+        export interface EventDataDrop {
+            col: number
+        }
+
+        export interface GameState {
+            code: string
+            player1: gameroom.DuelPlayer | null
+            player2: gameroom.DuelPlayer | null
+            current_turn: number
+            status: gameroom.DuelStatus
+            ties: number
+            winner: number
+            board: number[][]
+        }
+    }
+
+    export namespace gameroom {
+        export interface Cell {
+            row: number
+            col: number
+        }
+
+        export interface DuelPlayer {
+            username: string
+            connected: boolean
+            wins: number
+        }
+
+        export interface DuelState {
+            code: string
+            player1: gameroom.DuelPlayer | null
+            player2: gameroom.DuelPlayer | null
+            current_turn: number
+            status: gameroom.DuelStatus
+            ties: number
+            winner: number
+        }
+
+        export type DuelStatus = number
+
+        export interface EventDataChat {
+            text: string
+        }
+
+        export interface EventDataChatHistory {
+            code: string
+            messages: chat.Message[]
+        }
+
+        export interface EventDataCode {
+            code: string
+        }
+
+        export interface EventDataCountdown {
+            code: string
+            username: string
+            seconds: number
+        }
+
+        export interface EventDataCreateRoom {
+            allow_spectators?: boolean
+        }
+
+        export interface EventDataDuelFinish {
+            winner: number
+            player1: gameroom.DuelPlayer | null
+            player2: gameroom.DuelPlayer | null
+            row: number
+            col: number
+            value: number
+            line: gameroom.Cell[]
+            ratings?: Record<string, services.RatingChange>
+        }
+
+        export interface EventDataDuelForfeit {
+            winner: number
+            forfeited: string
+            player1: gameroom.DuelPlayer | null
+            player2: gameroom.DuelPlayer | null
+            ratings?: Record<string, services.RatingChange>
+        }
+
+        export interface EventDataDuelTie {
+            ties: number
+            row: number
+            col: number
+            value: number
+            ratings?: Record<string, services.RatingChange>
+        }
+
+        export interface EventDataJoin {
+            code: string
+            last_seq?: number
+        }
+
+        export interface EventDataQueue {
+            queued: boolean
+            players: number
+        }
+
+        export interface EventDataSpectate {
+            code: string
+            state: unknown
+            spectators: number
+        }
+
+        export interface EventDataSpectators {
+            code: string
+            spectators: number
+        }
+    }
+
+    export namespace handgame {
+        export const Path = "/ws/handgame"
+
+        export enum EventType {
+            CreateGame = 1,
+            JoinGame = 2,
+            JoinedGame = 22,
+            OtherPlayerJoined = 23,
+            CommitHand = 3,
+            HandCommitted = 31,
+            OpponentCommitted = 32,
+            RoundResult = 33,
+            MatchFinished = 34,
+            RequestRematch = 35,
+            RematchRequested = 36,
+            PlayerDisconnected = 5,
+            PlayerReconnected = 6,
+            StateUpdate = 9,
+            FindMatch = 13,
+            CancelFindMatch = 14,
+            SpectateGame = 15,
+            SpectatorsUpdate = 16,
+            ReconnectCountdown = 17,
+            Forfeit = 18,
+            ChatMessage = 37,
+            ChatHistory = 38,
+            UserDisconnected = 97,
+            Ping = 98,
+            Pong = 99,
+        }
+
+        // ClientData is the data clients send with each event
+        export type ClientData = {
+            [EventType.CreateGame]: handgame.EventDataCreateGame
+            [EventType.JoinGame]: gameroom.EventDataJoin
+            [EventType.CommitHand]: handgame.EventDataHand
+            [EventType.RequestRematch]: null
+            [EventType.FindMatch]: null
+            [EventType.CancelFindMatch]: null
+            [EventType.SpectateGame]: gameroom.EventDataCode
+            [EventType.ChatMessage]: gameroom.EventDataChat
+            [EventType.Ping]: ws.EventPingPongData
+        }
+
+        // ServerData is the data clients get with each event
+        export type ServerData = {
+            [EventType.JoinedGame]: handgame.EventDataState
+            [EventType.OtherPlayerJoined]: handgame.Player
+            [EventType.HandCommitted]: handgame.EventDataHand
+            [EventType.OpponentCommitted]: handgame.Player
+            [EventType.RoundResult]: handgame.EventDataRoundResult
+            [EventType.MatchFinished]: handgame.EventDataMatchFinished
+            [EventType.RematchRequested]: handgame.Player
+            [EventType.PlayerDisconnected]: handgame.Player
+            [EventType.PlayerReconnected]: handgame.Player
+            [EventType.StateUpdate]: handgame.EventDataState
+            [EventType.FindMatch]: gameroom.EventDataQueue
+            [EventType.CancelFindMatch]: gameroom.EventDataQueue
+            [EventType.SpectateGame]: gameroom.EventDataSpectate
+            [EventType.SpectatorsUpdate]: gameroom.EventDataSpectators
+            [EventType.ReconnectCountdown]: gameroom.EventDataCountdown
+            [EventType.Forfeit]: handgame.EventDataForfeit
+            [EventType.ChatMessage]: chat.Message
+            [EventType.ChatHistory]: gameroom.EventDataChatHistory
+            [EventType.UserDisconnected]: ws.EventDataUser
+            [EventType.Pong]: ws.EventPingPongData
+        }
+
+        export interface EventDataCreateGame {
+            allow_spectators?: boolean
+            best_of: number
+        }
+
+        export interface EventDataForfeit {
+            winner: number
+            forfeited: string
+            player1: handgame.Player | null
+            player2: handgame.Player | null
+        }
+
+        export interface EventDataHand {
+            hand: handgame.Hand
+        }
+
+        export interface EventDataMatchFinished {
+            winner: number
+            player1: handgame.Player | null
+            player2: handgame.Player | null
+            history: handgame.Round[]
+        }
+
+        export interface EventDataRoundResult {
+            round: handgame.Round
+            player1: handgame.Player | null
+            player2: handgame.Player | null
+            ties: number
+        }
+
+        export interface EventDataState {
+            code: string
+            player1: handgame.Player | null
+            player2: handgame.Player | null
+            best_of: number
+            round: number
+            ties: number
+            history: handgame.Round[]
+            status: handgame.GameStatus
+            winner: number
+            player_num: number
+            my_hand: handgame.Hand
+        }
+
+        export interface GameState {
+            code: string
+            player1: handgame.Player | null
+            player2: handgame.Player | null
+            best_of: number
+            round: number
+            ties: number
+            history: handgame.Round[]
+            status: handgame.GameStatus
+            winner: number
+        }
+
+        export type GameStatus = number
+
+        export type Hand = number
+
+        export interface Player {
+            username: string
+            connected: boolean
+            wins: number
+            committed: boolean
+            rematch: boolean
+        }
+
+        export interface Round {
+            number: number
+            hand1: handgame.Hand
+            hand2: handgame.Hand
+            winner: number
+        }
+    }
+
+    export namespace models {
+        export interface Vector2D {
+            x: number
+            y: number
+        }
+    }
+
+    export namespace pong {
+        export const Path = "/ws/pong"
+
+        export enum EventType {
+            Message = 1,
+            CreateRoom = 21,
+            CreatedRoom = 22,
+            JoinRoom = 23,
+            JoinedRoom = 24,
+            PlayerJoinedRoom = 25,
+            FindMatch = 26,
+            CancelFindMatch = 27,
+            SpectateRoom = 28,
+            SpectatorsUpdate = 29,
+            ReconnectCountdown = 30,
+            PlayerReconnected = 31,
+            ChatHistory = 32,
+            PaddleMoved = 35,
+            BallShot = 36,
+            BallUpdate = 37,
+            Goal = 38,
+            SyncGameState = 39,
+            GameFinished = 40,
+            UserDisconnected = 97,
+            Ping = 98,
+            Pong = 99,
+        }
+
+        // ClientData is the data clients send with each event
+        export type ClientData = {
+            [EventType.Message]: gameroom.EventDataChat
+            [EventType.CreateRoom]: pong.EventDataCreateRoom
+            [EventType.JoinRoom]: gameroom.EventDataJoin
+            [EventType.FindMatch]: null
+            [EventType.CancelFindMatch]: null
+            [EventType.SpectateRoom]: gameroom.EventDataCode
+            [EventType.PaddleMoved]: pong.EventPaddleMoveData
+            [EventType.BallShot]: null
+            [EventType.Ping]: ws.EventPingPongData
+        }
+
+        // ServerData is the data clients get with each event
+        export type ServerData = {
+            [EventType.Message]: chat.Message
+            [EventType.CreatedRoom]: pong.EventDataCreatedRoom
+            [EventType.JoinedRoom]: pong.EventDataJoinedRoom
+            [EventType.PlayerJoinedRoom]: pong.EventDataPlayerJoined
+            [EventType.FindMatch]: gameroom.EventDataQueue
+            [EventType.CancelFindMatch]: gameroom.EventDataQueue
+            [EventType.SpectateRoom]: gameroom.EventDataSpectate
+            [EventType.SpectatorsUpdate]: gameroom.EventDataSpectators
+            [EventType.ReconnectCountdown]: gameroom.EventDataCountdown
+            [EventType.PlayerReconnected]: ws.EventDataUser
+            [EventType.ChatHistory]: gameroom.EventDataChatHistory
+            [EventType.PaddleMoved]: pong.EventPaddleMoveData
+            [EventType.BallUpdate]: models.Vector2D
+            [EventType.Goal]: pong.EventDataGoal
+            [EventType.SyncGameState]: pong.EventDataSync
+            [EventType.GameFinished]: pong.EventDataGameFinished
+            [EventType.UserDisconnected]: ws.EventDataUser
+            [EventType.Pong]: ws.EventPingPongData
+        }
+
+        export interface AIOptions {
+            reaction_ms: number
+            error: number
+        }
+
+        export interface EventDataCreateRoom {
+            allow_spectators?: boolean
+            ai?: pong.AIOptions
+        }
+
+        export interface EventDataCreatedRoom {
+            code: string
+            username: string
+        }
+
+        export interface EventDataGameFinished {
+            winner: string
+            forfeited?: string
+            player1_score: number
+            player2_score: number
+            ratings?: Record<string, services.RatingChange>
+        }
+
+        export interface EventDataGoal {
+            player1_score: number
+            player2_score: number
+        }
+
+        export interface EventDataJoinedRoom {
+            code: string
+            username: string
+            player: string
+            is_player_1?: boolean
+        }
+
+        export interface EventDataPlayerJoined {
+            code: string
+            player: string
+            is_player_1: boolean
+        }
+
+        export interface EventDataSync {
+            code: string
+            username: string
+            state: unknown
+        }
+
+        export interface EventPaddleMoveData {
+            y: number
+            username?: string
+        }
+    }
+
+    export namespace presence {
+        export const Path = "/ws/lobby"
+
+        export enum EventType {
+            LobbyUpdate = 1,
+            Invite = 2,
+            InviteAnswer = 3,
+            InviteAnswered = 4,
+            UserDisconnected = 97,
+            Ping = 98,
+            Pong = 99,
+        }
+
+        // ClientData is the data clients send with each event
+        export type ClientData = {
+            [EventType.InviteAnswer]: presence.InviteAnswer
+            [EventType.Ping]: ws.EventPingPongData
+        }
+
+        // ServerData is the data clients get with each event
+        export type ServerData = {
+            [EventType.LobbyUpdate]: presence.Snapshot
+            [EventType.Invite]: presence.Invite
+            [EventType.InviteAnswer]: presence.InviteAnswer
+            [EventType.InviteAnswered]: presence.InviteAnswer
+            [EventType.UserDisconnected]: ws.EventDataUser
+            [EventType.Pong]: ws.EventPingPongData
+        }
+
+        export interface Activity {
+            game: string
+            code: string
+            spectating?: boolean
+        }
+
+        export interface Invite {
+            from: string
+            to: string
+            game: string
+            code: string
+        }
+
+        export interface InviteAnswer {
+            from: string
+            to: string
+            game: string
+            code: string
+            accepted: boolean
+        }
+
+        export interface Room {
+            game: string
+            code: string
+            host: string
+            players: number
+            max_players: number
+        }
+
+        export interface Snapshot {
+            users: presence.User[]
+            rooms: presence.Room[]
+        }
+
+        export interface User {
+            username: string
+            activities: presence.Activity[]
+        }
+    }
+
+    export namespace services {
+        export interface RatingChange {
+            rating: number
+            delta: number
+        }
+    }
+
+    export namespace tictactoe {
+        export const Path = "/ws/tictactoe"
+
+        export enum EventType {
+            CreateGame = 1,
+            JoinGame = 2,
+            JoinedGame = 22,
+            OtherPlayerJoined = 23,
+            MakePlay = 3,
+            PlayerSendMessage = 4,
+            PlayerDisconnected = 5,
+            PlayerReconnected = 6,
+            BoardCellUpdate = 8,
+            StateUpdate = 9,
+            Tie = 10,
+            Victory = 11,
+            Defeat = 12,
+            FindMatch = 13,
+            CancelFindMatch = 14,
+            SpectateGame = 15,
+            SpectatorsUpdate = 16,
+            ReconnectCountdown = 17,
+            Forfeit = 18,
+            ChatHistory = 19,
+            UserDisconnected = 97,
+            Ping = 98,
+            Pong = 99,
+        }
+
+        // ClientData is the data clients send with each event
+        export type ClientData = {
+            [EventType.CreateGame]: tictactoe.EventDataCreateGame
+            [EventType.JoinGame]: gameroom.EventDataJoin
+            [EventType.MakePlay]: tictactoe.EventDataPlay
+            [EventType.PlayerSendMessage]: gameroom.EventDataChat
+            [EventType.FindMatch]: null
+            [EventType.CancelFindMatch]: null
+            [EventType.SpectateGame]: gameroom.EventDataCode
+            [EventType.Ping]: ws.EventPingPongData
+        }
+
+        // ServerData is the data clients get with each event
+        export type ServerData = {
+            [EventType.JoinedGame]: tictactoe.GameState
+            [EventType.OtherPlayerJoined]: gameroom.DuelPlayer
+            [EventType.PlayerSendMessage]: chat.Message
+            [EventType.PlayerDisconnected]: gameroom.DuelPlayer
+            [EventType.PlayerReconnected]: gameroom.DuelPlayer
+            [EventType.BoardCellUpdate]: tictactoe.EventDataCellUpdate
+            [EventType.StateUpdate]: tictactoe.GameState
+            [EventType.Tie]: gameroom.EventDataDuelTie
+            [EventType.Victory]: gameroom.EventDataDuelFinish
+            [EventType.Defeat]: gameroom.EventDataDuelFinish
+            [EventType.FindMatch]: gameroom.EventDataQueue
+            [EventType.CancelFindMatch]: gameroom.EventDataQueue
+            [EventType.SpectateGame]: gameroom.EventDataSpectate
+            [EventType.SpectatorsUpdate]: gameroom.EventDataSpectators
+            [EventType.ReconnectCountdown]: gameroom.EventDataCountdown
+            [EventType.Forfeit]: gameroom.EventDataDuelForfeit
+            [EventType.ChatHistory]: gameroom.EventDataChatHistory
+            [EventType.UserDisconnected]: ws.EventDataUser
+            [EventType.Pong]: ws.EventPingPongData
+        }
+
+        export type Difficulty = string
+
+        export interface EventDataCellUpdate {
+            row: number
+            col: number
+            value: number
+            size: number
+            win_length: number
+        }
+
+        export interface EventDataCreateGame {
+            allow_spectators?: boolean
+            bot?: tictactoe.Difficulty
+            size?: number
+            win_length?: number
+        }
+
+        export interface EventDataPlay {
+            row: number
+            col: number
+        }
+
+        export interface GameState {
+            code: string
+            player1: gameroom.DuelPlayer | null
+            player2: gameroom.DuelPlayer | null
+            current_turn: number
+            status: gameroom.DuelStatus
+            ties: number
+            winner: number
+            size: number
+            win_length: number
+            board: number[][]
+        }
+    }
+
+    export namespace ws {
+        export enum ErrorCode {
+            Internal = "internal",
+            UnknownEvent = "unknown_event",
+            InvalidData = "invalid_data",
+            RoomNotFound = "room_not_found",
+            RoomFull = "room_full",
+            AlreadyInRoom = "already_in_room",
+            NotInRoom = "not_in_room",
+            SpectatingDisabled = "spectating_disabled",
+            AlreadyQueued = "already_queued",
+            NotAPlayer = "not_a_player",
+            GameNotRunning = "game_not_running",
+            NotYourTurn = "not_your_turn",
+            InvalidOptions = "invalid_options",
+            InvalidPlay = "invalid_play",
+            AlreadyPlayed = "already_played",
+            OutOfBounds = "out_of_bounds",
+            CellOccupied = "cell_occupied",
+            ColumnFull = "column_full",
+            InvalidMessage = "invalid_message",
+            RateLimited = "rate_limited",
+        }
+
+        export interface Error {
+            code: ws.ErrorCode
+            message: string
+            field?: string
+        }
+
+        export interface Event {
+            type: ws.EventType
+            data?: unknown
+            roomCode?: string
+            isError?: boolean
+            error?: ws.Error
+            seq?: number
+        }
+
+        export interface EventDataUser {
+            username: string
+        }
+
+        export interface EventPingPongData {
+            timestamp: string
+        }
+
+        export type EventType = number
+    }
+}
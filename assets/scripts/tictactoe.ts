@@ -16,12 +16,8 @@ setTimeout(() => {
     class Board {
         board: number[][]
 
-        constructor() {
-            this.board = [
-              [0, 0, 0],  
-              [0, 0, 0],  
-              [0, 0, 0]  
-            ];
+        constructor(size: number = 3) {
+            this.board = Array.from({ length: size }, () => new Array(size).fill(0))
         }  
     }
 
@@ -31,6 +27,8 @@ setTimeout(() => {
         board: Board
         status: number
         ties: number
+        size: number
+        win_length: number
 
         constructor() {
            this.player1 = new TicPlayer("", false)   
@@ -38,38 +36,49 @@ setTimeout(() => {
            this.board = new Board()
            this.ties = 0
            this.status = 0
+           this.size = 3
+           this.win_length = 3
         }
     }
 
-    enum TTTEventType {
-        CreateGame = 1,
-        JoinGame = 2,
-        JoinedGame = 22,
-        OtherPlayerJoined = 23,
-
-        MakePlay = 3,
-
-        PlayerDisconnected = 5,
-        PlayerReconnected = 6,
-
-        BoardCellUpdate = 8,
-        StateUpdate = 9,
-
-        Tie = 10,
-        Victory = 11,
-        Defeat = 12,
-    }
+    // The event types and error codes come from the schema in
+    // internal/protocol, see protocol.ts
+    const TTTEventType = Protocol.tictactoe.EventType
+    type TTTEventType = Protocol.tictactoe.EventType
 
     type TTTEvent = {
         type: TTTEventType,
         data?: any,
-        isError?: boolean
+        isError?: boolean,
+        error?: TTTEventError,
+        seq?: number
     }
-    let host = window.location.host
-    let ttt_socket = new WebSocket("ws://"+host+"/ws/tictactoe")
+
+    // The error of an event the server refused, field is the part of the
+    // data that was wrong if it was only one
+    type TTTEventError = Protocol.ws.Error
+    const TTTErrorCode = Protocol.ws.ErrorCode
+
+    let ttt_socket = new WebSocket(Protocol.socketURL(Protocol.tictactoe.Path))
+
+    // A player that loses its socket joins its room again with the sequence
+    // number of the last event it got, the server replays what it missed or
+    // sends the whole state
+    let ttt_room_code = ""
+    let ttt_last_seq = 0
+    // ttt_seq_lost is set once an event came out of order, the next rejoin
+    // asks for the whole state instead of what was missed
+    let ttt_seq_lost = false
+    let ttt_leaving = false
 
     let ttt_create_btn = document.getElementById("tictactoe_create_btn") as HTMLButtonElement
     let ttt_join_btn = document.getElementById("tictactoe_join_btn") as HTMLButtonElement
+    let ttt_find_btn = document.getElementById("tictactoe_find_btn") as HTMLButtonElement
+    let ttt_spectate_btn = document.getElementById("tictactoe_spectate_btn") as HTMLButtonElement
+    let ttt_allow_spectators = document.getElementById("ttt_allow_spectators") as HTMLInputElement
+    let ttt_bot = document.getElementById("ttt_bot") as HTMLSelectElement
+    let ttt_dimensions = document.getElementById("ttt_dimensions") as HTMLSelectElement
+    let ttt_spectators_label = document.getElementById("ttt_spectators_label") as HTMLParagraphElement
 
     let ttt_code_label = document.getElementById("ttt_code_label") as HTMLParagraphElement
 
@@ -84,19 +93,37 @@ setTimeout(() => {
     let ties_label = document.getElementById("ties") as HTMLParagraphElement
     ties_label.style.color = "green"
 
+    let ttt_chat = document.getElementById("ttt_chat") as HTMLDivElement
+    let ttt_chat_messages = document.getElementById("ttt_chat_messages") as HTMLDivElement
+    let ttt_chat_form = document.getElementById("ttt_chat_form") as HTMLFormElement
+    let ttt_chat_input = document.getElementById("ttt_chat_input") as HTMLInputElement
+
     let board_el = document.getElementById("ttt_board") as HTMLDivElement
-    let cells = document.querySelectorAll('#ttt_board .cell');
+    let board_body = board_el.querySelector(".ttt_board_body") as HTMLDivElement
+    let cells = document.querySelectorAll<HTMLDivElement>('#ttt_board .cell');
 
     ttt_create_btn.addEventListener("click", ttt_create_game)
     ttt_join_btn.addEventListener("click", ttt_join_game)
+    ttt_find_btn.addEventListener("click", ttt_find_match)
+    ttt_spectate_btn.addEventListener("click", ttt_spectate_game)
+    ttt_chat_form.addEventListener("submit", ttt_send_chat)
 
     let state = new State()
 
     let clicked_create = false
+    let spectating = false
 
     function ttt_create_game(): void {
+        // The dimensions are picked as "size:win_length"
+        const [size, win_length] = (ttt_dimensions?.value ?? "3:3").split(":").map(Number)
         const create_event: TTTEvent = {
             type: TTTEventType.CreateGame,
+            data: {
+                allow_spectators: ttt_allow_spectators.checked,
+                bot: ttt_bot?.value ?? "",
+                size: size,
+                win_length: win_length,
+            }
         }
         ttt_send_event(create_event)
         clicked_create = true
@@ -114,11 +141,68 @@ setTimeout(() => {
         clicked_create = true
     }
 
+    function ttt_spectate_game(): void {
+        const code_input = document.getElementById("ttt_code") as HTMLInputElement
+        const spectate_event: TTTEvent = {
+            type: TTTEventType.SpectateGame,
+            data: {
+                code: code_input?.value
+            }
+        }
+        ttt_send_event(spectate_event)
+    }
+
+    function handle_ttt_spectate_game(event: TTTEvent): void {
+        spectating = true
+        handle_ttt_joined_game({ ...event, data: event.data.state })
+        handle_ttt_spectators_update(event)
+    }
+
+    function handle_ttt_spectators_update(event: TTTEvent): void {
+        if (event.data) {
+            const count: number = event.data.spectators
+            ttt_spectators_label.innerText = count > 0 ? count + " watching" : ""
+        }
+    }
+
+    let searching = false
+
+    function ttt_find_match(): void {
+        const find_event: TTTEvent = {
+            type: searching ? TTTEventType.CancelFindMatch : TTTEventType.FindMatch,
+        }
+        ttt_send_event(find_event)
+    }
+
+    function handle_ttt_queue_update(event: TTTEvent): void {
+        if (event.data) {
+            ttt_set_searching(event.data.queued)
+        }
+    }
+
+    function ttt_send_chat(e: SubmitEvent): void {
+        e.preventDefault()
+        const text = ttt_chat_input.value.trim()
+        if (text == "") {
+            return
+        }
+        ttt_send_event({
+            type: TTTEventType.PlayerSendMessage,
+            data: { text: text }
+        })
+        ttt_chat_input.value = ""
+    }
+
+    function ttt_set_searching(queued: boolean): void {
+        searching = queued
+        ttt_find_btn.textContent = searching ? "Cancel Search" : "Find Match"
+    }
+
     function ttt_send_event(ev: TTTEvent): void {
         ttt_socket.send(JSON.stringify(ev))
     }
 
-    ttt_socket.addEventListener("message", (e) => {
+    function ttt_on_message(e: MessageEvent): void {
         console.log(e)
         const event = ttt_parse_event(e.data)
         if (event.isError !== undefined) {
@@ -130,15 +214,87 @@ setTimeout(() => {
             console.log("got pong")
             return
         }
+        ttt_track_seq(event)
         ttt_handle_event(event)
-    })
+    }
+
+    // ttt_track_seq keeps the last sequence number got, the ones sent along
+    // the whole state start over from there
+    function ttt_track_seq(event: TTTEvent): void {
+        switch (event.type) {
+            case TTTEventType.JoinedGame:
+            case TTTEventType.StateUpdate:
+            case TTTEventType.SpectateGame:
+                ttt_last_seq = event.seq ?? 0
+                ttt_seq_lost = false
+                break
+            default:
+                if (event.seq && event.seq < ttt_last_seq) {
+                    ttt_seq_lost = true
+                }
+                if (!ttt_seq_lost) {
+                    ttt_last_seq = Math.max(ttt_last_seq, event.seq ?? 0)
+                }
+        }
+    }
+
+    function ttt_on_close(e: CloseEvent): void {
+        if (e.code == Protocol.CloseProtocolMismatch) {
+            ttt_show_notification("The game was updated, reload the page to keep playing")
+            return
+        }
+        if (ttt_leaving || ttt_room_code == "" || spectating) {
+            return
+        }
+        setTimeout(ttt_reconnect, 1000)
+    }
+
+    function ttt_reconnect(): void {
+        ttt_socket = new WebSocket(Protocol.socketURL(Protocol.tictactoe.Path))
+        ttt_socket.addEventListener("message", ttt_on_message)
+        ttt_socket.addEventListener("close", ttt_on_close)
+        ttt_socket.addEventListener("open", () => {
+            ttt_send_event({
+                type: TTTEventType.JoinGame,
+                data: {
+                    code: ttt_room_code,
+                    last_seq: ttt_seq_lost ? 0 : ttt_last_seq,
+                }
+            })
+        })
+    }
+
+    ttt_socket.addEventListener("message", ttt_on_message)
+    ttt_socket.addEventListener("close", ttt_on_close)
+
+    // Accepting the invite of a friend comes here with the room to join
+    const ttt_invite_code = new URLSearchParams(window.location.search).get("join")
+    if (ttt_invite_code) {
+        history.replaceState(null, "", window.location.pathname)
+        ttt_socket.addEventListener("open", () => {
+            const code_input = document.getElementById("ttt_code") as HTMLInputElement
+            code_input.value = ttt_invite_code
+            ttt_join_game()
+        }, { once: true })
+    }
 
 
     function ttt_handle_event(event: TTTEvent): void {
         switch (event.type) {
             case TTTEventType.JoinedGame:
+                ttt_set_searching(false)
                 handle_ttt_joined_game(event)
                 break
+            case TTTEventType.FindMatch:
+            case TTTEventType.CancelFindMatch:
+                handle_ttt_queue_update(event)
+                break
+            case TTTEventType.SpectateGame:
+                handle_ttt_spectate_game(event)
+                break
+            case TTTEventType.SpectatorsUpdate:
+                handle_ttt_spectators_update(event)
+                break
             case TTTEventType.OtherPlayerJoined:
                 handle_ttt_other_player_joined_game(event)
                 break
@@ -163,6 +319,18 @@ setTimeout(() => {
             case TTTEventType.PlayerReconnected:
                 handle_ttt_player_reconnected(event)
                 break
+            case TTTEventType.ReconnectCountdown:
+                handle_ttt_reconnect_countdown(event)
+                break
+            case TTTEventType.Forfeit:
+                handle_ttt_forfeit(event)
+                break
+            case TTTEventType.PlayerSendMessage:
+                ttt_add_chat_message(event.data)
+                break
+            case TTTEventType.ChatHistory:
+                handle_ttt_chat_history(event)
+                break
             default:
                 console.log("Unknown event")
                 console.log(event)
@@ -173,6 +341,28 @@ setTimeout(() => {
 
     function ttt_handle_event_error(event: TTTEvent): void {
         console.log("Event gave an error: " + event.type)
+        if (event.type == TTTEventType.PlayerSendMessage && event.data) {
+            ttt_show_notification(event.data.message)
+            return
+        }
+        switch (event.error?.code) {
+            case TTTErrorCode.NotYourTurn:
+                ttt_show_notification("Wait for your turn")
+                return
+            case TTTErrorCode.CellOccupied:
+                ttt_show_notification("That cell is taken")
+                return
+            case TTTErrorCode.GameNotRunning:
+                ttt_show_notification("Waiting for the other player")
+                return
+            case TTTErrorCode.NotAPlayer:
+                ttt_show_notification("You are watching this game")
+                return
+            case TTTErrorCode.OutOfBounds:
+            case TTTErrorCode.InvalidPlay:
+                ttt_show_notification(event.error?.message ?? "Invalid play")
+                return
+        }
         if (event.data) {
             console.log(event.data.message)
             alert(event.data.message)
@@ -191,6 +381,7 @@ setTimeout(() => {
 
     function handle_ttt_board_cell_update(event: TTTEvent): void {
         if(event.data) {
+            set_dimensions(event.data.size, event.data.win_length)
             const row: number = event.data.row
             const col: number = event.data.col
             const value: number = event.data.value
@@ -209,7 +400,7 @@ setTimeout(() => {
             state.ties = event.data.ties
 
             await update_cell(event.data.row, event.data.col, event.data.value)
-            await show_game_result_message("tie", event.data.row, event.data.col).then(() => {
+            await show_game_result_message("tie", []).then(() => {
                 clear_board()
             })
             clear_board()
@@ -219,6 +410,7 @@ setTimeout(() => {
 
 
     function close_web_socket() {
+        ttt_leaving = true
         if (ttt_socket) {
             ttt_socket.close();
         }
@@ -231,8 +423,10 @@ setTimeout(() => {
             state.player2.wins = event.data.player2.wins
             state.player2.name = event.data.player2.username
 
+            const winner = event.data.winner == 1 ? event.data.player1 : event.data.player2
             await update_cell(event.data.row, event.data.col, event.data.value)
-            await show_game_result_message("win", event.data.row, event.data.col).then(() => {
+            const result = spectating ? winner.username + " Wins!" : "win"
+            await show_game_result_message(result, event.data.line, rating_delta_text(event.data.ratings, winner.username)).then(() => {
                 clear_board()
             })
             clear_board()
@@ -248,8 +442,9 @@ setTimeout(() => {
             state.player2.wins = event.data.player2.wins
             state.player2.name = event.data.player2.username
 
+            const loser = event.data.winner == 1 ? event.data.player2 : event.data.player1
             await update_cell(event.data.row, event.data.col, event.data.value)
-            await show_game_result_message("lose", event.data.row, event.data.col).then(() => {
+            await show_game_result_message("lose", event.data.line, rating_delta_text(event.data.ratings, loser.username)).then(() => {
                 clear_board()
             })
         }
@@ -257,6 +452,7 @@ setTimeout(() => {
     }
 
     function handle_ttt_player_reconnected(event: TTTEvent): void {
+        countdown_label.remove()
         if (event.data){
             let player: TicPlayer 
             if (event.data.username == state.player1.name) {
@@ -289,8 +485,42 @@ setTimeout(() => {
         }
     }
 
+    const countdown_label = document.createElement("p") as HTMLParagraphElement
+    countdown_label.id = "countdown_label"
+    countdown_label.classList.add("has-text-danger")
+
+    // handle_ttt_reconnect_countdown shows how long a player that dropped has
+    // left to come back before it forfeits
+    function handle_ttt_reconnect_countdown(event: TTTEvent): void {
+        if (event.data) {
+            countdown_label.innerText = event.data.username + " left, " + event.data.seconds + "s to come back"
+            board_el.insertAdjacentElement("afterend", countdown_label)
+        }
+    }
+
+    // handle_ttt_forfeit gives the game to the player left, the slot of the
+    // one that forfeited is free for someone else
+    async function handle_ttt_forfeit(event: TTTEvent): Promise<void> {
+        countdown_label.remove()
+        if (event.data) {
+            const winner = event.data.winner == 1 ? event.data.player1 : event.data.player2
+            const result = spectating ? winner.username + " Wins by forfeit!" : event.data.forfeited + " forfeited, you win!"
+            await show_game_result_message(result, [], rating_delta_text(event.data.ratings, winner.username))
+
+            const left = event.data.winner == 1 ? state.player2 : state.player1
+            left.name = ""
+            left.connected = false
+            state.player1.wins = 0
+            state.player2.wins = 0
+            state.ties = 0
+            clear_board()
+        }
+        update_scoreboard()
+    }
+
     function handle_ttt_state_update(event: TTTEvent): void {
         if (event.data) {
+            set_dimensions(event.data.size, event.data.win_length)
             state.player2.name = event.data.player
             state.board.board = event.data.board
 
@@ -322,8 +552,11 @@ setTimeout(() => {
     function handle_ttt_joined_game(event: TTTEvent): void {
         const form = document.getElementById("room-menu") as HTMLDivElement
         form.style.display = "none"
+        const options = document.getElementById("ttt_room_options") as HTMLDivElement
+        options.style.display = "none"
 
         ttt_code_label.innerText = event.data.code
+        ttt_room_code = event.data.code
         if (clicked_create) {
             ttt_show_notification(event.data.code)
             navigator.clipboard.writeText(event.data.code);
@@ -348,6 +581,7 @@ setTimeout(() => {
             }
         }
         state.ties = event.data.ties
+        set_dimensions(event.data.size, event.data.win_length)
         state.board.board = event.data.board
         state.status - event.data.status
     
@@ -355,6 +589,25 @@ setTimeout(() => {
         update_board()
     }
 
+    // handle_ttt_chat_history replaces the chat with the scrollback of the
+    // room, it comes with every join
+    function handle_ttt_chat_history(event: TTTEvent): void {
+        ttt_chat_messages.replaceChildren()
+        for (const message of event.data.messages ?? []) {
+            ttt_add_chat_message(message)
+        }
+        ttt_chat.classList.remove("is-hidden")
+    }
+
+    function ttt_add_chat_message(message: { from: string, text: string }): void {
+        const line = document.createElement("p")
+        const from = document.createElement("strong")
+        from.textContent = message.from + ": "
+        line.append(from, message.text)
+        ttt_chat_messages.append(line)
+        ttt_chat_messages.scrollTop = ttt_chat_messages.scrollHeight
+    }
+
     function ttt_show_notification(message: string) {
         // Create the notification element
         const notification = document.createElement("div");
@@ -419,11 +672,34 @@ setTimeout(() => {
     
     }
 
+    // set_dimensions lays the cells out again when the game has another size
+    // than the board shown
+    function set_dimensions(size: number | undefined, win_length: number | undefined): void {
+        if (!size || size == state.size && cells.length == size * size) {
+            return
+        }
+        state.size = size
+        state.win_length = win_length ?? state.win_length
+        state.board = new Board(size)
+
+        // Cells shrink so that big boards still fit, with the 5px gaps
+        board_body.style.setProperty("--ttt-size", size.toString())
+        board_body.style.setProperty("--ttt-cell", Math.min(100, Math.floor((450 - 5 * (size - 1)) / size)) + "px")
+        board_body.replaceChildren()
+        for (let i = 0; i < size * size; i++) {
+            const cell = document.createElement("div")
+            cell.classList.add("cell")
+            board_body.append(cell)
+        }
+        cells = board_body.querySelectorAll<HTMLDivElement>(".cell")
+        listen_cells()
+    }
+
     async function update_board(): Promise<void> {
 
-        for (let row:number = 0; row < 3; row++) {
-            for (let col:number = 0; col < 3; col++) {
-                const cellIndex = row * 3 + col;
+        for (let row:number = 0; row < state.size; row++) {
+            for (let col:number = 0; col < state.size; col++) {
+                const cellIndex = row * state.size + col;
                 const cell = cells[cellIndex];
 
                 if (state.board.board[row][col] === 1) {
@@ -438,13 +714,13 @@ setTimeout(() => {
     }
 
     function clear_board(): void {
-        state.board.board = [ [0, 0, 0], [0,0,0], [0,0,0] ]
+        state.board = new Board(state.size)
         update_board()
     }
 
     async function update_cell(row: number, col: number, value: number): Promise<void> {
         state.board.board[row][col] = value
-        const cell = cells[row * 3 + col]
+        const cell = cells[row * state.size + col]
 
         if (value == 1) {
             cell.classList.add('x');
@@ -458,92 +734,72 @@ setTimeout(() => {
 
     update_scoreboard()
 
-    cells.forEach((cell, index) => {
-        cell.addEventListener('click', () => {
-            const row: number = Math.floor(index / 3);
-            const col: number  = index % 3;
-
-        
-            const play_event: TTTEvent = {
-                type: TTTEventType.MakePlay,
-                data: {
-                    row: row,
-                    col: col,
+    function listen_cells(): void {
+        cells.forEach((cell, index) => {
+            cell.addEventListener('click', () => {
+                if (spectating) {
+                    return
                 }
-            }
-            ttt_send_event(play_event)
+                const row: number = Math.floor(index / state.size);
+                const col: number  = index % state.size;
+
+                const play_event: TTTEvent = {
+                    type: TTTEventType.MakePlay,
+                    data: {
+                        row: row,
+                        col: col,
+                    }
+                }
+                ttt_send_event(play_event)
+            });
         });
-    });
+    }
+    listen_cells()
+
+    // rating_delta_text formats the rating change of a player sent with the game result
+    function rating_delta_text(ratings: any, username: string): string {
+        if (!ratings || !ratings[username]) {
+            return ""
+        }
+        const change = ratings[username]
+        const sign = change.delta >= 0 ? "+" : ""
+        return " (" + change.rating + ", " + sign + change.delta + ")"
+    }
 
-    async function show_game_result_message(result: string, winning_row: number, winning_col: number): Promise<void> {
+    async function show_game_result_message(result: string, line: { row: number, col: number }[] = [], rating: string = ""): Promise<void> {
         return new Promise((resolve) => {
             const messageElement = document.getElementById("game-result-overlay") as HTMLDivElement;
             const resultTextElement = document.getElementById("result-text") as HTMLParagraphElement;
-            const winningCells = get_winning_cells(winning_row, winning_col); // Retorna um array de coordenadas [row, col]
+            // The server sends the cells of the winning line
+            const winningCells = (line ?? []).map(({ row, col }) => cells[row * state.size + col])
 
             if (result === "win") {
-                resultTextElement.textContent = "You Win!";
+                resultTextElement.textContent = "You Win!" + rating;
             } else if (result === "lose") {
-                resultTextElement.textContent = "You Lose!";
+                resultTextElement.textContent = "You Lose!" + rating;
             } else if (result === "tie") {
                 resultTextElement.textContent = "It's a Tie!";
+            } else {
+                resultTextElement.textContent = result + rating;
             }
 
             messageElement.classList.remove("is-hidden");
 
-            if (winningCells && result !== "tie") {
-                winningCells.forEach(([row, col]) => {
-                    const cellIndex = row * 3 + col; // Calcula o índice da célula com base em row e col
-                    const cell = document.querySelectorAll('.cell')[cellIndex] as HTMLElement;
-                    cell.classList.add('winning-cell');
-                });
+            if (result !== "tie") {
+                winningCells.forEach((cell) => cell.classList.add('winning-cell'));
             }
 
             setTimeout(() => {
                 messageElement.classList.add("is-hidden");
-                if (winningCells) {
-                    winningCells.forEach(([row, col]) => {
-                        const cellIndex = row * 3 + col;
-                        const cell = document.querySelectorAll('.cell')[cellIndex] as HTMLElement;
-                        cell.classList.remove('winning-cell');
-                    });
-                }
+                winningCells.forEach((cell) => cell.classList.remove('winning-cell'));
                 resolve()
             }, 1500);
         })
     }
 
-    function get_winning_cells(lastRow: number, lastCol: number): number[][] {
-        const player = state.board.board[lastRow][lastCol];
-        const winningCells = [];
-        const board = state.board.board
-
-        // Check row
-        if (board[lastRow][0] === player && board[lastRow][1] === player && board[lastRow][2] === player) {
-            winningCells.push([lastRow, 0], [lastRow, 1], [lastRow, 2]);
-        }
-
-        // Check column
-        if (board[0][lastCol] === player && board[1][lastCol] === player && board[2][lastCol] === player) {
-            winningCells.push([0, lastCol], [1, lastCol], [2, lastCol]);
-        }
-
-        // Check diagonal (top-left to bottom-right)
-        if (lastRow === lastCol && board[0][0] === player && board[1][1] === player && board[2][2] === player) {
-            winningCells.push([0, 0], [1, 1], [2, 2]);
-        }
-
-        // Check diagonal (top-right to bottom-left)
-        if (lastRow + lastCol === 2 && board[0][2] === player && board[1][1] === player && board[2][0] === player) {
-            winningCells.push([0, 2], [1, 1], [2, 0]);
-        }
-
-        // If winning cells were found, return them
-        return winningCells
-    }
-
 
         document.body.addEventListener('htmx:afterOnLoad', function(event) {
+            ttt_leaving = true
             ttt_socket.close()
         });
 
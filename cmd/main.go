@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -21,7 +22,10 @@ import (
 	"github.com/FredericoBento/HandGame/internal/server"
 	"github.com/FredericoBento/HandGame/internal/services"
 	"github.com/FredericoBento/HandGame/internal/services/admin_service"
+	"github.com/FredericoBento/HandGame/internal/services/connectfour"
+	"github.com/FredericoBento/HandGame/internal/services/handgame"
 	"github.com/FredericoBento/HandGame/internal/services/pong"
+	"github.com/FredericoBento/HandGame/internal/services/presence"
 	"github.com/FredericoBento/HandGame/internal/services/tictactoe"
 
 	_ "net/http/pprof"
@@ -55,10 +59,14 @@ type Config struct {
 	Server       ServerConfig                 `json:"server"`
 	Database     DatabaseConfig               `son:"database"`
 	Applications map[string]ApplicationConfig `json:"applications"`
+	// Admins are given the admin role at startup while there is no admin yet,
+	// roles are managed from /admin/users from then on
+	Admins []string `json:"admins"`
 }
 
 const (
-	dbFile = "./simple.db"
+	dbFile               = "./simple.db"
+	sessionSweepInterval = 10 * time.Minute
 )
 
 func main() {
@@ -79,15 +87,39 @@ func main() {
 	var httpServer *server.Server
 
 	userRepository := repository.NewSQLiteUserRepository(db)
+	matchRepository := repository.NewSQLiteMatchRepository(db)
+	ratingRepository := repository.NewSQLiteRatingRepository(db)
+	sessionRepository := repository.NewSQLiteSessionRepository(db)
+	friendRepository := repository.NewSQLiteFriendRepository(db)
 
 	userService := services.NewUserService(userRepository, time.Minute*10)
-	authService := services.NewAuthService(userService)
-
-	pongService := pong.NewPongService()
-	handgameService := services.NewHandGameService()
-	ticTacToeService := tictactoe.NewTicTacToeService()
+	authService := services.NewAuthService(userService, sessionRepository)
+	grantAdmins(userService, config.Admins)
+	go authService.RunSessionSweeper(sessionSweepInterval)
+	ratingService := services.NewRatingService(ratingRepository)
+	friendService := services.NewFriendService(friendRepository, userService)
+	presenceService := presence.NewService()
+
+	pongService := pong.NewPongService(
+		pong.WithMatchRepository(matchRepository),
+		pong.WithRatingService(ratingService),
+		pong.WithPresence(presenceService),
+	)
+	handgameService := handgame.NewHandGameService(
+		handgame.WithPresence(presenceService),
+	)
+	ticTacToeService := tictactoe.NewTicTacToeService(
+		tictactoe.WithMatchRepository(matchRepository),
+		tictactoe.WithRatingService(ratingService),
+		tictactoe.WithPresence(presenceService),
+	)
+	connectFourService := connectfour.NewConnectFourService(
+		connectfour.WithMatchRepository(matchRepository),
+		connectfour.WithRatingService(ratingService),
+		connectfour.WithPresence(presenceService),
+	)
 
-	games := []services.GameService{handgameService, pongService, ticTacToeService}
+	games := []services.GameService{handgameService, pongService, ticTacToeService, connectFourService}
 
 	adminService := admin_service.NewAdminService(httpServer, games)
 
@@ -95,13 +127,17 @@ func main() {
 
 	authHandler := handler.NewAuthHandler(authService, userService)
 	adminHandler := handler.NewAdminHandler(adminService, userService)
-	homeHandler := handler.NewHomeHandler(games, authService)
+	homeHandler := handler.NewHomeHandler(games, authService, presenceService)
 
 	handGameHandler := handler.NewHandGameHandler(handgameService)
 	pongHandler := handler.NewPongHandler(pongService)
 	tictactoeHandler := handler.NewTicTacToeHandler()
+	connectFourHandler := handler.NewConnectFourHandler()
+	leaderboardHandler := handler.NewLeaderboardHandler(matchRepository)
+	profileHandler := handler.NewProfileHandler(userService, ratingService, matchRepository)
+	friendsHandler := handler.NewFriendsHandler(friendService, presenceService)
 
-	serverHandlers := server.NewServerHandlers(authHandler, adminHandler, homeHandler, handGameHandler, pongHandler, tictactoeHandler)
+	serverHandlers := server.NewServerHandlers(authHandler, adminHandler, homeHandler, handGameHandler, pongHandler, tictactoeHandler, connectFourHandler, leaderboardHandler, profileHandler, friendsHandler)
 
 	httpServer = server.NewServer(
 		server.WithHost(config.Server.Host),
@@ -113,11 +149,13 @@ func main() {
 	if err != nil {
 		slog.Error(err.Error())
 	}
+	httpServer.SetupLobbyWebsocketLogic(presenceService.HandleWebSocketConnection())
 
 	for _, game := range games {
 		switch game.(type) {
-		case *services.HandGameService:
+		case *handgame.HandGameService:
 			httpServer.SetupHandGameRoutes(game.GetRoute())
+			httpServer.SetupHandGameWebsocketLogic(game.HandleWebSocketConnection())
 
 		case *pong.PongService:
 			httpServer.SetupPongGameRoutes(game.GetRoute())
@@ -127,6 +165,10 @@ func main() {
 			httpServer.SetupTicTacToeGameRoutes(game.GetRoute())
 			httpServer.SetupTicTacToeGameWebsocketLogic(game.HandleWebSocketConnection())
 
+		case *connectfour.ConnectFourService:
+			httpServer.SetupConnectFourGameRoutes(game.GetRoute())
+			httpServer.SetupConnectFourGameWebsocketLogic(game.HandleWebSocketConnection())
+
 		default:
 			slog.Error("could not setup routes for unknown game service")
 		}
@@ -158,6 +200,12 @@ func getDB(databaseConfig DatabaseConfig) (db *sql.DB, err error) {
 	return db, nil
 }
 
+func grantAdmins(userService *services.UserService, admins []string) {
+	if err := userService.BootstrapAdmins(context.Background(), admins); err != nil {
+		slog.Error("could not grant admin roles", "err", err.Error())
+	}
+}
+
 func loadConfig(filename string) (*Config, error) {
 	raw, err := os.ReadFile(filename)
 	if err != nil {
@@ -0,0 +1,25 @@
+// Command protocolgen writes the TypeScript client of the event schema in
+// internal/protocol, it is run by go generate
+package main
+
+import (
+	"bytes"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/FredericoBento/HandGame/internal/protocol"
+)
+
+func main() {
+	out := flag.String("o", "assets/scripts/protocol.ts", "file to write the TypeScript to")
+	flag.Parse()
+
+	buf := &bytes.Buffer{}
+	if err := protocol.GenerateTypeScript(buf); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, buf.Bytes(), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -9,11 +9,7 @@ require (
 
 require (
 	github.com/google/uuid v1.6.0
+	github.com/gorilla/websocket v1.5.3
 	golang.org/x/crypto v0.28.0
-	golang.org/x/net v0.30.0
-)
-
-require (
-	github.com/gorilla/websocket v1.5.3 // indirect
-	google.golang.org/protobuf v1.35.1 // indirect
+	google.golang.org/protobuf v1.35.1
 )
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/FredericoBento/HandGame/internal/logger"
+	"github.com/FredericoBento/HandGame/internal/models"
+)
+
+var (
+	ErrCouldNotCreateFriendship       = errors.New("could not create friendship")
+	ErrCouldNotGetFriendship          = errors.New("could not get friendship")
+	ErrCouldNotUpdateFriendship       = errors.New("could not update friendship")
+	ErrCouldNotDeleteFriendship       = errors.New("could not delete friendship")
+	ErrCouldNotCreateFriendRepoLogger = errors.New("could not create logger for sqlite friend repository")
+)
+
+type SQLiteFriendRepository struct {
+	DB  *sql.DB
+	log *slog.Logger
+}
+
+func NewSQLiteFriendRepository(db *sql.DB) *SQLiteFriendRepository {
+	lo, err := logger.NewRepositoryLogger("sqlite", "friends", "", false)
+	if err != nil {
+		slog.Error(ErrCouldNotCreateFriendRepoLogger.Error() + " " + err.Error())
+		lo = slog.Default()
+	}
+	return &SQLiteFriendRepository{
+		DB:  db,
+		log: lo,
+	}
+}
+
+func (r *SQLiteFriendRepository) Create(ctx context.Context, friendship *models.Friendship) error {
+	query := "INSERT INTO friends(requester, addressee, status, created_at) VALUES(?, ?, ?, ?)"
+	_, err := r.DB.ExecContext(ctx, query,
+		friendship.Requester, friendship.Addressee, friendship.Status, friendship.CreatedAt.UTC(),
+	)
+	if err != nil {
+		r.log.Error(err.Error())
+		return ErrCouldNotCreateFriendship
+	}
+	return nil
+}
+
+// Get returns the friendship between the two users whoever requested it, or
+// sql.ErrNoRows when there is none
+func (r *SQLiteFriendRepository) Get(ctx context.Context, username string, other string) (*models.Friendship, error) {
+	query := `SELECT requester, addressee, status, created_at FROM friends
+	    WHERE (requester = ? AND addressee = ?) OR (requester = ? AND addressee = ?)`
+	var friendship models.Friendship
+	err := r.DB.QueryRowContext(ctx, query, username, other, other, username).Scan(
+		&friendship.Requester, &friendship.Addressee, &friendship.Status, &friendship.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		r.log.Error(err.Error())
+		return nil, ErrCouldNotGetFriendship
+	}
+	return &friendship, nil
+}
+
+// GetByUsername returns every friendship of the user, pending ones included
+func (r *SQLiteFriendRepository) GetByUsername(ctx context.Context, username string) ([]models.Friendship, error) {
+	query := `SELECT requester, addressee, status, created_at FROM friends
+	    WHERE requester = ? OR addressee = ?
+	    ORDER BY created_at`
+	rows, err := r.DB.QueryContext(ctx, query, username, username)
+	if err != nil {
+		r.log.Error(err.Error())
+		return nil, ErrCouldNotGetFriendship
+	}
+	defer rows.Close()
+
+	friendships := []models.Friendship{}
+	for rows.Next() {
+		var friendship models.Friendship
+		err := rows.Scan(&friendship.Requester, &friendship.Addressee, &friendship.Status, &friendship.CreatedAt)
+		if err != nil {
+			r.log.Error(err.Error())
+			return nil, ErrCouldNotGetFriendship
+		}
+		friendships = append(friendships, friendship)
+	}
+	if err := rows.Err(); err != nil {
+		r.log.Error(err.Error())
+		return nil, ErrCouldNotGetFriendship
+	}
+	return friendships, nil
+}
+
+// Accept returns sql.ErrNoRows when there is no request from requester to
+// addressee
+func (r *SQLiteFriendRepository) Accept(ctx context.Context, requester string, addressee string) error {
+	query := "UPDATE friends SET status = ? WHERE requester = ? AND addressee = ?"
+	result, err := r.DB.ExecContext(ctx, query, models.FriendshipAccepted, requester, addressee)
+	if err != nil {
+		r.log.Error(err.Error())
+		return ErrCouldNotUpdateFriendship
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		r.log.Error(err.Error())
+		return ErrCouldNotUpdateFriendship
+	}
+	if updated == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Delete removes the friendship between the two users whoever requested it
+func (r *SQLiteFriendRepository) Delete(ctx context.Context, username string, other string) error {
+	query := "DELETE FROM friends WHERE (requester = ? AND addressee = ?) OR (requester = ? AND addressee = ?)"
+	_, err := r.DB.ExecContext(ctx, query, username, other, other, username)
+	if err != nil {
+		r.log.Error(err.Error())
+		return ErrCouldNotDeleteFriendship
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/models"
+)
+
+func TestFriendRequestAcceptDelete(t *testing.T) {
+	repo := NewSQLiteFriendRepository(testDB)
+
+	friendship := &models.Friendship{
+		Requester: "friend_a",
+		Addressee: "friend_b",
+		Status:    models.FriendshipPending,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	if err := repo.Create(context.TODO(), friendship); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if err := repo.Create(context.TODO(), friendship); err == nil {
+		t.Errorf("expected a second request to fail but got: %v", err)
+	}
+
+	// Either side finds the same friendship
+	got, err := repo.Get(context.TODO(), "friend_b", "friend_a")
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if got.Requester != "friend_a" || got.Status != models.FriendshipPending {
+		t.Errorf("expected the pending request but got: %+v", got)
+	}
+
+	if err := repo.Accept(context.TODO(), "friend_b", "friend_a"); err != sql.ErrNoRows {
+		t.Errorf("expected the addressee to not be the requester but got: %v", err)
+	}
+	if err := repo.Accept(context.TODO(), "friend_a", "friend_b"); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+
+	friendships, err := repo.GetByUsername(context.TODO(), "friend_b")
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if len(friendships) != 1 || friendships[0].Status != models.FriendshipAccepted {
+		t.Errorf("expected one accepted friendship but got: %+v", friendships)
+	}
+
+	if err := repo.Delete(context.TODO(), "friend_b", "friend_a"); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if _, err := repo.Get(context.TODO(), "friend_a", "friend_b"); err != sql.ErrNoRows {
+		t.Errorf("expected %v but got: %v", sql.ErrNoRows, err)
+	}
+}
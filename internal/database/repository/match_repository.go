@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/logger"
+	"github.com/FredericoBento/HandGame/internal/models"
+)
+
+var (
+	ErrCouldNotInsertMatch           = errors.New("could not insert match")
+	ErrCouldNotInsertParticipant     = errors.New("could not insert match participant")
+	ErrCouldNotGetMatches            = errors.New("could not get matches")
+	ErrCouldNotGetRecord             = errors.New("could not get match record")
+	ErrMatchWithoutParticipants      = errors.New("match has no participants")
+	ErrCouldNotGetLeaderboard        = errors.New("could not get leaderboard")
+	ErrCouldNotCreateMatchRepoLogger = errors.New("could not create logger for sqlite match repository")
+)
+
+type SQLiteMatchRepository struct {
+	DB  *sql.DB
+	log *slog.Logger
+}
+
+func NewSQLiteMatchRepository(db *sql.DB) *SQLiteMatchRepository {
+	lo, err := logger.NewRepositoryLogger("sqlite", "matches", "", false)
+	if err != nil {
+		slog.Error(ErrCouldNotCreateMatchRepoLogger.Error() + " " + err.Error())
+		lo = slog.Default()
+	}
+	return &SQLiteMatchRepository{
+		DB:  db,
+		log: lo,
+	}
+}
+
+// Create stores the match and its participants, on success match.ID is set
+func (r *SQLiteMatchRepository) Create(ctx context.Context, match *models.Match) error {
+	if len(match.Participants) == 0 {
+		return ErrMatchWithoutParticipants
+	}
+	if match.FinishedAt.IsZero() {
+		match.FinishedAt = time.Now()
+	}
+
+	t, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		r.log.Error(err.Error())
+		return ErrCouldNotStartTransaction
+	}
+
+	query := "INSERT INTO matches(game, room_code, finished_at) VALUES(?, ?, ?)"
+	res, err := t.ExecContext(ctx, query, match.Game, match.RoomCode, match.FinishedAt.UTC())
+	if err != nil {
+		r.log.Error(err.Error())
+		if err = t.Rollback(); err != nil {
+			r.log.Error(err.Error())
+			return ErrCouldNotRollback
+		}
+		return ErrCouldNotInsertMatch
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		r.log.Error(err.Error())
+		if err = t.Rollback(); err != nil {
+			r.log.Error(err.Error())
+			return ErrCouldNotRollback
+		}
+		return ErrCouldNotInsertMatch
+	}
+
+	query = "INSERT INTO match_participants(match_id, username, score, result) VALUES(?, ?, ?, ?)"
+	for _, p := range match.Participants {
+		_, err = t.ExecContext(ctx, query, id, p.Username, p.Score, string(p.Result))
+		if err != nil {
+			r.log.Error(err.Error())
+			if err = t.Rollback(); err != nil {
+				r.log.Error(err.Error())
+				return ErrCouldNotRollback
+			}
+			return ErrCouldNotInsertParticipant
+		}
+	}
+
+	if err = t.Commit(); err != nil {
+		r.log.Error(err.Error())
+		return ErrCouldNotInsertMatch
+	}
+
+	match.ID = int(id)
+	for i := range match.Participants {
+		match.Participants[i].MatchID = match.ID
+	}
+	return nil
+}
+
+// GetByUsername returns the latest matches the user took part in, newest first
+func (r *SQLiteMatchRepository) GetByUsername(ctx context.Context, username string, limit int) ([]models.Match, error) {
+	query := `
+	    SELECT m.id, m.game, m.room_code, m.finished_at FROM matches m
+	    JOIN match_participants p ON p.match_id = m.id
+	    WHERE p.username = ?
+	    ORDER BY m.finished_at DESC, m.id DESC
+	    LIMIT ?`
+	rows, err := r.DB.QueryContext(ctx, query, username, limit)
+	if err != nil {
+		r.log.Error(err.Error())
+		return nil, ErrCouldNotGetMatches
+	}
+	defer rows.Close()
+
+	var matches []models.Match
+	for rows.Next() {
+		var match models.Match
+		err = rows.Scan(&match.ID, &match.Game, &match.RoomCode, &match.FinishedAt)
+		if err != nil {
+			r.log.Error(err.Error())
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+	if err = rows.Err(); err != nil {
+		r.log.Error(err.Error())
+		return nil, ErrCouldNotGetMatches
+	}
+
+	for i := range matches {
+		matches[i].Participants, err = r.getParticipants(ctx, matches[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return matches, nil
+}
+
+// GetRecord counts the wins, losses and ties of the user in a game
+func (r *SQLiteMatchRepository) GetRecord(ctx context.Context, username string, game string) (*models.MatchRecord, error) {
+	query := `
+	    SELECT
+	        COALESCE(SUM(CASE WHEN p.result = ? THEN 1 ELSE 0 END), 0),
+	        COALESCE(SUM(CASE WHEN p.result = ? THEN 1 ELSE 0 END), 0),
+	        COALESCE(SUM(CASE WHEN p.result = ? THEN 1 ELSE 0 END), 0)
+	    FROM match_participants p
+	    JOIN matches m ON m.id = p.match_id
+	    WHERE p.username = ? AND m.game = ?`
+	record := &models.MatchRecord{Game: game}
+	err := r.DB.QueryRowContext(ctx, query,
+		string(models.MatchResultWin), string(models.MatchResultLoss), string(models.MatchResultTie),
+		username, game,
+	).Scan(&record.Wins, &record.Losses, &record.Ties)
+	if err != nil {
+		r.log.Error(err.Error())
+		return nil, ErrCouldNotGetRecord
+	}
+	return record, nil
+}
+
+// GetLeaderboard ranks the users by wins, then win rate and then games played,
+// an empty game ranks them across every game and leaves the rating out
+func (r *SQLiteMatchRepository) GetLeaderboard(ctx context.Context, game string, limit int, offset int) ([]models.LeaderboardEntry, error) {
+	query := `
+	    SELECT
+	        p.username,
+	        SUM(CASE WHEN p.result = ? THEN 1 ELSE 0 END) AS wins,
+	        SUM(CASE WHEN p.result = ? THEN 1 ELSE 0 END) AS losses,
+	        SUM(CASE WHEN p.result = ? THEN 1 ELSE 0 END) AS ties,
+	        COUNT(*) AS played,
+	        CAST(ROUND(COALESCE(MAX(r.rating), 0)) AS INTEGER)
+	    FROM match_participants p
+	    JOIN matches m ON m.id = p.match_id
+	    LEFT JOIN ratings r ON r.username = p.username AND r.game = ?
+	    WHERE ? = '' OR m.game = ?
+	    GROUP BY p.username
+	    ORDER BY wins DESC, CAST(wins AS REAL) / COUNT(*) DESC, played DESC, p.username
+	    LIMIT ? OFFSET ?`
+	rows, err := r.DB.QueryContext(ctx, query,
+		string(models.MatchResultWin), string(models.MatchResultLoss), string(models.MatchResultTie),
+		game, game, game, limit, offset,
+	)
+	if err != nil {
+		r.log.Error(err.Error())
+		return nil, ErrCouldNotGetLeaderboard
+	}
+	defer rows.Close()
+
+	var entries []models.LeaderboardEntry
+	for rows.Next() {
+		entry := models.LeaderboardEntry{Rank: offset + len(entries) + 1}
+		err = rows.Scan(&entry.Username, &entry.Wins, &entry.Losses, &entry.Ties, &entry.Played, &entry.Rating)
+		if err != nil {
+			r.log.Error(err.Error())
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err = rows.Err(); err != nil {
+		r.log.Error(err.Error())
+		return nil, ErrCouldNotGetLeaderboard
+	}
+	return entries, nil
+}
+
+// CountPlayers returns how many users played at least one match of the game
+func (r *SQLiteMatchRepository) CountPlayers(ctx context.Context, game string) (int, error) {
+	query := `
+	    SELECT COUNT(DISTINCT p.username) FROM match_participants p
+	    JOIN matches m ON m.id = p.match_id
+	    WHERE ? = '' OR m.game = ?`
+	var count int
+	if err := r.DB.QueryRowContext(ctx, query, game, game).Scan(&count); err != nil {
+		r.log.Error(err.Error())
+		return 0, ErrCouldNotGetLeaderboard
+	}
+	return count, nil
+}
+
+func (r *SQLiteMatchRepository) getParticipants(ctx context.Context, matchID int) ([]models.MatchParticipant, error) {
+	query := "SELECT match_id, username, score, result FROM match_participants WHERE match_id = ? ORDER BY id"
+	rows, err := r.DB.QueryContext(ctx, query, matchID)
+	if err != nil {
+		r.log.Error(err.Error())
+		return nil, ErrCouldNotGetMatches
+	}
+	defer rows.Close()
+
+	var participants []models.MatchParticipant
+	for rows.Next() {
+		var p models.MatchParticipant
+		var result string
+		err = rows.Scan(&p.MatchID, &p.Username, &p.Score, &result)
+		if err != nil {
+			r.log.Error(err.Error())
+			return nil, err
+		}
+		p.Result = models.MatchResult(result)
+		participants = append(participants, p)
+	}
+	return participants, rows.Err()
+}
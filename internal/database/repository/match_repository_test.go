@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/models"
+)
+
+func TestMatchCreate(t *testing.T) {
+	repo := NewSQLiteMatchRepository(testDB)
+
+	t.Run("WithoutParticipants", func(t *testing.T) {
+		err := repo.Create(context.TODO(), &models.Match{Game: models.GamePong, RoomCode: "ABCD"})
+		if err != ErrMatchWithoutParticipants {
+			t.Errorf("expected %v but got: %v", ErrMatchWithoutParticipants, err)
+		}
+	})
+
+	t.Run("SetsIDs", func(t *testing.T) {
+		match := &models.Match{
+			Game:     models.GamePong,
+			RoomCode: "ABCD",
+			Participants: []models.MatchParticipant{
+				{Username: "create1", Score: 5, Result: models.MatchResultWin},
+				{Username: "create2", Score: 3, Result: models.MatchResultLoss},
+			},
+		}
+		if err := repo.Create(context.TODO(), match); err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if match.ID == 0 {
+			t.Errorf("expected match id to be set")
+		}
+		for _, p := range match.Participants {
+			if p.MatchID != match.ID {
+				t.Errorf("expected participant match id %d got: %d", match.ID, p.MatchID)
+			}
+		}
+	})
+}
+
+func TestMatchGetByUsername(t *testing.T) {
+	repo := NewSQLiteMatchRepository(testDB)
+	now := time.Now()
+
+	for i, game := range []string{models.GameTicTacToe, models.GamePong, models.GameTicTacToe} {
+		err := repo.Create(context.TODO(), &models.Match{
+			Game:       game,
+			RoomCode:   "HIST",
+			FinishedAt: now.Add(time.Duration(i) * time.Minute),
+			Participants: []models.MatchParticipant{
+				{Username: "history1", Result: models.MatchResultWin},
+				{Username: "history2", Result: models.MatchResultLoss},
+			},
+		})
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+	}
+
+	matches, err := repo.GetByUsername(context.TODO(), "history2", 2)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 matches got: %d", len(matches))
+	}
+	if matches[0].Game != models.GameTicTacToe || matches[1].Game != models.GamePong {
+		t.Errorf("expected newest matches first, got %s and %s", matches[0].Game, matches[1].Game)
+	}
+	if len(matches[0].Participants) != 2 {
+		t.Errorf("expected 2 participants got: %d", len(matches[0].Participants))
+	}
+
+	matches, err = repo.GetByUsername(context.TODO(), "nobody", 10)
+	if err != nil {
+		t.Errorf("expected no error but got: %v", err)
+	}
+	if matches != nil {
+		t.Errorf("expected matches to be nil but got: %v", matches)
+	}
+}
+
+func TestMatchGetRecord(t *testing.T) {
+	repo := NewSQLiteMatchRepository(testDB)
+
+	results := [][2]models.MatchResult{
+		{models.MatchResultWin, models.MatchResultLoss},
+		{models.MatchResultWin, models.MatchResultLoss},
+		{models.MatchResultLoss, models.MatchResultWin},
+		{models.MatchResultTie, models.MatchResultTie},
+	}
+	for _, result := range results {
+		err := repo.Create(context.TODO(), &models.Match{
+			Game:     models.GameTicTacToe,
+			RoomCode: "RECD",
+			Participants: []models.MatchParticipant{
+				{Username: "record1", Result: result[0]},
+				{Username: "record2", Result: result[1]},
+			},
+		})
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+	}
+
+	record, err := repo.GetRecord(context.TODO(), "record1", models.GameTicTacToe)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if record.Wins != 2 || record.Losses != 1 || record.Ties != 1 {
+		t.Errorf("expected 2-1-1 got: %d-%d-%d", record.Wins, record.Losses, record.Ties)
+	}
+
+	record, err = repo.GetRecord(context.TODO(), "record1", models.GamePong)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if record.Played() != 0 {
+		t.Errorf("expected no pong matches got: %d", record.Played())
+	}
+}
+
+func TestMatchGetLeaderboard(t *testing.T) {
+	repo := NewSQLiteMatchRepository(testDB)
+	game := "leaderboard"
+
+	// alice 2-0, bob 2-1, carol 0-3, dave 1-1
+	results := [][4]string{
+		{"alice", "win", "carol", "loss"},
+		{"alice", "win", "carol", "loss"},
+		{"bob", "win", "carol", "loss"},
+		{"bob", "win", "dave", "loss"},
+		{"dave", "win", "bob", "loss"},
+	}
+	for _, r := range results {
+		err := repo.Create(context.TODO(), &models.Match{
+			Game:     game,
+			RoomCode: "LEAD",
+			Participants: []models.MatchParticipant{
+				{Username: r[0], Result: models.MatchResult(r[1])},
+				{Username: r[2], Result: models.MatchResult(r[3])},
+			},
+		})
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+	}
+
+	ratings := NewSQLiteRatingRepository(testDB)
+	if err := ratings.Save(context.TODO(), &models.Rating{Username: "alice", Game: game, Rating: 1234.6}); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+
+	count, err := repo.CountPlayers(context.TODO(), game)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("expected 4 players got: %d", count)
+	}
+
+	entries, err := repo.GetLeaderboard(context.TODO(), game, 10, 0)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	expected := []string{"alice", "bob", "dave", "carol"}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries got: %d", len(expected), len(entries))
+	}
+	for i, username := range expected {
+		if entries[i].Username != username || entries[i].Rank != i+1 {
+			t.Errorf("expected %s at rank %d got: %s at rank %d", username, i+1, entries[i].Username, entries[i].Rank)
+		}
+	}
+	if entries[1].Played != 3 || entries[1].Wins != 2 || entries[1].Losses != 1 {
+		t.Errorf("unexpected standing for bob: %+v", entries[1])
+	}
+	if entries[0].Rating != 1235 || entries[1].Rating != 0 {
+		t.Errorf("expected only alice to be rated got: %d and %d", entries[0].Rating, entries[1].Rating)
+	}
+
+	entries, err = repo.GetLeaderboard(context.TODO(), game, 2, 2)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Username != "dave" || entries[0].Rank != 3 {
+		t.Errorf("expected second page to start with dave at rank 3 got: %+v", entries)
+	}
+}
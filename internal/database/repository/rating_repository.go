@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/logger"
+	"github.com/FredericoBento/HandGame/internal/models"
+)
+
+var (
+	ErrCouldNotGetRating              = errors.New("could not get rating")
+	ErrCouldNotSaveRating             = errors.New("could not save rating")
+	ErrCouldNotCreateRatingRepoLogger = errors.New("could not create logger for sqlite rating repository")
+)
+
+type SQLiteRatingRepository struct {
+	DB  *sql.DB
+	log *slog.Logger
+}
+
+func NewSQLiteRatingRepository(db *sql.DB) *SQLiteRatingRepository {
+	lo, err := logger.NewRepositoryLogger("sqlite", "ratings", "", false)
+	if err != nil {
+		slog.Error(ErrCouldNotCreateRatingRepoLogger.Error() + " " + err.Error())
+		lo = slog.Default()
+	}
+	return &SQLiteRatingRepository{
+		DB:  db,
+		log: lo,
+	}
+}
+
+// Get returns sql.ErrNoRows when the user has no rating in the game yet
+func (r *SQLiteRatingRepository) Get(ctx context.Context, username string, game string) (*models.Rating, error) {
+	query := "SELECT username, game, rating, games, updated_at FROM ratings WHERE username = ? AND game = ?"
+	var rating models.Rating
+	err := r.DB.QueryRowContext(ctx, query, username, game).Scan(
+		&rating.Username, &rating.Game, &rating.Rating, &rating.Games, &rating.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		r.log.Error(err.Error())
+		return nil, ErrCouldNotGetRating
+	}
+	return &rating, nil
+}
+
+func (r *SQLiteRatingRepository) GetByUsername(ctx context.Context, username string) ([]models.Rating, error) {
+	query := "SELECT username, game, rating, games, updated_at FROM ratings WHERE username = ? ORDER BY game"
+	rows, err := r.DB.QueryContext(ctx, query, username)
+	if err != nil {
+		r.log.Error(err.Error())
+		return nil, ErrCouldNotGetRating
+	}
+	defer rows.Close()
+
+	var ratings []models.Rating
+	for rows.Next() {
+		var rating models.Rating
+		err = rows.Scan(&rating.Username, &rating.Game, &rating.Rating, &rating.Games, &rating.UpdatedAt)
+		if err != nil {
+			r.log.Error(err.Error())
+			return nil, err
+		}
+		ratings = append(ratings, rating)
+	}
+	return ratings, rows.Err()
+}
+
+// Save inserts or updates every rating in a single transaction
+func (r *SQLiteRatingRepository) Save(ctx context.Context, ratings ...*models.Rating) error {
+	t, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		r.log.Error(err.Error())
+		return ErrCouldNotStartTransaction
+	}
+
+	query := `
+	    INSERT INTO ratings(username, game, rating, games, updated_at) VALUES(?, ?, ?, ?, ?)
+	    ON CONFLICT(username, game) DO UPDATE SET
+	        rating = excluded.rating,
+	        games = excluded.games,
+	        updated_at = excluded.updated_at`
+	now := time.Now().UTC()
+	for _, rating := range ratings {
+		_, err = t.ExecContext(ctx, query, rating.Username, rating.Game, rating.Rating, rating.Games, now)
+		if err != nil {
+			r.log.Error(err.Error())
+			if err = t.Rollback(); err != nil {
+				r.log.Error(err.Error())
+				return ErrCouldNotRollback
+			}
+			return ErrCouldNotSaveRating
+		}
+		rating.UpdatedAt = now
+	}
+
+	if err = t.Commit(); err != nil {
+		r.log.Error(err.Error())
+		return ErrCouldNotSaveRating
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/FredericoBento/HandGame/internal/models"
+)
+
+func TestRatingSave(t *testing.T) {
+	repo := NewSQLiteRatingRepository(testDB)
+
+	_, err := repo.Get(context.TODO(), "rated", models.GamePong)
+	if err != sql.ErrNoRows {
+		t.Errorf("expected %v but got: %v", sql.ErrNoRows, err)
+	}
+
+	pong := models.NewRating("rated", models.GamePong)
+	tictactoe := models.NewRating("rated", models.GameTicTacToe)
+	if err := repo.Save(context.TODO(), pong, tictactoe); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+
+	pong.Rating = 1216.5
+	pong.Games = 1
+	if err := repo.Save(context.TODO(), pong); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+
+	rating, err := repo.Get(context.TODO(), "rated", models.GamePong)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if rating.Rating != 1216.5 || rating.Games != 1 {
+		t.Errorf("expected the updated rating got: %+v", rating)
+	}
+
+	ratings, err := repo.GetByUsername(context.TODO(), "rated")
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if len(ratings) != 2 {
+		t.Errorf("expected 2 ratings got: %d", len(ratings))
+	}
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/FredericoBento/HandGame/internal/models"
 )
@@ -10,4 +11,35 @@ type UserRepository interface {
 	GetByUsername(ctx context.Context, username string) (*models.User, error)
 	GetAll(ctx context.Context) ([]models.User, error)
 	Create(ctx context.Context, user *models.User) error
+	UpdateRole(ctx context.Context, username string, role models.Role) error
+}
+
+type MatchRepository interface {
+	Create(ctx context.Context, match *models.Match) error
+	GetByUsername(ctx context.Context, username string, limit int) ([]models.Match, error)
+	GetRecord(ctx context.Context, username string, game string) (*models.MatchRecord, error)
+	GetLeaderboard(ctx context.Context, game string, limit int, offset int) ([]models.LeaderboardEntry, error)
+	CountPlayers(ctx context.Context, game string) (int, error)
+}
+
+type RatingRepository interface {
+	Get(ctx context.Context, username string, game string) (*models.Rating, error)
+	GetByUsername(ctx context.Context, username string) ([]models.Rating, error)
+	Save(ctx context.Context, ratings ...*models.Rating) error
+}
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.Session) error
+	Get(ctx context.Context, token string) (*models.Session, error)
+	Touch(ctx context.Context, token string, lastSeenAt time.Time, expiresAt time.Time) error
+	Delete(ctx context.Context, token string) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type FriendRepository interface {
+	Create(ctx context.Context, friendship *models.Friendship) error
+	Get(ctx context.Context, username string, other string) (*models.Friendship, error)
+	GetByUsername(ctx context.Context, username string) ([]models.Friendship, error)
+	Accept(ctx context.Context, requester string, addressee string) error
+	Delete(ctx context.Context, username string, other string) error
 }
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/logger"
+	"github.com/FredericoBento/HandGame/internal/models"
+)
+
+var (
+	ErrCouldNotCreateSession           = errors.New("could not create session")
+	ErrCouldNotGetSession              = errors.New("could not get session")
+	ErrCouldNotUpdateSession           = errors.New("could not update session")
+	ErrCouldNotDeleteSession           = errors.New("could not delete session")
+	ErrCouldNotCreateSessionRepoLogger = errors.New("could not create logger for sqlite session repository")
+)
+
+type SQLiteSessionRepository struct {
+	DB  *sql.DB
+	log *slog.Logger
+}
+
+func NewSQLiteSessionRepository(db *sql.DB) *SQLiteSessionRepository {
+	lo, err := logger.NewRepositoryLogger("sqlite", "sessions", "", false)
+	if err != nil {
+		slog.Error(ErrCouldNotCreateSessionRepoLogger.Error() + " " + err.Error())
+		lo = slog.Default()
+	}
+	return &SQLiteSessionRepository{
+		DB:  db,
+		log: lo,
+	}
+}
+
+func (r *SQLiteSessionRepository) Create(ctx context.Context, session *models.Session) error {
+	query := "INSERT INTO sessions(token, username, created_at, last_seen_at, expires_at) VALUES(?, ?, ?, ?, ?)"
+	_, err := r.DB.ExecContext(ctx, query,
+		session.Token, session.Username, session.CreatedAt.UTC(), session.LastSeenAt.UTC(), session.ExpiresAt.UTC(),
+	)
+	if err != nil {
+		r.log.Error(err.Error())
+		return ErrCouldNotCreateSession
+	}
+	return nil
+}
+
+// Get returns sql.ErrNoRows when there is no session with the token
+func (r *SQLiteSessionRepository) Get(ctx context.Context, token string) (*models.Session, error) {
+	query := "SELECT token, username, created_at, last_seen_at, expires_at FROM sessions WHERE token = ?"
+	var session models.Session
+	err := r.DB.QueryRowContext(ctx, query, token).Scan(
+		&session.Token, &session.Username, &session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		r.log.Error(err.Error())
+		return nil, ErrCouldNotGetSession
+	}
+	return &session, nil
+}
+
+// Touch marks the session as used and pushes its expiry forward
+func (r *SQLiteSessionRepository) Touch(ctx context.Context, token string, lastSeenAt time.Time, expiresAt time.Time) error {
+	query := "UPDATE sessions SET last_seen_at = ?, expires_at = ? WHERE token = ?"
+	_, err := r.DB.ExecContext(ctx, query, lastSeenAt.UTC(), expiresAt.UTC(), token)
+	if err != nil {
+		r.log.Error(err.Error())
+		return ErrCouldNotUpdateSession
+	}
+	return nil
+}
+
+func (r *SQLiteSessionRepository) Delete(ctx context.Context, token string) error {
+	_, err := r.DB.ExecContext(ctx, "DELETE FROM sessions WHERE token = ?", token)
+	if err != nil {
+		r.log.Error(err.Error())
+		return ErrCouldNotDeleteSession
+	}
+	return nil
+}
+
+// DeleteExpired removes every session that expired before now and returns
+// how many were removed
+func (r *SQLiteSessionRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.DB.ExecContext(ctx, "DELETE FROM sessions WHERE expires_at < ?", now.UTC())
+	if err != nil {
+		r.log.Error(err.Error())
+		return 0, ErrCouldNotDeleteSession
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		r.log.Error(err.Error())
+		return 0, ErrCouldNotDeleteSession
+	}
+	return deleted, nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/models"
+)
+
+func newTestSession(token string, now time.Time, expiresIn time.Duration) *models.Session {
+	return &models.Session{
+		Token:      token,
+		Username:   "sessionuser",
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(expiresIn),
+	}
+}
+
+func TestSessionCreateGetDelete(t *testing.T) {
+	repo := NewSQLiteSessionRepository(testDB)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	if err := repo.Create(context.TODO(), newTestSession("token-1", now, time.Hour)); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+
+	session, err := repo.Get(context.TODO(), "token-1")
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if session.Username != "sessionuser" || !session.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("expected the created session but got: %+v", session)
+	}
+
+	later := now.Add(time.Minute)
+	if err := repo.Touch(context.TODO(), "token-1", later, later.Add(time.Hour)); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	session, err = repo.Get(context.TODO(), "token-1")
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if !session.LastSeenAt.Equal(later) || !session.ExpiresAt.Equal(later.Add(time.Hour)) {
+		t.Errorf("expected the session to be touched but got: %+v", session)
+	}
+
+	if err := repo.Delete(context.TODO(), "token-1"); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if _, err := repo.Get(context.TODO(), "token-1"); err != sql.ErrNoRows {
+		t.Errorf("expected %v but got: %v", sql.ErrNoRows, err)
+	}
+}
+
+func TestSessionDeleteExpired(t *testing.T) {
+	repo := NewSQLiteSessionRepository(testDB)
+	now := time.Now().UTC()
+
+	if err := repo.Create(context.TODO(), newTestSession("expired", now.Add(-2*time.Hour), time.Hour)); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if err := repo.Create(context.TODO(), newTestSession("active", now, time.Hour)); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+
+	deleted, err := repo.DeleteExpired(context.TODO(), now)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("expected 1 session deleted but got: %d", deleted)
+	}
+	if _, err := repo.Get(context.TODO(), "expired"); err != sql.ErrNoRows {
+		t.Errorf("expected %v but got: %v", sql.ErrNoRows, err)
+	}
+	if _, err := repo.Get(context.TODO(), "active"); err != nil {
+		t.Errorf("expected no error but got: %v", err)
+	}
+}
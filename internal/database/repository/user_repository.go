@@ -16,6 +16,7 @@ var (
 	ErrCouldNotRollback         = errors.New("could not rollback transaction")
 	ErrCouldNotCreateLogger     = errors.New("could not create logger for sqlite user repository")
 	ErrCouldNotGetByUsername    = errors.New("could not get user by username")
+	ErrCouldNotUpdateRole       = errors.New("could not update user role")
 )
 
 type SQLiteUserRepository struct {
@@ -36,7 +37,7 @@ func NewSQLiteUserRepository(db *sql.DB) *SQLiteUserRepository {
 }
 
 func (r *SQLiteUserRepository) GetAll(ctx context.Context) ([]models.User, error) {
-	rows, err := r.DB.Query("SELECT * from users")
+	rows, err := r.DB.Query("SELECT id, username, password, role from users")
 	if err != nil {
 		r.log.Error(err.Error())
 		return nil, err
@@ -46,7 +47,7 @@ func (r *SQLiteUserRepository) GetAll(ctx context.Context) ([]models.User, error
 
 	for rows.Next() {
 		var user models.User
-		err = rows.Scan(&user.ID, &user.Username, &user.Password)
+		err = rows.Scan(&user.ID, &user.Username, &user.Password, &user.Role)
 		if err != nil {
 			r.log.Error(err.Error())
 			return nil, err
@@ -62,8 +63,11 @@ func (r *SQLiteUserRepository) Create(ctx context.Context, user *models.User) er
 		r.log.Error(err.Error())
 		return ErrCouldNotStartTransaction
 	}
-	query := "INSERT INTO users(username, password) VALUES(?, ?)"
-	_, err = t.Exec(query, user.Username, user.Password)
+	if user.Role == "" {
+		user.Role = models.RolePlayer
+	}
+	query := "INSERT INTO users(username, password, role) VALUES(?, ?, ?)"
+	_, err = t.Exec(query, user.Username, user.Password, user.Role)
 	if err != nil {
 		if err = t.Rollback(); err != nil {
 			r.log.Error(err.Error())
@@ -79,7 +83,7 @@ func (r *SQLiteUserRepository) Create(ctx context.Context, user *models.User) er
 }
 
 func (r *SQLiteUserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
-	query := "SELECT * FROM users WHERE username = ?"
+	query := "SELECT id, username, password, role FROM users WHERE username = ?"
 	rows, err := r.DB.Query(query, username)
 	if err != nil {
 		r.log.Error(err.Error())
@@ -91,7 +95,7 @@ func (r *SQLiteUserRepository) GetByUsername(ctx context.Context, username strin
 		return nil, sql.ErrNoRows
 	}
 
-	err = rows.Scan(&user.ID, &user.Username, &user.Password)
+	err = rows.Scan(&user.ID, &user.Username, &user.Password, &user.Role)
 	if err != nil {
 		r.log.Error(err.Error())
 		return nil, err
@@ -99,3 +103,21 @@ func (r *SQLiteUserRepository) GetByUsername(ctx context.Context, username strin
 
 	return &user, nil
 }
+
+// UpdateRole returns sql.ErrNoRows when there is no user with the username
+func (r *SQLiteUserRepository) UpdateRole(ctx context.Context, username string, role models.Role) error {
+	result, err := r.DB.ExecContext(ctx, "UPDATE users SET role = ? WHERE username = ?", role, username)
+	if err != nil {
+		r.log.Error(err.Error())
+		return ErrCouldNotUpdateRole
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		r.log.Error(err.Error())
+		return ErrCouldNotUpdateRole
+	}
+	if updated == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -99,3 +99,36 @@ func TestGetAll(t *testing.T) {
 		}
 	})
 }
+
+func TestUpdateRole(t *testing.T) {
+	repo := NewSQLiteUserRepository(testDB)
+
+	err := repo.Create(context.TODO(), &models.User{Username: "promoted", Password: "randomdata"})
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+
+	user, err := repo.GetByUsername(context.TODO(), "promoted")
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if user.Role != models.RolePlayer {
+		t.Errorf("expected new users to be %s but got: %s", models.RolePlayer, user.Role)
+	}
+
+	if err = repo.UpdateRole(context.TODO(), "promoted", models.RoleAdmin); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	user, err = repo.GetByUsername(context.TODO(), "promoted")
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if user.Role != models.RoleAdmin {
+		t.Errorf("expected %s but got: %s", models.RoleAdmin, user.Role)
+	}
+
+	err = repo.UpdateRole(context.TODO(), "missing", models.RoleAdmin)
+	if err != sql.ErrNoRows {
+		t.Errorf("expected %v but got: %v", sql.ErrNoRows, err)
+	}
+}
@@ -9,6 +9,22 @@ func CreateTables(db *sql.DB) error {
 		return err
 	}
 
+	if err = createMatchTables(db); err != nil {
+		return err
+	}
+
+	if err = createRatingTable(db); err != nil {
+		return err
+	}
+
+	if err = createSessionTable(db); err != nil {
+		return err
+	}
+
+	if err = createFriendTable(db); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -17,11 +33,109 @@ func createUserTable(db *sql.DB) error {
 	    CREATE TABLE IF NOT EXISTS users (
 	        id INTEGER PRIMARY KEY AUTOINCREMENT,
 	        username TEXT NOT NULL,
-	        password TEXT NOT NULL
+	        password TEXT NOT NULL,
+	        role TEXT NOT NULL DEFAULT 'player'
+	    );`
+
+	_, err := db.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	// Tables created before users had roles
+	return addColumnIfMissing(db, "users", "role", "TEXT NOT NULL DEFAULT 'player'")
+}
+
+func addColumnIfMissing(db *sql.DB, table string, column string, definition string) error {
+	rows, err := db.Query("SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = db.Exec("ALTER TABLE " + table + " ADD COLUMN " + column + " " + definition)
+	return err
+}
+
+func createMatchTables(db *sql.DB) error {
+	query := `
+	    CREATE TABLE IF NOT EXISTS matches (
+	        id INTEGER PRIMARY KEY AUTOINCREMENT,
+	        game TEXT NOT NULL,
+	        room_code TEXT NOT NULL,
+	        finished_at DATETIME NOT NULL
+	    );
+	    CREATE TABLE IF NOT EXISTS match_participants (
+	        id INTEGER PRIMARY KEY AUTOINCREMENT,
+	        match_id INTEGER NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+	        username TEXT NOT NULL,
+	        score INTEGER NOT NULL DEFAULT 0,
+	        result TEXT NOT NULL
+	    );
+	    CREATE INDEX IF NOT EXISTS idx_match_participants_username ON match_participants(username);`
+
+	_, err := db.Exec(query)
+
+	return err
+}
+
+func createRatingTable(db *sql.DB) error {
+	query := `
+	    CREATE TABLE IF NOT EXISTS ratings (
+	        username TEXT NOT NULL,
+	        game TEXT NOT NULL,
+	        rating REAL NOT NULL,
+	        games INTEGER NOT NULL DEFAULT 0,
+	        updated_at DATETIME NOT NULL,
+	        PRIMARY KEY (username, game)
 	    );`
 
 	_, err := db.Exec(query)
 
 	return err
+}
+
+func createSessionTable(db *sql.DB) error {
+	query := `
+	    CREATE TABLE IF NOT EXISTS sessions (
+	        token TEXT PRIMARY KEY,
+	        username TEXT NOT NULL,
+	        created_at DATETIME NOT NULL,
+	        last_seen_at DATETIME NOT NULL,
+	        expires_at DATETIME NOT NULL
+	    );
+	    CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions(expires_at);`
+
+	_, err := db.Exec(query)
+
+	return err
+}
+
+func createFriendTable(db *sql.DB) error {
+	query := `
+	    CREATE TABLE IF NOT EXISTS friends (
+	        requester TEXT NOT NULL,
+	        addressee TEXT NOT NULL,
+	        status TEXT NOT NULL DEFAULT 'pending',
+	        created_at DATETIME NOT NULL,
+	        PRIMARY KEY (requester, addressee)
+	    );
+	    CREATE INDEX IF NOT EXISTS idx_friends_addressee ON friends(addressee);`
 
+	_, err := db.Exec(query)
+
+	return err
 }
@@ -25,6 +25,7 @@ var (
 	ErrGameNotFound        = errors.New("game not found")
 	ErrGameCouldNotGetMore = errors.New("could not get more info of game")
 	ErrGameIsInactive      = errors.New("game is inactive")
+	ErrCannotChangeOwnRole = errors.New("you can not change your own role")
 )
 
 type AdminHandler struct {
@@ -51,6 +52,10 @@ func (h *AdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
 		h.Get(w, r)
+	case http.MethodPost:
+		h.Post(w, r)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
 	}
 }
 
@@ -81,6 +86,11 @@ func (h *AdminHandler) Post(w http.ResponseWriter, r *http.Request) {
 	switch route[len(route)-1] {
 	case "dashboard":
 		h.GetDashboard(w, r)
+	case "users":
+		h.PostUserRole(w, r)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, "404 page not found")
 	}
 }
 
@@ -135,6 +145,49 @@ func (h *AdminHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
 
 }
 
+// PostUserRole promotes or demotes the user in the username form value by one
+// role, the updated row of the users table is sent back
+func (h *AdminHandler) PostUserRole(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	username := r.PostFormValue("username")
+
+	logged, _ := GetLoggedUser(r)
+	if logged != nil && logged.Username == username {
+		http.Error(w, ErrCannotChangeOwnRole.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.userService.GetUserByUsername(r.Context(), username)
+	if err != nil {
+		http.Error(w, services.ErrCouldNotFindUser.Error(), http.StatusNotFound)
+		return
+	}
+
+	role := user.Role
+	switch r.PostFormValue("action") {
+	case "promote":
+		role = user.Role.Promoted()
+	case "demote":
+		role = user.Role.Demoted()
+	default:
+		http.Error(w, "Action not found", http.StatusBadRequest)
+		return
+	}
+
+	user, err = h.userService.SetRole(r.Context(), username, role)
+	if err != nil {
+		h.log.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.log.Info("Changed user role", "username", username, "role", role)
+
+	admin_views.UserRow(*user).Render(r.Context(), w)
+}
+
 func (h *AdminHandler) moreGame(w http.ResponseWriter, r *http.Request, gameID string) {
 	game, ok := h.adminService.GetGame(gameID)
 	if !ok {
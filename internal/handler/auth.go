@@ -272,7 +272,7 @@ func (ah *AuthHandler) PostSignIn(w http.ResponseWriter, r *http.Request) {
 		http.SetCookie(w, &cookie)
 		http.SetCookie(w, &usernameCookie)
 
-		if ah.authService.IsAdmin(u.Username) {
+		if ah.authService.IsAdmin(u) {
 			Redirect(w, r, "/admin")
 			return
 		}
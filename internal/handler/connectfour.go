@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/FredericoBento/HandGame/internal/logger"
+	"github.com/FredericoBento/HandGame/internal/views"
+	"github.com/FredericoBento/HandGame/internal/views/components"
+	"github.com/FredericoBento/HandGame/internal/views/connectfour_views"
+	"github.com/a-h/templ"
+)
+
+type ConnectFourHandler struct {
+	log *slog.Logger
+}
+
+type ConnectFourViewProps struct {
+	title   string
+	content templ.Component
+}
+
+func NewConnectFourHandler() *ConnectFourHandler {
+	lo, err := logger.NewHandlerLogger("ConnectFourHandler", "", false)
+	if err != nil {
+		lo = slog.New(slog.Default().Handler())
+		lo.Error(err.Error())
+	}
+	return &ConnectFourHandler{
+		log: lo,
+	}
+}
+
+func (h *ConnectFourHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/connectfour/home":
+		h.home(w, r)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("404 - Not Found"))
+	}
+}
+
+func (h *ConnectFourHandler) home(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.getHome(w, r)
+	}
+}
+
+func (h *ConnectFourHandler) getHome(w http.ResponseWriter, r *http.Request) {
+	if !IsLogged(r) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	h.View(w, r, ConnectFourViewProps{
+		content: connectfour_views.Home(),
+	})
+}
+
+func (h *ConnectFourHandler) View(w http.ResponseWriter, r *http.Request, props ConnectFourViewProps) {
+	if IsHTMX(r) {
+		props.content.Render(r.Context(), w)
+	} else {
+		views.Page(props.title, components.DefaultLoggedNavbar(), props.content).Render(r.Context(), w)
+	}
+}
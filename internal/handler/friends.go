@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/FredericoBento/HandGame/internal/logger"
+	"github.com/FredericoBento/HandGame/internal/services"
+	"github.com/FredericoBento/HandGame/internal/services/presence"
+	"github.com/FredericoBento/HandGame/internal/views/friends_views"
+)
+
+var (
+	ErrNotFriends = errors.New("you can only invite your friends")
+)
+
+// FriendsHandler serves the friends panel of the navbar, every action sends
+// the panel back
+type FriendsHandler struct {
+	friendService *services.FriendService
+	presence      *presence.Service
+	log           *slog.Logger
+}
+
+func NewFriendsHandler(friendService *services.FriendService, presenceService *presence.Service) *FriendsHandler {
+	lo, err := logger.NewHandlerLogger("FriendsHandler", "", false)
+	if err != nil {
+		lo = slog.New(slog.Default().Handler())
+		lo.Error(err.Error())
+	}
+	return &FriendsHandler{
+		friendService: friendService,
+		presence:      presenceService,
+		log:           lo,
+	}
+}
+
+func (h *FriendsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !IsLogged(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.URL.Path == "/friends" {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetPanel(w, r)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/friends/request":
+		h.PostRequest(w, r)
+	case "/friends/accept":
+		h.PostAccept(w, r)
+	case "/friends/remove":
+		h.PostRemove(w, r)
+	case "/friends/invite":
+		h.PostInvite(w, r)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("404 - Not Found"))
+	}
+}
+
+func (h *FriendsHandler) GetPanel(w http.ResponseWriter, r *http.Request) {
+	h.panel(w, r, "", nil)
+}
+
+// PostRequest sends a friend request to the user in the username form value
+func (h *FriendsHandler) PostRequest(w http.ResponseWriter, r *http.Request) {
+	user, _ := GetLoggedUser(r)
+	username := r.PostFormValue("username")
+
+	_, err := h.friendService.Request(r.Context(), user.Username, username)
+	if err != nil {
+		h.panel(w, r, "", err)
+		return
+	}
+	h.panel(w, r, "Friend request sent to "+username, nil)
+}
+
+func (h *FriendsHandler) PostAccept(w http.ResponseWriter, r *http.Request) {
+	user, _ := GetLoggedUser(r)
+	err := h.friendService.Accept(r.Context(), user.Username, r.PostFormValue("username"))
+	h.panel(w, r, "", err)
+}
+
+// PostRemove removes a friend, it also declines or cancels a request
+func (h *FriendsHandler) PostRemove(w http.ResponseWriter, r *http.Request) {
+	user, _ := GetLoggedUser(r)
+	err := h.friendService.Remove(r.Context(), user.Username, r.PostFormValue("username"))
+	h.panel(w, r, "", err)
+}
+
+// PostInvite invites the friend in the username form value to the room the
+// user plays in of the game form value
+func (h *FriendsHandler) PostInvite(w http.ResponseWriter, r *http.Request) {
+	user, _ := GetLoggedUser(r)
+	username := r.PostFormValue("username")
+	game := r.PostFormValue("game")
+
+	friends, err := h.friendService.AreFriends(r.Context(), user.Username, username)
+	if err != nil {
+		h.panel(w, r, "", err)
+		return
+	}
+	if !friends {
+		h.panel(w, r, "", ErrNotFriends)
+		return
+	}
+
+	invite, err := h.presence.Invite(user.Username, username, game)
+	if err != nil {
+		h.panel(w, r, "", err)
+		return
+	}
+	h.log.Info("Invited friend", "from", invite.From, "to", invite.To, "game", invite.Game, "code", invite.Code)
+	h.panel(w, r, "Invited "+username+" to "+game+" room "+invite.Code, nil)
+}
+
+func (h *FriendsHandler) panel(w http.ResponseWriter, r *http.Request, notice string, actionErr error) {
+	user, _ := GetLoggedUser(r)
+	friends, err := h.friendService.List(r.Context(), user.Username)
+	if err != nil {
+		h.log.Error(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	props := friends_views.PanelProps{
+		Friends: friends,
+		Notice:  notice,
+	}
+	if actionErr != nil {
+		props.Error = actionErr.Error()
+	}
+	if h.presence != nil {
+		snapshot := h.presence.Snapshot()
+		for i := range props.Friends {
+			props.Friends[i].Online = snapshot.Online(props.Friends[i].Username)
+		}
+		props.Rooms = snapshot.OpenRooms(user.Username)
+	}
+
+	friends_views.Panel(props).Render(r.Context(), w)
+}
@@ -5,18 +5,19 @@ import (
 	"net/http"
 
 	"github.com/FredericoBento/HandGame/internal/logger"
-	"github.com/FredericoBento/HandGame/internal/services"
+	"github.com/FredericoBento/HandGame/internal/services/handgame"
 	"github.com/FredericoBento/HandGame/internal/views"
 	"github.com/FredericoBento/HandGame/internal/views/components"
+	"github.com/FredericoBento/HandGame/internal/views/handgame_views"
 	"github.com/a-h/templ"
 )
 
 type HandGameHandler struct {
-	handGameService *services.HandGameService
+	handGameService *handgame.HandGameService
 	log             *slog.Logger
 }
 
-func NewHandGameHandler(handGameService *services.HandGameService) *HandGameHandler {
+func NewHandGameHandler(handGameService *handgame.HandGameService) *HandGameHandler {
 	lo, err := logger.NewHandlerLogger("HandgameHandler", "", false)
 	if err != nil {
 		lo = slog.New(slog.Default().Handler())
@@ -30,17 +31,54 @@ func NewHandGameHandler(handGameService *services.HandGameService) *HandGameHand
 }
 
 func (h *HandGameHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/handgame/home":
+		h.home(w, r)
+	case "/handgame/rooms":
+		h.rooms(w, r)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("404 - Not Found"))
+	}
+}
+
+func (h *HandGameHandler) home(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
 		h.Get(w, r)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *HandGameHandler) rooms(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetRooms(w, r)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
 	}
 }
 
 func (h *HandGameHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if !IsLogged(r) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	h.View(w, r, HandGameViewProps{
+		title:   "HandGame",
+		content: handgame_views.Home(h.handGameService.ListOpenRooms("")),
+	})
+}
 
-	// h.View(w, r, HandGameViewProps{
-	// content: handgame_views.Home(),
-	// })
+// GetRooms only renders the rooms table, it is polled and searched through htmx
+func (h *HandGameHandler) GetRooms(w http.ResponseWriter, r *http.Request) {
+	if !IsLogged(r) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	rooms := h.handGameService.ListOpenRooms(r.URL.Query().Get("search"))
+	handgame_views.RoomsTable(rooms).Render(r.Context(), w)
 }
 
 type HandGameViewProps struct {
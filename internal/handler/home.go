@@ -5,6 +5,7 @@ import (
 
 	"github.com/FredericoBento/HandGame/internal/models"
 	"github.com/FredericoBento/HandGame/internal/services"
+	"github.com/FredericoBento/HandGame/internal/services/presence"
 	"github.com/FredericoBento/HandGame/internal/views"
 	"github.com/FredericoBento/HandGame/internal/views/home_views"
 
@@ -14,13 +15,15 @@ import (
 type HomeHandler struct {
 	games       []services.GameService
 	authService *services.AuthService
+	presence    *presence.Service
 }
 
-func NewHomeHandler(gameServices []services.GameService, authService *services.AuthService) *HomeHandler {
+func NewHomeHandler(gameServices []services.GameService, authService *services.AuthService, presenceService *presence.Service) *HomeHandler {
 
 	return &HomeHandler{
 		games:       gameServices,
 		authService: authService,
+		presence:    presenceService,
 	}
 
 	// h.navbar = h.getNavbar(false, false)
@@ -56,8 +59,13 @@ type HomeViewProps struct {
 
 func (h *HomeHandler) GetHome(w http.ResponseWriter, r *http.Request) {
 	// apps := h.appManager.GetAppsSortedAlphabetic()
+	var lobby *presence.Snapshot
+	if h.presence != nil && IsLogged(r) {
+		snapshot := h.presence.Snapshot()
+		lobby = &snapshot
+	}
 	props := HomeViewProps{
-		content: home_views.Home(h.games),
+		content: home_views.Home(h.games, lobby),
 	}
 	h.View(w, r, props)
 }
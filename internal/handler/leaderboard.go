@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/FredericoBento/HandGame/internal/database/repository"
+	"github.com/FredericoBento/HandGame/internal/logger"
+	"github.com/FredericoBento/HandGame/internal/models"
+	"github.com/FredericoBento/HandGame/internal/views"
+	"github.com/FredericoBento/HandGame/internal/views/home_views"
+	"github.com/FredericoBento/HandGame/internal/views/leaderboard_views"
+	"github.com/a-h/templ"
+)
+
+const (
+	leaderboardPageSize = 20
+)
+
+var (
+	leaderboardTabs = []leaderboard_views.Tab{
+		{Name: "All Games", Route: "/leaderboard"},
+		{Name: "TicTacToe", Route: "/tictactoe/leaderboard"},
+		{Name: "Pong", Route: "/pong/leaderboard"},
+		{Name: "Connect Four", Route: "/connectfour/leaderboard"},
+	}
+)
+
+type LeaderboardHandler struct {
+	matchRepository repository.MatchRepository
+	log             *slog.Logger
+}
+
+type LeaderboardViewProps struct {
+	title   string
+	content templ.Component
+}
+
+func NewLeaderboardHandler(matchRepository repository.MatchRepository) *LeaderboardHandler {
+	lo, err := logger.NewHandlerLogger("LeaderboardHandler", "", false)
+	if err != nil {
+		lo = slog.New(slog.Default().Handler())
+		lo.Error(err.Error())
+	}
+	return &LeaderboardHandler{
+		matchRepository: matchRepository,
+		log:             lo,
+	}
+}
+
+func (h *LeaderboardHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/leaderboard":
+		h.leaderboard(w, r, "", "Leaderboard")
+	case "/tictactoe/leaderboard":
+		h.leaderboard(w, r, models.GameTicTacToe, "TicTacToe Leaderboard")
+	case "/pong/leaderboard":
+		h.leaderboard(w, r, models.GamePong, "Pong Leaderboard")
+	case "/connectfour/leaderboard":
+		h.leaderboard(w, r, models.GameConnectFour, "Connect Four Leaderboard")
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("404 - Not Found"))
+	}
+}
+
+func (h *LeaderboardHandler) leaderboard(w http.ResponseWriter, r *http.Request, game string, title string) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetLeaderboard(w, r, game, title)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// GetLeaderboard renders the page of the leaderboard asked in the page query,
+// htmx pagination only gets the table back
+func (h *LeaderboardHandler) GetLeaderboard(w http.ResponseWriter, r *http.Request, game string, title string) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	count, err := h.matchRepository.CountPlayers(r.Context(), game)
+	if err != nil {
+		h.log.Error(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	totalPages := (count + leaderboardPageSize - 1) / leaderboardPageSize
+	if totalPages > 0 && page > totalPages {
+		page = totalPages
+	}
+
+	entries, err := h.matchRepository.GetLeaderboard(r.Context(), game, leaderboardPageSize, (page-1)*leaderboardPageSize)
+	if err != nil {
+		h.log.Error(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if IsHTMX(r) && r.Header.Get("Hx-Target") == "leaderboard_table" {
+		leaderboard_views.LeaderboardTable(r.URL.Path, game != "", entries, page, totalPages).Render(r.Context(), w)
+		return
+	}
+
+	h.View(w, r, LeaderboardViewProps{
+		title:   title,
+		content: leaderboard_views.Leaderboard(title, r.URL.Path, leaderboardTabs, game != "", entries, page, totalPages),
+	})
+}
+
+func (h *LeaderboardHandler) View(w http.ResponseWriter, r *http.Request, props LeaderboardViewProps) {
+	if IsHTMX(r) {
+		props.content.Render(r.Context(), w)
+	} else {
+		var navbar templ.Component
+		if IsLogged(r) {
+			if IsAdmin(r) {
+				navbar = home_views.AdminNavbar()
+			} else {
+				navbar = home_views.LoggedNavbar()
+			}
+		} else {
+			navbar = home_views.DefaultNavbar()
+		}
+		views.Page(props.title, navbar, props.content).Render(r.Context(), w)
+	}
+}
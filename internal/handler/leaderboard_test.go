@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/FredericoBento/HandGame/internal/mock"
+	"github.com/FredericoBento/HandGame/internal/models"
+)
+
+func TestGetLeaderboard(t *testing.T) {
+	repo := &mock.MockMatchRepository{
+		CountPlayersResult: 45,
+		GetLeaderboardResult: []models.LeaderboardEntry{
+			{Rank: 21, Username: "player21", Wins: 3, Losses: 1, Played: 4},
+		},
+	}
+	h := NewLeaderboardHandler(repo)
+
+	t.Run("FullPage", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/pong/leaderboard?page=2", nil)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200 got: %d", rec.Code)
+		}
+		body := rec.Body.String()
+		if !strings.Contains(body, "Pong Leaderboard") || !strings.Contains(body, "player21") {
+			t.Errorf("expected the pong leaderboard with its entries")
+		}
+		if !strings.Contains(body, "75.0%") {
+			t.Errorf("expected the win rate to be rendered")
+		}
+	})
+
+	t.Run("TableOnly", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/leaderboard?page=3", nil)
+		req.Header.Set("Hx-Request", "true")
+		req.Header.Set("Hx-Target", "leaderboard_table")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		body := rec.Body.String()
+		if strings.Contains(body, "<h1") {
+			t.Errorf("expected only the table to be rendered")
+		}
+		if !strings.Contains(body, `hx-get="/leaderboard?page=2"`) {
+			t.Errorf("expected a link to the previous page")
+		}
+		if strings.Contains(body, `hx-get="/leaderboard?page=4"`) {
+			t.Errorf("expected no page after the last one")
+		}
+	})
+
+	t.Run("UnknownRoute", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/handgame/leaderboard", nil)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404 got: %d", rec.Code)
+		}
+	})
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+
+	"github.com/FredericoBento/HandGame/internal/database/repository"
+	"github.com/FredericoBento/HandGame/internal/logger"
+	"github.com/FredericoBento/HandGame/internal/models"
+	"github.com/FredericoBento/HandGame/internal/services"
+	"github.com/FredericoBento/HandGame/internal/views"
+	"github.com/FredericoBento/HandGame/internal/views/home_views"
+	"github.com/FredericoBento/HandGame/internal/views/profile_views"
+	"github.com/a-h/templ"
+)
+
+const (
+	profileRecentMatches = 10
+)
+
+var (
+	profileGames = []struct {
+		Name string
+		Game string
+	}{
+		{Name: "TicTacToe", Game: models.GameTicTacToe},
+		{Name: "Pong", Game: models.GamePong},
+		{Name: "Connect Four", Game: models.GameConnectFour},
+	}
+)
+
+type ProfileHandler struct {
+	userService     *services.UserService
+	ratingService   *services.RatingService
+	matchRepository repository.MatchRepository
+	log             *slog.Logger
+}
+
+type ProfileViewProps struct {
+	title   string
+	content templ.Component
+}
+
+func NewProfileHandler(userService *services.UserService, ratingService *services.RatingService, matchRepository repository.MatchRepository) *ProfileHandler {
+	lo, err := logger.NewHandlerLogger("ProfileHandler", "", false)
+	if err != nil {
+		lo = slog.New(slog.Default().Handler())
+		lo.Error(err.Error())
+	}
+	return &ProfileHandler{
+		userService:     userService,
+		ratingService:   ratingService,
+		matchRepository: matchRepository,
+		log:             lo,
+	}
+}
+
+func (h *ProfileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/profile":
+		h.profile(w, r)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("404 - Not Found"))
+	}
+}
+
+func (h *ProfileHandler) profile(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetProfile(w, r)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// GetProfile shows the ratings and records of the user in the username query,
+// or of the logged user when there is none
+func (h *ProfileHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		user, isLogged := GetLoggedUser(r)
+		if !isLogged {
+			Redirect(w, r, "/sign-in")
+			return
+		}
+		username = user.Username
+	} else {
+		exists, err := h.userService.UserExists(r.Context(), username)
+		if err != nil {
+			h.log.Error(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("404 - Not Found"))
+			return
+		}
+	}
+
+	standings := make([]profile_views.GameStanding, 0, len(profileGames))
+	for _, g := range profileGames {
+		rating, err := h.ratingService.GetRating(r.Context(), username, g.Game)
+		if err != nil {
+			h.log.Error(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		record, err := h.matchRepository.GetRecord(r.Context(), username, g.Game)
+		if err != nil {
+			h.log.Error(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		standings = append(standings, profile_views.GameStanding{
+			Name:   g.Name,
+			Rating: int(math.Round(rating.Rating)),
+			Record: *record,
+		})
+	}
+
+	matches, err := h.matchRepository.GetByUsername(r.Context(), username, profileRecentMatches)
+	if err != nil {
+		h.log.Error(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	h.View(w, r, ProfileViewProps{
+		title:   username,
+		content: profile_views.Profile(username, standings, matches),
+	})
+}
+
+func (h *ProfileHandler) View(w http.ResponseWriter, r *http.Request, props ProfileViewProps) {
+	if IsHTMX(r) {
+		props.content.Render(r.Context(), w)
+	} else {
+		var navbar templ.Component
+		if IsLogged(r) {
+			if IsAdmin(r) {
+				navbar = home_views.AdminNavbar()
+			} else {
+				navbar = home_views.LoggedNavbar()
+			}
+		} else {
+			navbar = home_views.DefaultNavbar()
+		}
+		views.Page(props.title, navbar, props.content).Render(r.Context(), w)
+	}
+}
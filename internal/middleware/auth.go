@@ -23,7 +23,7 @@ type AuthService interface {
 	GetToken(r *http.Request) (string, error)
 	GetCookieName() string
 	ValidateSession(ctx context.Context, token string) (*models.User, error)
-	IsAdmin(username string) bool
+	IsAdmin(user *models.User) bool
 }
 
 func SetAuthService(service AuthService) {
@@ -55,12 +55,9 @@ func RequiredAdmin(next http.Handler) http.Handler {
 			return
 		}
 
-		isAdmin := r.Context().Value(IsAdminKey)
-		if isAdmin != nil {
-			if isAdmin.(bool) {
-				next.ServeHTTP(w, r)
-				return
-			}
+		if authService.IsAdmin(user.(*models.User)) {
+			next.ServeHTTP(w, r)
+			return
 		}
 
 		http.Error(w, "Forbidden, not an admin", http.StatusForbidden)
@@ -120,7 +117,7 @@ func addAdminLevelToContext(ctx context.Context) context.Context {
 	}
 	user := ctx.Value(LoggedUserKey)
 	if user != nil {
-		if authService.IsAdmin(user.(*models.User).Username) {
+		if authService.IsAdmin(user.(*models.User)) {
 			return context.WithValue(ctx, IsAdminKey, true)
 		}
 	}
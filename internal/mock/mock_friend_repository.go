@@ -0,0 +1,84 @@
+package mock
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/FredericoBento/HandGame/internal/models"
+)
+
+// MockFriendRepository keeps the friendships in memory
+type MockFriendRepository struct {
+	mu          sync.Mutex
+	Friendships []models.Friendship
+
+	CreateError error
+}
+
+func NewMockFriendRepository() *MockFriendRepository {
+	return &MockFriendRepository{}
+}
+
+func (m *MockFriendRepository) Create(ctx context.Context, friendship *models.Friendship) error {
+	if m.CreateError != nil {
+		return m.CreateError
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Friendships = append(m.Friendships, *friendship)
+	return nil
+}
+
+func (m *MockFriendRepository) Get(ctx context.Context, username string, other string) (*models.Friendship, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if i := m.find(username, other); i >= 0 {
+		friendship := m.Friendships[i]
+		return &friendship, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *MockFriendRepository) GetByUsername(ctx context.Context, username string) ([]models.Friendship, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	friendships := []models.Friendship{}
+	for _, friendship := range m.Friendships {
+		if friendship.Requester == username || friendship.Addressee == username {
+			friendships = append(friendships, friendship)
+		}
+	}
+	return friendships, nil
+}
+
+func (m *MockFriendRepository) Accept(ctx context.Context, requester string, addressee string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, friendship := range m.Friendships {
+		if friendship.Requester == requester && friendship.Addressee == addressee {
+			m.Friendships[i].Status = models.FriendshipAccepted
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (m *MockFriendRepository) Delete(ctx context.Context, username string, other string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if i := m.find(username, other); i >= 0 {
+		m.Friendships = append(m.Friendships[:i], m.Friendships[i+1:]...)
+	}
+	return nil
+}
+
+func (m *MockFriendRepository) find(username string, other string) int {
+	for i, friendship := range m.Friendships {
+		if friendship.Requester == username && friendship.Addressee == other ||
+			friendship.Requester == other && friendship.Addressee == username {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,60 @@
+package mock
+
+import (
+	"context"
+	"sync"
+
+	"github.com/FredericoBento/HandGame/internal/models"
+)
+
+type MockMatchRepository struct {
+	mu      sync.Mutex
+	Created []models.Match
+
+	CreateError error
+
+	GetByUsernameResult []models.Match
+	GetByUsernameError  error
+
+	GetRecordResult *models.MatchRecord
+	GetRecordError  error
+
+	GetLeaderboardResult []models.LeaderboardEntry
+	GetLeaderboardError  error
+
+	CountPlayersResult int
+	CountPlayersError  error
+}
+
+func (m *MockMatchRepository) Create(ctx context.Context, match *models.Match) error {
+	if m.CreateError != nil {
+		return m.CreateError
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Created = append(m.Created, *match)
+	return nil
+}
+
+func (m *MockMatchRepository) GetByUsername(ctx context.Context, username string, limit int) ([]models.Match, error) {
+	return m.GetByUsernameResult, m.GetByUsernameError
+}
+
+func (m *MockMatchRepository) GetRecord(ctx context.Context, username string, game string) (*models.MatchRecord, error) {
+	return m.GetRecordResult, m.GetRecordError
+}
+
+func (m *MockMatchRepository) GetLeaderboard(ctx context.Context, game string, limit int, offset int) ([]models.LeaderboardEntry, error) {
+	return m.GetLeaderboardResult, m.GetLeaderboardError
+}
+
+func (m *MockMatchRepository) CountPlayers(ctx context.Context, game string) (int, error) {
+	return m.CountPlayersResult, m.CountPlayersError
+}
+
+// GetCreated returns a copy of the matches stored so far
+func (m *MockMatchRepository) GetCreated() []models.Match {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]models.Match(nil), m.Created...)
+}
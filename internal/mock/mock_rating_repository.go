@@ -0,0 +1,64 @@
+package mock
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/FredericoBento/HandGame/internal/models"
+)
+
+// MockRatingRepository keeps the ratings in memory
+type MockRatingRepository struct {
+	mu      sync.Mutex
+	Ratings map[string]models.Rating
+
+	GetError  error
+	SaveError error
+}
+
+func NewMockRatingRepository() *MockRatingRepository {
+	return &MockRatingRepository{
+		Ratings: make(map[string]models.Rating),
+	}
+}
+
+func (m *MockRatingRepository) Get(ctx context.Context, username string, game string) (*models.Rating, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rating, ok := m.Ratings[game+"/"+username]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &rating, nil
+}
+
+func (m *MockRatingRepository) GetByUsername(ctx context.Context, username string) ([]models.Rating, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var ratings []models.Rating
+	for _, rating := range m.Ratings {
+		if rating.Username == username {
+			ratings = append(ratings, rating)
+		}
+	}
+	return ratings, nil
+}
+
+func (m *MockRatingRepository) Save(ctx context.Context, ratings ...*models.Rating) error {
+	if m.SaveError != nil {
+		return m.SaveError
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, rating := range ratings {
+		m.Ratings[rating.Game+"/"+rating.Username] = *rating
+	}
+	return nil
+}
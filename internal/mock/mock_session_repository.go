@@ -0,0 +1,81 @@
+package mock
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/models"
+)
+
+// MockSessionRepository keeps the sessions in memory
+type MockSessionRepository struct {
+	mu       sync.Mutex
+	Sessions map[string]models.Session
+
+	GetError    error
+	CreateError error
+}
+
+func NewMockSessionRepository() *MockSessionRepository {
+	return &MockSessionRepository{
+		Sessions: make(map[string]models.Session),
+	}
+}
+
+func (m *MockSessionRepository) Create(ctx context.Context, session *models.Session) error {
+	if m.CreateError != nil {
+		return m.CreateError
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Sessions[session.Token] = *session
+	return nil
+}
+
+func (m *MockSessionRepository) Get(ctx context.Context, token string) (*models.Session, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.Sessions[token]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &session, nil
+}
+
+func (m *MockSessionRepository) Touch(ctx context.Context, token string, lastSeenAt time.Time, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.Sessions[token]
+	if !ok {
+		return nil
+	}
+	session.LastSeenAt = lastSeenAt
+	session.ExpiresAt = expiresAt
+	m.Sessions[token] = session
+	return nil
+}
+
+func (m *MockSessionRepository) Delete(ctx context.Context, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.Sessions, token)
+	return nil
+}
+
+func (m *MockSessionRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var deleted int64
+	for token, session := range m.Sessions {
+		if session.IsExpired(now) {
+			delete(m.Sessions, token)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
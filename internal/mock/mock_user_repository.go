@@ -12,6 +12,8 @@ type MockUserRepository struct {
 
 	CreateError error
 
+	UpdateRoleError error
+
 	GetAllResult []models.User
 	GetAllError  error
 }
@@ -30,3 +32,13 @@ func (m *MockUserRepository) Create(ctx context.Context, user *models.User) erro
 func (m *MockUserRepository) GetAll(ctx context.Context) ([]models.User, error) {
 	return m.GetAllResult, m.GetAllError
 }
+
+func (m *MockUserRepository) UpdateRole(ctx context.Context, username string, role models.Role) error {
+	if m.UpdateRoleError != nil {
+		return m.UpdateRoleError
+	}
+	if m.GetByUsernameResult != nil && m.GetByUsernameResult.Username == username {
+		m.GetByUsernameResult.Role = role
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+type FriendshipStatus string
+
+const (
+	FriendshipPending  FriendshipStatus = "pending"
+	FriendshipAccepted FriendshipStatus = "accepted"
+)
+
+// Friendship is stored once for both users, Requester is who asked for it
+type Friendship struct {
+	Requester string
+	Addressee string
+	Status    FriendshipStatus
+	CreatedAt time.Time
+}
+
+// Other is the user on the other side of the friendship
+func (f *Friendship) Other(username string) string {
+	if f.Requester == username {
+		return f.Addressee
+	}
+	return f.Requester
+}
+
+// Friend is a friendship as seen by one of the two users
+type Friend struct {
+	Username string
+	Status   FriendshipStatus
+	// Incoming is a pending request that the user can accept
+	Incoming bool
+	Online   bool
+}
@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+type MatchResult string
+
+const (
+	GameTicTacToe   = "tictactoe"
+	GamePong        = "pong"
+	GameHandGame    = "handgame"
+	GameConnectFour = "connectfour"
+
+	MatchResultWin  MatchResult = "win"
+	MatchResultLoss MatchResult = "loss"
+	MatchResultTie  MatchResult = "tie"
+)
+
+type Match struct {
+	ID           int
+	Game         string
+	RoomCode     string
+	FinishedAt   time.Time
+	Participants []MatchParticipant
+}
+
+type MatchParticipant struct {
+	MatchID  int
+	Username string
+	Score    int
+	Result   MatchResult
+}
+
+// MatchRecord is the summary of every match a user played in a game
+type MatchRecord struct {
+	Game   string
+	Wins   int
+	Losses int
+	Ties   int
+}
+
+func (r *MatchRecord) Played() int {
+	return r.Wins + r.Losses + r.Ties
+}
+
+// LeaderboardEntry is the standing of a user across the matches of a game
+type LeaderboardEntry struct {
+	Rank     int
+	Username string
+	Wins     int
+	Losses   int
+	Ties     int
+	Played   int
+	// Rating in the game of the leaderboard, 0 when ranking across games
+	Rating int
+}
+
+// WinRate is the percentage of played matches the user won
+func (e *LeaderboardEntry) WinRate() float64 {
+	if e.Played == 0 {
+		return 0
+	}
+	return float64(e.Wins) * 100 / float64(e.Played)
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+const (
+	DefaultRating = 1200
+)
+
+// Rating is the Elo rating of a user in a game
+type Rating struct {
+	Username  string
+	Game      string
+	Rating    float64
+	Games     int
+	UpdatedAt time.Time
+}
+
+func NewRating(username string, game string) *Rating {
+	return &Rating{
+		Username: username,
+		Game:     game,
+		Rating:   DefaultRating,
+	}
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Session is a logged in user, ExpiresAt moves forward while it is being used
+type Session struct {
+	Token      string
+	Username   string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	ExpiresAt  time.Time
+}
+
+func (s *Session) IsExpired(now time.Time) bool {
+	return s.ExpiresAt.Before(now)
+}
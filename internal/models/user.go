@@ -1,8 +1,57 @@
 package models
 
+type Role string
+
+const (
+	RolePlayer    Role = "player"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+var (
+	// roles from the least to the most privileged
+	roles = []Role{RolePlayer, RoleModerator, RoleAdmin}
+)
+
 type User struct {
 	ID           int
 	Username     string
 	Password     string
 	PasswordSalt string
+	Role         Role
+}
+
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+func (r Role) IsValid() bool {
+	return r.rank() >= 0
+}
+
+// Promoted is the role right above r, the admin role stays the same
+func (r Role) Promoted() Role {
+	rank := r.rank()
+	if rank < 0 || rank == len(roles)-1 {
+		return r
+	}
+	return roles[rank+1]
+}
+
+// Demoted is the role right below r, the player role stays the same
+func (r Role) Demoted() Role {
+	rank := r.rank()
+	if rank <= 0 {
+		return r
+	}
+	return roles[rank-1]
+}
+
+func (r Role) rank() int {
+	for i, role := range roles {
+		if role == r {
+			return i
+		}
+	}
+	return -1
 }
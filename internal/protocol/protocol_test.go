@@ -0,0 +1,53 @@
+package protocol
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/FredericoBento/HandGame/internal/ws"
+)
+
+func TestSocketsAreValid(t *testing.T) {
+	for _, socket := range Sockets() {
+		t.Run(socket.Package, func(t *testing.T) {
+			if err := socket.Validate(); err != nil {
+				t.Errorf("expected the socket to be valid but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateRejectsClashingEvents(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []Event
+	}{
+		{"SameType", []Event{{Name: "Play", Type: 3}, {Name: "Move", Type: 3}}},
+		{"SameName", []Event{{Name: "Play", Type: 3}, {Name: "Play", Type: 4}}},
+		{"SharedName", []Event{{Name: "Ping", Type: 3}}},
+		{"SharedType", []Event{{Name: "Play", Type: ws.EventTypeFirstShared}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			socket := Socket{Package: "test", Path: "/ws/test", Events: tt.events}
+			if err := socket.Validate(); err == nil {
+				t.Errorf("expected the events to be rejected")
+			}
+		})
+	}
+}
+
+func TestTypeScriptIsUpToDate(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := GenerateTypeScript(buf); err != nil {
+		t.Fatal(err)
+	}
+	generated, err := os.ReadFile("../../assets/scripts/protocol.ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), generated) {
+		t.Errorf("expected assets/scripts/protocol.ts to match the schema, run go generate ./internal/protocol")
+	}
+}
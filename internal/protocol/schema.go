@@ -0,0 +1,210 @@
+// Package protocol is the schema of the events sent over the websockets, the
+// event types of every socket along with the data they carry. The
+// TypeScript clients get their constants and types from it, see
+// GenerateTypeScript
+package protocol
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/FredericoBento/HandGame/internal/services/chat"
+	"github.com/FredericoBento/HandGame/internal/services/connectfour"
+	"github.com/FredericoBento/HandGame/internal/services/gameroom"
+	"github.com/FredericoBento/HandGame/internal/services/handgame"
+	"github.com/FredericoBento/HandGame/internal/services/pong"
+	"github.com/FredericoBento/HandGame/internal/services/presence"
+	"github.com/FredericoBento/HandGame/internal/services/tictactoe"
+	"github.com/FredericoBento/HandGame/internal/ws"
+)
+
+//go:generate go run ../../cmd/protocolgen -o ../../assets/scripts/protocol.ts
+
+// none is the data of an event that carries nothing
+type none struct{}
+
+// None is given as the data of an event sent without any
+var None = none{}
+
+// Event is an event of a socket. Client is the data clients send with it and
+// Server the data they get with it, either is nil if the event does not go
+// that way. Name is the name of the Go constant without its EventType prefix
+type Event struct {
+	Name   string
+	Type   ws.EventType
+	Client any
+	Server any
+}
+
+// Socket is the events of one websocket, Package is the Go package they are
+// declared in. Every socket also carries the Shared events
+type Socket struct {
+	Package string
+	Path    string
+	Events  []Event
+}
+
+// Shared are the events of every socket, they are numbered from
+// ws.EventTypeFirstShared so they never take the number of a game event
+var Shared = []Event{
+	{Name: "UserDisconnected", Type: ws.EventTypeUserDisconnected, Server: ws.EventDataUser{}},
+	{Name: "Ping", Type: ws.EventTypePing, Client: ws.EventPingPongData{}},
+	{Name: "Pong", Type: ws.EventTypePong, Server: ws.EventPingPongData{}},
+}
+
+// Sockets returns the schema of every socket
+func Sockets() []Socket {
+	return []Socket{
+		{
+			Package: "tictactoe",
+			Path:    "/ws/tictactoe",
+			Events: []Event{
+				{Name: "CreateGame", Type: tictactoe.EventTypeCreateGame, Client: tictactoe.EventDataCreateGame{}},
+				{Name: "JoinGame", Type: tictactoe.EventTypeJoinGame, Client: gameroom.EventDataJoin{}},
+				{Name: "JoinedGame", Type: tictactoe.EventTypeJoinedGame, Server: tictactoe.GameState{}},
+				{Name: "OtherPlayerJoined", Type: tictactoe.EventTypeOtherPlayerJoined, Server: gameroom.DuelPlayer{}},
+				{Name: "MakePlay", Type: tictactoe.EventTypeMakePlay, Client: tictactoe.EventDataPlay{}},
+				{Name: "PlayerSendMessage", Type: tictactoe.EventTypePlayerSendMessage, Client: gameroom.EventDataChat{}, Server: chat.Message{}},
+				{Name: "PlayerDisconnected", Type: tictactoe.EventTypePlayerDisconnected, Server: gameroom.DuelPlayer{}},
+				{Name: "PlayerReconnected", Type: tictactoe.EventTypePlayerReconnected, Server: gameroom.DuelPlayer{}},
+				{Name: "BoardCellUpdate", Type: tictactoe.EventTypeBoardCellUpdate, Server: tictactoe.EventDataCellUpdate{}},
+				{Name: "StateUpdate", Type: tictactoe.EventTypeStateUpdate, Server: tictactoe.GameState{}},
+				{Name: "Tie", Type: tictactoe.EventTypeTie, Server: gameroom.EventDataDuelTie{}},
+				{Name: "Victory", Type: tictactoe.EventTypeVictory, Server: gameroom.EventDataDuelFinish{}},
+				{Name: "Defeat", Type: tictactoe.EventTypeDefeat, Server: gameroom.EventDataDuelFinish{}},
+				{Name: "FindMatch", Type: tictactoe.EventTypeFindMatch, Client: None, Server: gameroom.EventDataQueue{}},
+				{Name: "CancelFindMatch", Type: tictactoe.EventTypeCancelFindMatch, Client: None, Server: gameroom.EventDataQueue{}},
+				{Name: "SpectateGame", Type: tictactoe.EventTypeSpectateGame, Client: gameroom.EventDataCode{}, Server: gameroom.EventDataSpectate{}},
+				{Name: "SpectatorsUpdate", Type: tictactoe.EventTypeSpectatorsUpdate, Server: gameroom.EventDataSpectators{}},
+				{Name: "ReconnectCountdown", Type: tictactoe.EventTypeReconnectCountdown, Server: gameroom.EventDataCountdown{}},
+				{Name: "Forfeit", Type: tictactoe.EventTypeForfeit, Server: gameroom.EventDataDuelForfeit{}},
+				{Name: "ChatHistory", Type: tictactoe.EventTypeChatHistory, Server: gameroom.EventDataChatHistory{}},
+			},
+		},
+		{
+			Package: "connectfour",
+			Path:    "/ws/connectfour",
+			Events: []Event{
+				{Name: "CreateGame", Type: connectfour.EventTypeCreateGame, Client: gameroom.EventDataCreateRoom{}},
+				{Name: "JoinGame", Type: connectfour.EventTypeJoinGame, Client: gameroom.EventDataJoin{}},
+				{Name: "JoinedGame", Type: connectfour.EventTypeJoinedGame, Server: connectfour.GameState{}},
+				{Name: "OtherPlayerJoined", Type: connectfour.EventTypeOtherPlayerJoined, Server: gameroom.DuelPlayer{}},
+				{Name: "DropDisc", Type: connectfour.EventTypeDropDisc, Client: connectfour.EventDataDrop{}},
+				{Name: "PlayerSendMessage", Type: connectfour.EventTypePlayerSendMessage, Client: gameroom.EventDataChat{}, Server: chat.Message{}},
+				{Name: "PlayerDisconnected", Type: connectfour.EventTypePlayerDisconnected, Server: gameroom.DuelPlayer{}},
+				{Name: "PlayerReconnected", Type: connectfour.EventTypePlayerReconnected, Server: gameroom.DuelPlayer{}},
+				{Name: "DiscDropped", Type: connectfour.EventTypeDiscDropped, Server: connectfour.EventDataDiscDropped{}},
+				{Name: "StateUpdate", Type: connectfour.EventTypeStateUpdate, Server: connectfour.GameState{}},
+				{Name: "Tie", Type: connectfour.EventTypeTie, Server: gameroom.EventDataDuelTie{}},
+				{Name: "Victory", Type: connectfour.EventTypeVictory, Server: gameroom.EventDataDuelFinish{}},
+				{Name: "Defeat", Type: connectfour.EventTypeDefeat, Server: gameroom.EventDataDuelFinish{}},
+				{Name: "FindMatch", Type: connectfour.EventTypeFindMatch, Client: None, Server: gameroom.EventDataQueue{}},
+				{Name: "CancelFindMatch", Type: connectfour.EventTypeCancelFindMatch, Client: None, Server: gameroom.EventDataQueue{}},
+				{Name: "SpectateGame", Type: connectfour.EventTypeSpectateGame, Client: gameroom.EventDataCode{}, Server: gameroom.EventDataSpectate{}},
+				{Name: "SpectatorsUpdate", Type: connectfour.EventTypeSpectatorsUpdate, Server: gameroom.EventDataSpectators{}},
+				{Name: "ReconnectCountdown", Type: connectfour.EventTypeReconnectCountdown, Server: gameroom.EventDataCountdown{}},
+				{Name: "Forfeit", Type: connectfour.EventTypeForfeit, Server: gameroom.EventDataDuelForfeit{}},
+				{Name: "ChatHistory", Type: connectfour.EventTypeChatHistory, Server: gameroom.EventDataChatHistory{}},
+			},
+		},
+		{
+			Package: "handgame",
+			Path:    "/ws/handgame",
+			Events: []Event{
+				{Name: "CreateGame", Type: handgame.EventTypeCreateGame, Client: handgame.EventDataCreateGame{}},
+				{Name: "JoinGame", Type: handgame.EventTypeJoinGame, Client: gameroom.EventDataJoin{}},
+				{Name: "JoinedGame", Type: handgame.EventTypeJoinedGame, Server: handgame.EventDataState{}},
+				{Name: "OtherPlayerJoined", Type: handgame.EventTypeOtherPlayerJoined, Server: handgame.Player{}},
+				{Name: "CommitHand", Type: handgame.EventTypeCommitHand, Client: handgame.EventDataHand{}},
+				{Name: "HandCommitted", Type: handgame.EventTypeHandCommitted, Server: handgame.EventDataHand{}},
+				{Name: "OpponentCommitted", Type: handgame.EventTypeOpponentCommitted, Server: handgame.Player{}},
+				{Name: "RoundResult", Type: handgame.EventTypeRoundResult, Server: handgame.EventDataRoundResult{}},
+				{Name: "MatchFinished", Type: handgame.EventTypeMatchFinished, Server: handgame.EventDataMatchFinished{}},
+				{Name: "RequestRematch", Type: handgame.EventTypeRequestRematch, Client: None},
+				{Name: "RematchRequested", Type: handgame.EventTypeRematchRequested, Server: handgame.Player{}},
+				{Name: "PlayerDisconnected", Type: handgame.EventTypePlayerDisconnected, Server: handgame.Player{}},
+				{Name: "PlayerReconnected", Type: handgame.EventTypePlayerReconnected, Server: handgame.Player{}},
+				{Name: "StateUpdate", Type: handgame.EventTypeStateUpdate, Server: handgame.EventDataState{}},
+				{Name: "FindMatch", Type: handgame.EventTypeFindMatch, Client: None, Server: gameroom.EventDataQueue{}},
+				{Name: "CancelFindMatch", Type: handgame.EventTypeCancelFindMatch, Client: None, Server: gameroom.EventDataQueue{}},
+				{Name: "SpectateGame", Type: handgame.EventTypeSpectateGame, Client: gameroom.EventDataCode{}, Server: gameroom.EventDataSpectate{}},
+				{Name: "SpectatorsUpdate", Type: handgame.EventTypeSpectatorsUpdate, Server: gameroom.EventDataSpectators{}},
+				{Name: "ReconnectCountdown", Type: handgame.EventTypeReconnectCountdown, Server: gameroom.EventDataCountdown{}},
+				{Name: "Forfeit", Type: handgame.EventTypeForfeit, Server: handgame.EventDataForfeit{}},
+				{Name: "ChatMessage", Type: handgame.EventTypeChatMessage, Client: gameroom.EventDataChat{}, Server: chat.Message{}},
+				{Name: "ChatHistory", Type: handgame.EventTypeChatHistory, Server: gameroom.EventDataChatHistory{}},
+			},
+		},
+		{
+			Package: "pong",
+			Path:    "/ws/pong",
+			Events: []Event{
+				{Name: "Message", Type: pong.EventTypeMessage, Client: gameroom.EventDataChat{}, Server: chat.Message{}},
+				{Name: "CreateRoom", Type: pong.EventTypeCreateRoom, Client: pong.EventDataCreateRoom{}},
+				{Name: "CreatedRoom", Type: pong.EventTypeCreatedRoom, Server: pong.EventDataCreatedRoom{}},
+				{Name: "JoinRoom", Type: pong.EventTypeJoinRoom, Client: gameroom.EventDataJoin{}},
+				{Name: "JoinedRoom", Type: pong.EventTypeJoinedRoom, Server: pong.EventDataJoinedRoom{}},
+				{Name: "PlayerJoinedRoom", Type: pong.EventTypePlayerJoinedRoom, Server: pong.EventDataPlayerJoined{}},
+				{Name: "FindMatch", Type: pong.EventTypeFindMatch, Client: None, Server: gameroom.EventDataQueue{}},
+				{Name: "CancelFindMatch", Type: pong.EventTypeCancelFindMatch, Client: None, Server: gameroom.EventDataQueue{}},
+				{Name: "SpectateRoom", Type: pong.EventTypeSpectateRoom, Client: gameroom.EventDataCode{}, Server: gameroom.EventDataSpectate{}},
+				{Name: "SpectatorsUpdate", Type: pong.EventTypeSpectatorsUpdate, Server: gameroom.EventDataSpectators{}},
+				{Name: "ReconnectCountdown", Type: pong.EventTypeReconnectCountdown, Server: gameroom.EventDataCountdown{}},
+				{Name: "PlayerReconnected", Type: pong.EventTypePlayerReconnected, Server: ws.EventDataUser{}},
+				{Name: "ChatHistory", Type: pong.EventTypeChatHistory, Server: gameroom.EventDataChatHistory{}},
+				{Name: "PaddleMoved", Type: pong.EventTypePaddleMoved, Client: pong.EventPaddleMoveData{}, Server: pong.EventPaddleMoveData{}},
+				{Name: "BallShot", Type: pong.EventTypeBallShot, Client: None},
+				{Name: "BallUpdate", Type: pong.EventTypeBallUpdate, Server: pong.Ball{}.Position},
+				{Name: "Goal", Type: pong.EventTypeGoal, Server: pong.EventDataGoal{}},
+				{Name: "SyncGameState", Type: pong.EventTypeSyncGameState, Server: pong.EventDataSync{}},
+				{Name: "GameFinished", Type: pong.EventTypeGameFinished, Server: pong.EventDataGameFinished{}},
+			},
+		},
+		{
+			Package: "presence",
+			Path:    "/ws/lobby",
+			Events: []Event{
+				{Name: "LobbyUpdate", Type: presence.EventTypeLobbyUpdate, Server: presence.Snapshot{}},
+				{Name: "Invite", Type: presence.EventTypeInvite, Server: presence.Invite{}},
+				{Name: "InviteAnswer", Type: presence.EventTypeInviteAnswer, Client: presence.InviteAnswer{}, Server: presence.InviteAnswer{}},
+				{Name: "InviteAnswered", Type: presence.EventTypeInviteAnswered, Server: presence.InviteAnswer{}},
+			},
+		},
+	}
+}
+
+// Validate checks that no two events of the socket share a type or a name,
+// the shared ones included
+func (s Socket) Validate() error {
+	types := make(map[ws.EventType]string)
+	names := make(map[string]bool)
+	for _, event := range append(append([]Event{}, s.Events...), Shared...) {
+		if other, ok := types[event.Type]; ok {
+			return fmt.Errorf("%s: %s and %s are both event %d", s.Package, other, event.Name, event.Type)
+		}
+		if names[event.Name] {
+			return fmt.Errorf("%s: there are two %s events", s.Package, event.Name)
+		}
+		types[event.Type] = event.Name
+		names[event.Name] = true
+	}
+	for _, event := range s.Events {
+		if event.Type >= ws.EventTypeFirstShared {
+			return fmt.Errorf("%s: %s is numbered with the shared events", s.Package, event.Name)
+		}
+	}
+	return nil
+}
+
+// payloadTypes returns the types of the data of the events
+func payloadTypes(events []Event) []reflect.Type {
+	var types []reflect.Type
+	for _, event := range events {
+		for _, data := range []any{event.Client, event.Server} {
+			if data != nil && data != None {
+				types = append(types, reflect.TypeOf(data))
+			}
+		}
+	}
+	return types
+}
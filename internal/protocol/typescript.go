@@ -0,0 +1,304 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/ws"
+)
+
+const ts_indent = "    "
+
+var (
+	time_type        = reflect.TypeOf(time.Time{})
+	raw_message_type = reflect.TypeOf(json.RawMessage{})
+	error_code_type  = reflect.TypeOf(ws.ErrorCode(""))
+
+	ts_identifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+)
+
+// tsGenerator turns the schema into a TypeScript namespace, the Go types are
+// grouped by the package they are declared in
+type tsGenerator struct {
+	types map[string]map[string]reflect.Type
+}
+
+// GenerateTypeScript writes the schema as the Protocol namespace the
+// clients in assets/scripts use, every socket gets an enum of its event
+// types and the data of the events is described by TypeScript interfaces
+func GenerateTypeScript(w io.Writer) error {
+	sockets := Sockets()
+	for _, socket := range sockets {
+		if err := socket.Validate(); err != nil {
+			return err
+		}
+	}
+
+	g := &tsGenerator{types: make(map[string]map[string]reflect.Type)}
+	g.add(reflect.TypeOf(ws.Event{}))
+	for _, t := range payloadTypes(Shared) {
+		g.add(t)
+	}
+	bySocket := make(map[string]Socket)
+	for _, socket := range sockets {
+		for _, t := range payloadTypes(socket.Events) {
+			g.add(t)
+		}
+		bySocket[socket.Package] = socket
+		if g.types[socket.Package] == nil {
+			g.types[socket.Package] = make(map[string]reflect.Type)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintln(buf, "// Code generated by protocolgen from the schema in internal/protocol. DO NOT EDIT.")
+	fmt.Fprintln(buf)
+	fmt.Fprintln(buf, "namespace Protocol {")
+	g.writeVersion(buf)
+
+	packages := make([]string, 0, len(g.types))
+	for pkg := range g.types {
+		packages = append(packages, pkg)
+	}
+	sort.Strings(packages)
+	for _, pkg := range packages {
+		fmt.Fprintln(buf)
+		fmt.Fprintf(buf, "%sexport namespace %s {\n", ts_indent, pkg)
+		first := true
+		if socket, ok := bySocket[pkg]; ok {
+			g.writeSocket(buf, socket)
+			first = false
+		}
+		if pkg == path.Base(error_code_type.PkgPath()) {
+			g.writeErrorCodes(buf, first)
+			first = false
+		}
+		names := make([]string, 0, len(g.types[pkg]))
+		for name := range g.types[pkg] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if !first {
+				fmt.Fprintln(buf)
+			}
+			first = false
+			g.writeType(buf, name, g.types[pkg][name])
+		}
+		fmt.Fprintf(buf, "%s}\n", ts_indent)
+	}
+	fmt.Fprintln(buf, "}")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (g *tsGenerator) writeVersion(buf *bytes.Buffer) {
+	fmt.Fprintf(buf, "%sexport const Version = %d\n", ts_indent, ws.ProtocolVersion)
+	fmt.Fprintf(buf, "%sexport const VersionParam = %q\n", ts_indent, ws.ProtocolVersionParam)
+	fmt.Fprintf(buf, "%s// CloseProtocolMismatch closes the sockets of clients of another version\n", ts_indent)
+	fmt.Fprintf(buf, "%sexport const CloseProtocolMismatch = %d\n", ts_indent, ws.CloseProtocolMismatch)
+	fmt.Fprintln(buf)
+	fmt.Fprintf(buf, "%s// socketURL is the address of the socket at path for clients of this version\n", ts_indent)
+	fmt.Fprintf(buf, "%sexport function socketURL(path: string): string {\n", ts_indent)
+	fmt.Fprintf(buf, "%s%sconst scheme = window.location.protocol == \"https:\" ? \"wss://\" : \"ws://\"\n", ts_indent, ts_indent)
+	fmt.Fprintf(buf, "%s%sreturn scheme + window.location.host + path + \"?\" + VersionParam + \"=\" + Version\n", ts_indent, ts_indent)
+	fmt.Fprintf(buf, "%s}\n", ts_indent)
+}
+
+func (g *tsGenerator) writeSocket(buf *bytes.Buffer, socket Socket) {
+	in := ts_indent + ts_indent
+	events := append(append([]Event{}, socket.Events...), Shared...)
+
+	fmt.Fprintf(buf, "%sexport const Path = %q\n", in, socket.Path)
+	fmt.Fprintln(buf)
+	fmt.Fprintf(buf, "%sexport enum EventType {\n", in)
+	for _, event := range events {
+		fmt.Fprintf(buf, "%s%s%s = %d,\n", in, ts_indent, event.Name, event.Type)
+	}
+	fmt.Fprintf(buf, "%s}\n", in)
+
+	for _, side := range []string{"Client", "Server"} {
+		fmt.Fprintln(buf)
+		if side == "Client" {
+			fmt.Fprintf(buf, "%s// ClientData is the data clients send with each event\n", in)
+		} else {
+			fmt.Fprintf(buf, "%s// ServerData is the data clients get with each event\n", in)
+		}
+		fmt.Fprintf(buf, "%sexport type %sData = {\n", in, side)
+		for _, event := range events {
+			data := event.Client
+			if side == "Server" {
+				data = event.Server
+			}
+			switch {
+			case data == nil:
+				continue
+			case data == None:
+				fmt.Fprintf(buf, "%s%s[EventType.%s]: null\n", in, ts_indent, event.Name)
+			default:
+				fmt.Fprintf(buf, "%s%s[EventType.%s]: %s\n", in, ts_indent, event.Name, g.tsType(reflect.TypeOf(data)))
+			}
+		}
+		fmt.Fprintf(buf, "%s}\n", in)
+	}
+}
+
+func (g *tsGenerator) writeErrorCodes(buf *bytes.Buffer, first bool) {
+	in := ts_indent + ts_indent
+	if !first {
+		fmt.Fprintln(buf)
+	}
+	fmt.Fprintf(buf, "%sexport enum %s {\n", in, error_code_type.Name())
+	for _, code := range ws.ErrorCodes() {
+		fmt.Fprintf(buf, "%s%s%s = %q,\n", in, ts_indent, pascalCase(string(code)), code)
+	}
+	fmt.Fprintf(buf, "%s}\n", in)
+}
+
+func (g *tsGenerator) writeType(buf *bytes.Buffer, name string, t reflect.Type) {
+	in := ts_indent + ts_indent
+	if t.Kind() != reflect.Struct {
+		fmt.Fprintf(buf, "%sexport type %s = %s\n", in, name, g.tsUnnamed(t))
+		return
+	}
+	fmt.Fprintf(buf, "%sexport interface %s {\n", in, name)
+	for _, field := range g.fields(t) {
+		fmt.Fprintf(buf, "%s%s%s\n", in, ts_indent, field)
+	}
+	fmt.Fprintf(buf, "%s}\n", in)
+}
+
+// add records the named types reachable from t
+func (g *tsGenerator) add(t reflect.Type) {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		if t == raw_message_type {
+			return
+		}
+		t = t.Elem()
+	}
+	if t == time_type {
+		return
+	}
+	if t.Name() != "" && t.PkgPath() != "" {
+		if t == error_code_type {
+			return
+		}
+		pkg := path.Base(t.PkgPath())
+		if g.types[pkg] == nil {
+			g.types[pkg] = make(map[string]reflect.Type)
+		}
+		if _, ok := g.types[pkg][t.Name()]; ok {
+			return
+		}
+		g.types[pkg][t.Name()] = t
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if field.IsExported() || field.Anonymous {
+			g.add(field.Type)
+		}
+	}
+}
+
+// fields returns the fields of the struct as encoding/json writes them, the
+// fields of embedded structs are part of it
+func (g *tsGenerator) fields(t reflect.Type) []string {
+	var fields []string
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		fieldType := field.Type
+		if field.Anonymous && name == "" {
+			if fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				fields = append(fields, g.fields(fieldType)...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if !ts_identifier.MatchString(name) {
+			name = fmt.Sprintf("%q", name)
+		}
+		optional := strings.Contains(","+opts+",", ",omitempty,")
+		tsType := g.tsType(fieldType)
+		if fieldType.Kind() == reflect.Pointer && !optional {
+			tsType += " | null"
+		}
+		if optional {
+			name += "?"
+		}
+		fields = append(fields, name+": "+tsType)
+	}
+	return fields
+}
+
+func (g *tsGenerator) tsType(t reflect.Type) string {
+	switch {
+	case t == time_type:
+		return "string"
+	case t == raw_message_type:
+		return "unknown"
+	case t.Kind() == reflect.Pointer:
+		return g.tsType(t.Elem())
+	case t.Name() != "" && t.PkgPath() != "":
+		return path.Base(t.PkgPath()) + "." + t.Name()
+	}
+	return g.tsUnnamed(t)
+}
+
+// tsUnnamed is the TypeScript of a type regardless of its name
+func (g *tsGenerator) tsUnnamed(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		// encoding/json writes bytes as base64
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string"
+		}
+		return g.tsType(t.Elem()) + "[]"
+	case reflect.Map:
+		return "Record<string, " + g.tsType(t.Elem()) + ">"
+	case reflect.Struct:
+		return "{ " + strings.Join(g.fields(t), ", ") + " }"
+	}
+	return "unknown"
+}
+
+func pascalCase(s string) string {
+	words := strings.Split(s, "_")
+	for i, word := range words {
+		if word != "" {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, "")
+}
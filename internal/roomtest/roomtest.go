@@ -0,0 +1,78 @@
+// Package roomtest drives the rooms of a game service from its tests, the
+// clients it makes have no connection and keep the events they are sent
+package roomtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/utils"
+	"github.com/FredericoBento/HandGame/internal/ws"
+)
+
+// Rooms is what the helpers need of a *gameroom.Manager
+type Rooms interface {
+	Register(client *ws.Client)
+	ReadMessageHandler(client *ws.Client, event ws.Event)
+}
+
+const (
+	// client_queue_size is how many events a test client keeps until they
+	// are read, games streaming positions send plenty of them
+	client_queue_size = 1024
+	wait_timeout      = 2 * time.Second
+)
+
+// NewClient registers a client for the username as if it just connected
+func NewClient(rooms Rooms, username string) *ws.Client {
+	client := &ws.Client{Username: username, Event: make(chan *ws.Event, client_queue_size)}
+	rooms.Register(client)
+	return client
+}
+
+// Send hands the rooms an event of the client with the data encoded, data
+// may be nil for events without any
+func Send(t *testing.T, rooms Rooms, client *ws.Client, eventType ws.EventType, data any) {
+	event := ws.NewSimpleEvent(eventType)
+	if data != nil {
+		bytes, err := utils.EncodeJSON(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		event.Data = bytes
+	}
+	rooms.ReadMessageHandler(client, event)
+}
+
+// WaitForEvent skips the events of the client until one of the type that is
+// not an error
+func WaitForEvent(t *testing.T, client *ws.Client, eventType ws.EventType) *ws.Event {
+	timeout := time.After(wait_timeout)
+	for {
+		select {
+		case event := <-client.Event:
+			if event.Type == eventType && !event.IsError {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("expected %s to get event %d but got nothing", client.Username, eventType)
+			return nil
+		}
+	}
+}
+
+// WaitForError skips the events of the client until an error
+func WaitForError(t *testing.T, client *ws.Client) *ws.Event {
+	timeout := time.After(wait_timeout)
+	for {
+		select {
+		case event := <-client.Event:
+			if event.IsError {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("expected %s to get an error but got nothing", client.Username)
+			return nil
+		}
+	}
+}
@@ -28,12 +28,16 @@ var (
 )
 
 type ServerHandlers struct {
-	AuthHandler      http.Handler
-	HomeHandler      http.Handler
-	AdminHandler     http.Handler
-	HandGameHandler  http.Handler
-	PongHandler      http.Handler
-	TicTacToeHandler http.Handler
+	AuthHandler        http.Handler
+	HomeHandler        http.Handler
+	AdminHandler       http.Handler
+	HandGameHandler    http.Handler
+	PongHandler        http.Handler
+	TicTacToeHandler   http.Handler
+	ConnectFourHandler http.Handler
+	LeaderboardHandler http.Handler
+	ProfileHandler     http.Handler
+	FriendsHandler     http.Handler
 }
 
 type Server struct {
@@ -85,14 +89,18 @@ func NewServer(opts ...ServerOption) *Server {
 	return server
 }
 
-func NewServerHandlers(authH http.Handler, adminH http.Handler, homeH http.Handler, handGameH http.Handler, pongH http.Handler, tictactoeH http.Handler) *ServerHandlers {
+func NewServerHandlers(authH http.Handler, adminH http.Handler, homeH http.Handler, handGameH http.Handler, pongH http.Handler, tictactoeH http.Handler, connectFourH http.Handler, leaderboardH http.Handler, profileH http.Handler, friendsH http.Handler) *ServerHandlers {
 	return &ServerHandlers{
-		AuthHandler:      authH,
-		AdminHandler:     adminH,
-		HomeHandler:      homeH,
-		HandGameHandler:  handGameH,
-		PongHandler:      pongH,
-		TicTacToeHandler: tictactoeH,
+		AuthHandler:        authH,
+		AdminHandler:       adminH,
+		HomeHandler:        homeH,
+		HandGameHandler:    handGameH,
+		PongHandler:        pongH,
+		TicTacToeHandler:   tictactoeH,
+		ConnectFourHandler: connectFourH,
+		LeaderboardHandler: leaderboardH,
+		ProfileHandler:     profileH,
+		FriendsHandler:     friendsH,
 	}
 }
 
@@ -139,6 +147,16 @@ func (s *Server) setupRoutes() error {
 	s.Router.Handle("/home", authHandlerMiddlewares(s.Handlers.HomeHandler))
 	s.Router.Handle("/", http.RedirectHandler("/home", http.StatusSeeOther))
 
+	// Leaderboard, the per game ones are set up with their game routes
+	s.Router.Handle("/leaderboard", authHandlerMiddlewares(s.Handlers.LeaderboardHandler))
+
+	// Profile
+	s.Router.Handle("/profile", authHandlerMiddlewares(s.Handlers.ProfileHandler))
+
+	// Friends panel of the navbar
+	s.Router.Handle("/friends", authHandlerMiddlewares(s.Handlers.FriendsHandler))
+	s.Router.Handle("/friends/", authHandlerMiddlewares(s.Handlers.FriendsHandler))
+
 	// Fileserver
 	fs := http.FileServer(http.Dir("./assets"))
 	s.Router.Handle("/assets/", standardMiddlewares(http.StripPrefix("/assets", fs)))
@@ -153,12 +171,19 @@ func (s *Server) SetupHandGameRoutes(routePrefix string) {
 	)
 
 	s.Router.Handle(routePrefix+"/home", middlewares(s.Handlers.HandGameHandler))
+	s.Router.Handle(routePrefix+"/rooms", middlewares(s.Handlers.HandGameHandler))
 
 	//We need to set the routes before the server listening
 	//This makes sure the routes only are allow after the game service is started
 	s.BlockRoutes(routePrefix)
 }
 
+func (s *Server) SetupHandGameWebsocketLogic(wsHandler http.HandlerFunc) {
+	wsHandler = http.HandlerFunc(wsHandler)
+
+	s.Router.Handle("/ws/handgame", standardWebsocketMiddlewares(wsHandler))
+}
+
 func (s *Server) SetupPongGameRoutes(routePrefix string) {
 	middlewares := middleware.StackMiddleware(
 		standardMiddlewares,
@@ -166,6 +191,7 @@ func (s *Server) SetupPongGameRoutes(routePrefix string) {
 	)
 
 	s.Router.Handle(routePrefix+"/home", middlewares(s.Handlers.PongHandler))
+	s.Router.Handle(routePrefix+"/leaderboard", standardMiddlewares(s.Handlers.LeaderboardHandler))
 
 	//We need to set the routes before the server listening
 	//This makes sure the routes only are allow after the game service is started
@@ -185,6 +211,7 @@ func (s *Server) SetupTicTacToeGameRoutes(routePrefix string) {
 	)
 
 	s.Router.Handle(routePrefix+"/home", middlewares(s.Handlers.TicTacToeHandler))
+	s.Router.Handle(routePrefix+"/leaderboard", standardMiddlewares(s.Handlers.LeaderboardHandler))
 
 	//We need to set the routes before the server listening
 	//This makes sure the routes only are allow after the game service is started
@@ -197,6 +224,32 @@ func (s *Server) SetupTicTacToeGameWebsocketLogic(wsHandler http.HandlerFunc) {
 	s.Router.Handle("/ws/tictactoe", standardWebsocketMiddlewares(wsHandler))
 }
 
+func (s *Server) SetupConnectFourGameRoutes(routePrefix string) {
+	middlewares := middleware.StackMiddleware(
+		standardMiddlewares,
+		middleware.RequiredLogged,
+	)
+
+	s.Router.Handle(routePrefix+"/home", middlewares(s.Handlers.ConnectFourHandler))
+	s.Router.Handle(routePrefix+"/leaderboard", standardMiddlewares(s.Handlers.LeaderboardHandler))
+
+	//We need to set the routes before the server listening
+	//This makes sure the routes only are allow after the game service is started
+	s.BlockRoutes(routePrefix)
+}
+
+func (s *Server) SetupConnectFourGameWebsocketLogic(wsHandler http.HandlerFunc) {
+	wsHandler = http.HandlerFunc(wsHandler)
+
+	s.Router.Handle("/ws/connectfour", standardWebsocketMiddlewares(wsHandler))
+}
+
+// SetupLobbyWebsocketLogic serves the lobby socket, the home page keeps the
+// list of online players and open rooms up to date with it
+func (s *Server) SetupLobbyWebsocketLogic(wsHandler http.HandlerFunc) {
+	s.Router.Handle("/ws/lobby", standardWebsocketMiddlewares(wsHandler))
+}
+
 func (s *Server) Run() error {
 	addr := s.Host + ":" + strconv.Itoa(s.Port)
 
@@ -2,17 +2,17 @@ package services
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"log"
 	"log/slog"
 	"net/http"
-	"sync"
 	"time"
 
+	"github.com/FredericoBento/HandGame/internal/database/repository"
 	"github.com/FredericoBento/HandGame/internal/logger"
 	"github.com/FredericoBento/HandGame/internal/models"
 	"github.com/google/uuid"
-	"golang.org/x/net/websocket"
 )
 
 var (
@@ -22,30 +22,33 @@ var (
 	ErrSessionExpired          = errors.New("session has expired")
 	ErrTokenDoesNotExists      = errors.New("token invalid, does not exist")
 	ErrNoToken                 = errors.New("token not found")
+	ErrCouldNotCreateSession   = errors.New("could not create session")
+	ErrCouldNotGetSession      = errors.New("could not retrieve session")
 )
 
 const (
-	sessionExpiryTime = 120 * time.Minute
-	cookieName        = "session_token"
+	// A session expires after sessionIdleTimeout without being used, using it
+	// pushes the expiry forward but never past sessionMaxLifetime
+	sessionIdleTimeout = 120 * time.Minute
+	sessionMaxLifetime = 30 * 24 * time.Hour
+	// sessionTouchInterval keeps every request from writing the session back
+	sessionTouchInterval = time.Minute
+	cookieName           = "session_token"
 )
 
-type Session struct {
-	username string
-	expiry   time.Time
-	socket   *websocket.Conn
-}
-
 type AuthService struct {
-	sessions    map[string]Session
+	sessions    repository.SessionRepository
 	userService *UserService
-	mu          sync.Mutex
 	log         *slog.Logger
 }
 
-func NewAuthService(userService *UserService) *AuthService {
+func NewAuthService(userService *UserService, sessions repository.SessionRepository) *AuthService {
 	if userService == nil {
 		log.Fatal("no user service provided")
 	}
+	if sessions == nil {
+		log.Fatal("no session repository provided")
+	}
 
 	lo, err := logger.NewServiceLogger("AuthService", "", true)
 	if err != nil {
@@ -53,9 +56,8 @@ func NewAuthService(userService *UserService) *AuthService {
 	}
 
 	return &AuthService{
-		sessions:    make(map[string]Session),
+		sessions:    sessions,
 		userService: userService,
-		mu:          sync.Mutex{},
 		log:         lo,
 	}
 }
@@ -82,34 +84,52 @@ func (s *AuthService) Authenticate(ctx context.Context, username string, passwor
 }
 
 func (s *AuthService) CreateSession(user *models.User) (string, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	sessionToken := uuid.NewString()
-	session := Session{
-		username: user.Username,
-		expiry:   time.Now().Add(sessionExpiryTime),
+	now := time.Now()
+	session := &models.Session{
+		Token:      uuid.NewString(),
+		Username:   user.Username,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(sessionIdleTimeout),
 	}
 
-	s.sessions[sessionToken] = session
+	err := s.sessions.Create(context.Background(), session)
+	if err != nil {
+		s.log.Error(err.Error())
+		return "", ErrCouldNotCreateSession
+	}
 
-	return sessionToken, nil
+	return session.Token, nil
 }
 
+// ValidateSession returns the user of the session, using a session keeps it
+// alive for another sessionIdleTimeout
 func (s *AuthService) ValidateSession(ctx context.Context, token string) (*models.User, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	session, exists := s.sessions[token]
-	if !exists {
-		return nil, ErrTokenDoesNotExists
+	session, err := s.sessions.Get(ctx, token)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTokenDoesNotExists
+		}
+		s.log.Error(err.Error())
+		return nil, ErrCouldNotGetSession
 	}
 
-	if session.IsExpired() {
+	now := time.Now()
+	if session.IsExpired(now) {
+		if err := s.sessions.Delete(ctx, token); err != nil {
+			s.log.Error(err.Error())
+		}
 		return nil, ErrSessionExpired
 	}
 
-	user, err := s.userService.GetUserByUsername(ctx, session.username)
+	if now.Sub(session.LastSeenAt) >= sessionTouchInterval {
+		err = s.sessions.Touch(ctx, token, now, slidingExpiry(session, now))
+		if err != nil {
+			s.log.Error(err.Error())
+		}
+	}
+
+	user, err := s.userService.GetUserByUsername(ctx, session.Username)
 	if err != nil {
 		s.log.Error(err.Error())
 		return nil, ErrCouldNotFindUser
@@ -119,22 +139,47 @@ func (s *AuthService) ValidateSession(ctx context.Context, token string) (*model
 }
 
 func (s *AuthService) DestroySession(ctx context.Context, token string) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	if err := s.sessions.Delete(ctx, token); err != nil {
+		s.log.Error(err.Error())
+	}
+}
+
+// RunSessionSweeper deletes the expired sessions every interval, it never returns
+func (s *AuthService) RunSessionSweeper(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-	delete(s.sessions, token)
+	for now := range ticker.C {
+		s.SweepSessions(context.Background(), now)
+	}
 }
 
-func (s *AuthService) GetCookieName() string {
-	return cookieName
+func (s *AuthService) SweepSessions(ctx context.Context, now time.Time) {
+	deleted, err := s.sessions.DeleteExpired(ctx, now)
+	if err != nil {
+		s.log.Error(err.Error())
+		return
+	}
+	if deleted > 0 {
+		s.log.Info("Swept expired sessions", "deleted", deleted)
+	}
 }
 
-func (s *Session) IsExpired() bool {
-	return s.expiry.Before(time.Now())
+func slidingExpiry(session *models.Session, now time.Time) time.Time {
+	expiry := now.Add(sessionIdleTimeout)
+	limit := session.CreatedAt.Add(sessionMaxLifetime)
+	if expiry.After(limit) {
+		return limit
+	}
+	return expiry
+}
+
+func (s *AuthService) GetCookieName() string {
+	return cookieName
 }
 
-func (s *AuthService) IsAdmin(username string) bool {
-	return username == "fred"
+func (s *AuthService) IsAdmin(user *models.User) bool {
+	return user != nil && user.IsAdmin()
 }
 
 func (s *AuthService) GetToken(r *http.Request) (string, error) {
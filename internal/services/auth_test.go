@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/mock"
+	"github.com/FredericoBento/HandGame/internal/models"
+)
+
+func newTestAuthService(sessions *mock.MockSessionRepository) *AuthService {
+	userRepo := &mock.MockUserRepository{
+		GetByUsernameResult: &models.User{Username: "session_user"},
+	}
+	return NewAuthService(NewUserService(userRepo, 2*time.Minute), sessions)
+}
+
+func TestValidateSession(t *testing.T) {
+	t.Run("SlidesExpiry", func(t *testing.T) {
+		sessions := mock.NewMockSessionRepository()
+		auth := newTestAuthService(sessions)
+
+		token, err := auth.CreateSession(&models.User{Username: "session_user"})
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+
+		// Pretend the session was last used a while ago, about to expire
+		session := sessions.Sessions[token]
+		session.LastSeenAt = time.Now().Add(-sessionIdleTimeout + time.Minute)
+		session.ExpiresAt = time.Now().Add(time.Minute)
+		sessions.Sessions[token] = session
+
+		user, err := auth.ValidateSession(context.TODO(), token)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if user.Username != "session_user" {
+			t.Errorf("expected session_user but got: %v", user.Username)
+		}
+		if sessions.Sessions[token].ExpiresAt.Before(time.Now().Add(sessionIdleTimeout - time.Minute)) {
+			t.Errorf("expected expiry to slide forward but got: %v", sessions.Sessions[token].ExpiresAt)
+		}
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		sessions := mock.NewMockSessionRepository()
+		auth := newTestAuthService(sessions)
+		sessions.Sessions["old"] = models.Session{
+			Token:     "old",
+			Username:  "session_user",
+			ExpiresAt: time.Now().Add(-time.Minute),
+		}
+
+		_, err := auth.ValidateSession(context.TODO(), "old")
+		if err != ErrSessionExpired {
+			t.Errorf("expected %v but got: %v", ErrSessionExpired, err)
+		}
+		if _, ok := sessions.Sessions["old"]; ok {
+			t.Errorf("expected expired session to be deleted")
+		}
+	})
+
+	t.Run("UnknownToken", func(t *testing.T) {
+		auth := newTestAuthService(mock.NewMockSessionRepository())
+
+		_, err := auth.ValidateSession(context.TODO(), "missing")
+		if err != ErrTokenDoesNotExists {
+			t.Errorf("expected %v but got: %v", ErrTokenDoesNotExists, err)
+		}
+	})
+}
+
+func TestSlidingExpiryCapped(t *testing.T) {
+	now := time.Now()
+	session := &models.Session{CreatedAt: now.Add(-sessionMaxLifetime + time.Minute)}
+
+	expiry := slidingExpiry(session, now)
+	if !expiry.Equal(session.CreatedAt.Add(sessionMaxLifetime)) {
+		t.Errorf("expected expiry to be capped at the max lifetime but got: %v", expiry)
+	}
+}
+
+func TestSweepSessions(t *testing.T) {
+	sessions := mock.NewMockSessionRepository()
+	auth := newTestAuthService(sessions)
+	now := time.Now()
+	sessions.Sessions["expired"] = models.Session{Token: "expired", ExpiresAt: now.Add(-time.Second)}
+	sessions.Sessions["active"] = models.Session{Token: "active", ExpiresAt: now.Add(time.Hour)}
+
+	auth.SweepSessions(context.TODO(), now)
+
+	if _, ok := sessions.Sessions["expired"]; ok {
+		t.Errorf("expected expired session to be swept")
+	}
+	if _, ok := sessions.Sessions["active"]; !ok {
+		t.Errorf("expected active session to be kept")
+	}
+}
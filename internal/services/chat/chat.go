@@ -0,0 +1,126 @@
+package chat
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/FredericoBento/HandGame/internal/ws"
+)
+
+const (
+	// A user may send default_rate_limit messages every default_rate_period,
+	// across every room of a game
+	default_rate_limit  = 5
+	default_rate_period = 10 * time.Second
+
+	default_max_length   = 280
+	default_history_size = 50
+)
+
+var (
+	ErrEmptyMessage   = ws.NewError(ws.ErrorCodeInvalidMessage, "Message is empty").WithField("text")
+	ErrMessageTooLong = ws.NewError(ws.ErrorCodeInvalidMessage, "Message is too long").WithField("text")
+	ErrRateLimited    = ws.NewError(ws.ErrorCodeRateLimited, "You are sending messages too fast, slow down")
+)
+
+// Message is a line of the chat of a room as sent to its members
+type Message struct {
+	From   string    `json:"from"`
+	Text   string    `json:"text"`
+	SentAt time.Time `json:"sent_at"`
+}
+
+// Moderator checks and filters the messages of every room of a game, it is
+// safe for concurrent use as long as its Filter is
+type Moderator struct {
+	limiter   *Limiter
+	filter    Filter
+	maxLength int
+}
+
+type ModeratorOption func(*Moderator)
+
+// WithRateLimit lets a user send limit messages every period
+func WithRateLimit(limit int, period time.Duration) ModeratorOption {
+	return func(m *Moderator) {
+		m.limiter = NewLimiter(limit, period)
+	}
+}
+
+// WithFilter rewrites every message with filter before it is sent
+func WithFilter(filter Filter) ModeratorOption {
+	return func(m *Moderator) {
+		m.filter = filter
+	}
+}
+
+// WithMaxLength caps the length of a message, in characters
+func WithMaxLength(length int) ModeratorOption {
+	return func(m *Moderator) {
+		m.maxLength = length
+	}
+}
+
+func NewModerator(opts ...ModeratorOption) *Moderator {
+	m := &Moderator{
+		limiter:   NewLimiter(default_rate_limit, default_rate_period),
+		maxLength: default_max_length,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Check turns the text a user sent into the message its room gets, it counts
+// toward the rate limit of the user only if the message is valid
+func (m *Moderator) Check(from string, text string, now time.Time) (Message, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return Message{}, ErrEmptyMessage
+	}
+	if utf8.RuneCountInString(text) > m.maxLength {
+		return Message{}, ErrMessageTooLong
+	}
+	if !m.limiter.Allow(from, now) {
+		return Message{}, ErrRateLimited
+	}
+	if m.filter != nil {
+		text = m.filter.Filter(text)
+	}
+	return Message{From: from, Text: text, SentAt: now}, nil
+}
+
+// History keeps the last messages of a room for the members that join later,
+// it is owned by the room and not safe for concurrent use
+type History struct {
+	messages []Message
+	size     int
+}
+
+func NewHistory(size int) *History {
+	if size <= 0 {
+		size = default_history_size
+	}
+	return &History{
+		messages: make([]Message, 0, size),
+		size:     size,
+	}
+}
+
+// Add keeps the message, the oldest one is forgotten once the history is
+// full
+func (h *History) Add(message Message) {
+	if len(h.messages) == h.size {
+		h.messages = append(h.messages[:0], h.messages[1:]...)
+	}
+	h.messages = append(h.messages, message)
+}
+
+// Messages returns the kept messages, the oldest first
+func (h *History) Messages() []Message {
+	messages := make([]Message, len(h.messages))
+	copy(messages, h.messages)
+	return messages
+}
@@ -0,0 +1,77 @@
+package chat
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestModeratorCheck(t *testing.T) {
+	m := NewModerator(WithMaxLength(5), WithFilter(NewWordFilter("darn")))
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+		err      error
+	}{
+		{"Trimmed", "  hi  ", "hi", nil},
+		{"Empty", "   ", "", ErrEmptyMessage},
+		{"TooLong", "hello!", "", ErrMessageTooLong},
+		{"Filtered", "DARN!", "****!", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := m.Check("fred", tt.text, now)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected %v but got: %v", tt.err, err)
+			}
+			if message.Text != tt.expected {
+				t.Errorf("expected %q but got: %q", tt.expected, message.Text)
+			}
+			if err == nil && message.From != "fred" {
+				t.Errorf("expected the message to be from fred but got: %q", message.From)
+			}
+		})
+	}
+}
+
+func TestModeratorRateLimit(t *testing.T) {
+	m := NewModerator(WithRateLimit(2, time.Second))
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		if _, err := m.Check("fred", "hi", now); err != nil {
+			t.Fatalf("expected message %d to be allowed but got: %v", i, err)
+		}
+	}
+	if _, err := m.Check("fred", "hi", now); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited but got: %v", err)
+	}
+	if _, err := m.Check("bento", "hi", now); err != nil {
+		t.Errorf("expected other users not to be limited but got: %v", err)
+	}
+	if _, err := m.Check("fred", "hi", now.Add(time.Second)); err != nil {
+		t.Errorf("expected fred to send again after the period but got: %v", err)
+	}
+}
+
+func TestWordFilterMasksWholeWords(t *testing.T) {
+	f := NewWordFilter("bad")
+	got := f.Filter("Bad badge, bad.")
+	if got != "*** badge, ***." {
+		t.Errorf("expected only whole words to be masked but got: %q", got)
+	}
+}
+
+func TestHistoryKeepsLastMessages(t *testing.T) {
+	h := NewHistory(2)
+	for _, text := range []string{"a", "b", "c"} {
+		h.Add(Message{Text: text})
+	}
+	messages := h.Messages()
+	if len(messages) != 2 || messages[0].Text != "b" || messages[1].Text != "c" {
+		t.Errorf("expected the last 2 messages but got: %+v", messages)
+	}
+}
@@ -0,0 +1,56 @@
+package chat
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Filter rewrites the text of a message before it is sent, like hiding the
+// words a game does not want in its rooms
+type Filter interface {
+	Filter(text string) string
+}
+
+// FilterFunc lets a plain function be a Filter
+type FilterFunc func(text string) string
+
+func (f FilterFunc) Filter(text string) string {
+	return f(text)
+}
+
+// WordFilter masks the listed words with asterisks whatever their case, only
+// whole words are masked
+type WordFilter struct {
+	words map[string]bool
+}
+
+func NewWordFilter(words ...string) *WordFilter {
+	f := &WordFilter{words: make(map[string]bool, len(words))}
+	for _, word := range words {
+		f.words[strings.ToLower(word)] = true
+	}
+	return f
+}
+
+func (f *WordFilter) Filter(text string) string {
+	var b strings.Builder
+	word := make([]rune, 0, 16)
+	flush := func() {
+		if f.words[strings.ToLower(string(word))] {
+			b.WriteString(strings.Repeat("*", len(word)))
+		} else {
+			b.WriteString(string(word))
+		}
+		word = word[:0]
+	}
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			word = append(word, r)
+			continue
+		}
+		flush()
+		b.WriteRune(r)
+	}
+	flush()
+	return b.String()
+}
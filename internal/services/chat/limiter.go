@@ -0,0 +1,44 @@
+package chat
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter allows each user a number of messages over a sliding period
+type Limiter struct {
+	limit  int
+	period time.Duration
+
+	mu   sync.Mutex
+	sent map[string][]time.Time
+}
+
+func NewLimiter(limit int, period time.Duration) *Limiter {
+	return &Limiter{
+		limit:  limit,
+		period: period,
+		sent:   make(map[string][]time.Time),
+	}
+}
+
+// Allow returns true and counts the message if the user did not send limit
+// messages during the period before now
+func (l *Limiter) Allow(username string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	sent := l.sent[username]
+	from := now.Add(-l.period)
+	i := 0
+	for i < len(sent) && !sent[i].After(from) {
+		i++
+	}
+	sent = sent[i:]
+	if len(sent) >= l.limit {
+		l.sent[username] = sent
+		return false
+	}
+	l.sent[username] = append(sent, now)
+	return true
+}
@@ -0,0 +1,19 @@
+package connectfour
+
+import (
+	"encoding/json"
+
+	"github.com/FredericoBento/HandGame/internal/services/gameroom"
+)
+
+// newEngine plays the game of one room on a gameroom.Duel, the board and its
+// moves are in state.go
+func (s *ConnectFourService) newEngine(room *gameroom.Room, options json.RawMessage) (gameroom.Engine, error) {
+	state := NewGameState(room.Code)
+	return &gameroom.Duel{
+		State:   &state.DuelState,
+		Rules:   state,
+		Events:  duelEvents,
+		Matches: s.recorder,
+	}, nil
+}
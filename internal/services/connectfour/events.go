@@ -0,0 +1,53 @@
+package connectfour
+
+import (
+	"github.com/FredericoBento/HandGame/internal/ws"
+)
+
+const (
+	EventTypeCreateGame        = 1
+	EventTypeJoinGame          = 2
+	EventTypeJoinedGame        = 22
+	EventTypeOtherPlayerJoined = 23
+
+	EventTypeDropDisc = 3
+
+	EventTypePlayerSendMessage = 4
+
+	EventTypePlayerDisconnected = 5
+	EventTypePlayerReconnected  = 6
+	EventTypeError              = 7
+
+	EventTypeDiscDropped = 8
+	EventTypeStateUpdate = 9
+
+	EventTypeTie     = 10
+	EventTypeVictory = 11
+	EventTypeDefeat  = 12
+
+	EventTypeFindMatch       = 13
+	EventTypeCancelFindMatch = 14
+
+	EventTypeSpectateGame     = 15
+	EventTypeSpectatorsUpdate = 16
+
+	EventTypeReconnectCountdown = 17
+	EventTypeForfeit            = 18
+
+	EventTypeChatHistory = 19
+)
+
+// EventDataDrop is the column a player drops its disc in
+type EventDataDrop struct {
+	Col int `json:"col"`
+}
+
+type EventDataDiscDropped struct {
+	Row   int `json:"row"`
+	Col   int `json:"col"`
+	Value int `json:"value"`
+}
+
+var (
+	ErrCouldNotPlay = ws.NewError(ws.ErrorCodeInvalidPlay, "Could not make play")
+)
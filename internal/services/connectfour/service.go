@@ -0,0 +1,203 @@
+package connectfour
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/database/repository"
+	"github.com/FredericoBento/HandGame/internal/logger"
+	"github.com/FredericoBento/HandGame/internal/middleware"
+	"github.com/FredericoBento/HandGame/internal/models"
+	"github.com/FredericoBento/HandGame/internal/services"
+	"github.com/FredericoBento/HandGame/internal/services/gameroom"
+	"github.com/FredericoBento/HandGame/internal/services/presence"
+	"github.com/FredericoBento/HandGame/internal/ws"
+	"github.com/gorilla/websocket"
+)
+
+type ConnectFourService struct {
+	Name    string
+	Status  *services.Status
+	Log     *slog.Logger
+	rooms   *gameroom.Manager
+	matches repository.MatchRepository
+	ratings *services.RatingService
+	// recorder records the finished games with matches and ratings
+	recorder *gameroom.MatchRecorder
+	// presence is told who plays where, it is optional
+	presence *presence.Service
+
+	reconnectGrace time.Duration
+}
+
+type ConnectFourServiceOption func(*ConnectFourService)
+
+var (
+	upgrader = websocket.Upgrader{
+		CheckOrigin:     func(r *http.Request) bool { return true },
+		ReadBufferSize:  512,
+		WriteBufferSize: 512,
+	}
+
+	events = gameroom.Events{
+		Create:           EventTypeCreateGame,
+		Join:             EventTypeJoinGame,
+		Spectate:         EventTypeSpectateGame,
+		SpectatorsUpdate: EventTypeSpectatorsUpdate,
+		FindMatch:        EventTypeFindMatch,
+		CancelFindMatch:  EventTypeCancelFindMatch,
+		Countdown:        EventTypeReconnectCountdown,
+		Chat:             EventTypePlayerSendMessage,
+		ChatHistory:      EventTypeChatHistory,
+	}
+
+	duelEvents = gameroom.DuelEvents{
+		Joined:             EventTypeJoinedGame,
+		OtherPlayerJoined:  EventTypeOtherPlayerJoined,
+		StateUpdate:        EventTypeStateUpdate,
+		PlayerDisconnected: EventTypePlayerDisconnected,
+		PlayerReconnected:  EventTypePlayerReconnected,
+		Play:               EventTypeDropDisc,
+		Moved:              EventTypeDiscDropped,
+		Tie:                EventTypeTie,
+		Victory:            EventTypeVictory,
+		Defeat:             EventTypeDefeat,
+		Forfeit:            EventTypeForfeit,
+	}
+)
+
+const (
+	default_reconnect_grace = 30 * time.Second
+)
+
+// WithMatchRepository makes the service record every finished game
+func WithMatchRepository(repo repository.MatchRepository) ConnectFourServiceOption {
+	return func(s *ConnectFourService) {
+		s.matches = repo
+	}
+}
+
+// WithRatingService makes the service update the ratings of the players of
+// every finished game
+func WithRatingService(ratings *services.RatingService) ConnectFourServiceOption {
+	return func(s *ConnectFourService) {
+		s.ratings = ratings
+	}
+}
+
+// WithPresence makes the service tell the lobby who plays where and which
+// rooms have a free seat
+func WithPresence(p *presence.Service) ConnectFourServiceOption {
+	return func(s *ConnectFourService) {
+		s.presence = p
+	}
+}
+
+// WithReconnectGrace sets how long a player that dropped has to come back
+// before the game is given to its opponent
+func WithReconnectGrace(grace time.Duration) ConnectFourServiceOption {
+	return func(s *ConnectFourService) {
+		s.reconnectGrace = grace
+	}
+}
+
+func NewConnectFourService(opts ...ConnectFourServiceOption) *ConnectFourService {
+	lo, err := logger.NewServiceLogger("ConnectFourService", "", true)
+	if err != nil {
+		lo = slog.Default()
+	}
+	service := &ConnectFourService{
+		Name:   "ConnectFourService",
+		Status: services.NewStatus(),
+		Log:    lo,
+
+		reconnectGrace: default_reconnect_grace,
+	}
+	for _, option := range opts {
+		option(service)
+	}
+	service.recorder = &gameroom.MatchRecorder{
+		Game:    models.GameConnectFour,
+		Matches: service.matches,
+		Ratings: service.ratings,
+		Log:     lo,
+	}
+	service.rooms = gameroom.NewManager(gameroom.Config{
+		Game:           models.GameConnectFour,
+		MaxPlayers:     2,
+		ReconnectGrace: service.reconnectGrace,
+		Events:         events,
+		NewEngine:      service.newEngine,
+		Ratings:        service.ratings,
+		Presence:       service.presence,
+	}, lo)
+	service.rooms.Run()
+	return service
+}
+
+// ReadMessageHandler hands the event over to the rooms, the rules of the
+// game are in state.go
+func (s *ConnectFourService) ReadMessageHandler(client *ws.Client, event ws.Event) {
+	s.rooms.ReadMessageHandler(client, event)
+}
+
+func (s *ConnectFourService) HandleWebSocketConnection() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, isLogged := middleware.GetUserFromContext(r)
+		if !isLogged {
+			s.Log.Error("Error User not logged:")
+			return
+		}
+
+		conn, err := ws.Accept(&upgrader, w, r)
+		if err != nil {
+			s.Log.Error("Error upgrading to WebSocket: " + err.Error())
+			return
+		}
+
+		client := ws.NewClient(conn, user.Username)
+		s.rooms.Register(client)
+
+		go client.ReadPump(s.rooms.Hub, s.ReadMessageHandler)
+		go client.WritePump()
+	}
+}
+
+func (s *ConnectFourService) Start() error {
+	s.Status.SetActive()
+	s.Log.Info(s.Name + " Started")
+	return nil
+}
+
+func (s *ConnectFourService) Stop() error {
+	s.Status.SetInactive()
+	s.Log.Warn(s.Name + " Stopped")
+	return nil
+}
+
+func (s *ConnectFourService) Resume() error {
+	s.Status.SetActive()
+	s.Log.Info(s.Name + " Resumed")
+	return nil
+}
+
+func (s *ConnectFourService) GetStatus() services.StatusChecker {
+	return s.Status
+}
+
+func (s *ConnectFourService) GetRoute() string {
+	return "/connectfour"
+}
+
+func (s *ConnectFourService) GetName() string {
+	return s.Name
+}
+
+func (s *ConnectFourService) GetLogs() ([]logger.PrettyLogs, error) {
+	logs, err := logger.GetServiceLogs(s.Name)
+	if err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
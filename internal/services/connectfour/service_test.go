@@ -0,0 +1,168 @@
+package connectfour
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/mock"
+	"github.com/FredericoBento/HandGame/internal/models"
+	"github.com/FredericoBento/HandGame/internal/roomtest"
+	"github.com/FredericoBento/HandGame/internal/services/gameroom"
+	"github.com/FredericoBento/HandGame/internal/ws"
+)
+
+// waitForDisc waits for a disc of the player number to drop, the events of
+// the room may come in any order so the other discs are skipped. It returns
+// the row the disc stopped at
+func waitForDisc(t *testing.T, client *ws.Client, value int) int {
+	type Data struct {
+		Row   int `json:"row"`
+		Value int `json:"value"`
+	}
+	for {
+		data := Data{}
+		if err := json.Unmarshal(roomtest.WaitForEvent(t, client, EventTypeDiscDropped).Data, &data); err != nil {
+			t.Fatal(err)
+		}
+		if data.Value == value {
+			return data.Row
+		}
+	}
+}
+
+func startGame(t *testing.T, s *ConnectFourService) (string, *ws.Client, *ws.Client) {
+	p1 := roomtest.NewClient(s.rooms, "p1")
+	p2 := roomtest.NewClient(s.rooms, "p2")
+	roomtest.Send(t, s.rooms, p1, EventTypeCreateGame, nil)
+	code := roomtest.WaitForEvent(t, p1, EventTypeJoinedGame).RoomCode
+	roomtest.Send(t, s.rooms, p2, EventTypeJoinGame, gameroom.EventDataCode{Code: code})
+	roomtest.WaitForEvent(t, p2, EventTypeJoinedGame)
+	return code, p1, p2
+}
+
+func TestPlayToVictory(t *testing.T) {
+	s := NewConnectFourService()
+	_, p1, p2 := startGame(t, s)
+
+	roomtest.Send(t, s.rooms, p2, EventTypeDropDisc, EventDataDrop{Col: 0})
+	if event := roomtest.WaitForError(t, p2); event.Error == nil || event.Error.Code != ws.ErrorCodeNotYourTurn {
+		t.Errorf("expected %s but got: %+v", ws.ErrorCodeNotYourTurn, event.Error)
+	}
+	roomtest.Send(t, s.rooms, p1, EventTypeDropDisc, EventDataDrop{Col: board_columns})
+	if event := roomtest.WaitForError(t, p1); event.Error == nil || event.Error.Code != ws.ErrorCodeOutOfBounds || event.Error.Field != "col" {
+		t.Errorf("expected %s on col but got: %+v", ws.ErrorCodeOutOfBounds, event.Error)
+	}
+
+	// p1 stacks up column 0 while p2 plays column 1
+	for i := range 3 {
+		roomtest.Send(t, s.rooms, p1, EventTypeDropDisc, EventDataDrop{Col: 0})
+		waitForDisc(t, p2, 1)
+		roomtest.Send(t, s.rooms, p2, EventTypeDropDisc, EventDataDrop{Col: 1})
+		if row := waitForDisc(t, p1, 2); row != board_rows-1-i {
+			t.Errorf("expected the disc of p2 at row %d but got: %d", board_rows-1-i, row)
+		}
+	}
+	roomtest.Send(t, s.rooms, p1, EventTypeDropDisc, EventDataDrop{Col: 0})
+	roomtest.WaitForEvent(t, p2, EventTypeDefeat)
+	event := roomtest.WaitForEvent(t, p1, EventTypeVictory)
+	type Data struct {
+		Winner int    `json:"winner"`
+		Line   []Cell `json:"line"`
+	}
+	data := Data{}
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Winner != 1 || len(data.Line) != discs_to_win {
+		t.Errorf("expected p1 to win with a line of four but got: %+v", data)
+	}
+}
+
+func TestVictoryIsSentOnceRecorded(t *testing.T) {
+	matches := &mock.MockMatchRepository{}
+	s := NewConnectFourService(WithMatchRepository(matches))
+	_, p1, p2 := startGame(t, s)
+
+	for range 3 {
+		roomtest.Send(t, s.rooms, p1, EventTypeDropDisc, EventDataDrop{Col: 0})
+		waitForDisc(t, p2, 1)
+		roomtest.Send(t, s.rooms, p2, EventTypeDropDisc, EventDataDrop{Col: 1})
+		waitForDisc(t, p1, 2)
+	}
+	roomtest.Send(t, s.rooms, p1, EventTypeDropDisc, EventDataDrop{Col: 0})
+	roomtest.WaitForEvent(t, p1, EventTypeVictory)
+	created := matches.GetCreated()
+	if len(created) != 1 || created[0].Participants[0].Result != models.MatchResultWin {
+		t.Fatalf("expected the win of p1 to be recorded but got: %+v", created)
+	}
+
+	// The next game starts once the result is out, p1 opens it again
+	roomtest.Send(t, s.rooms, p1, EventTypeDropDisc, EventDataDrop{Col: 3})
+	if row := waitForDisc(t, p2, 1); row != board_rows-1 {
+		t.Errorf("expected a new board but the disc stopped at row: %d", row)
+	}
+}
+
+func TestGameRunsOnceBothPlayersJoin(t *testing.T) {
+	s := NewConnectFourService()
+	p1 := roomtest.NewClient(s.rooms, "p1")
+	p2 := roomtest.NewClient(s.rooms, "p2")
+	roomtest.Send(t, s.rooms, p1, EventTypeCreateGame, nil)
+	code := roomtest.WaitForEvent(t, p1, EventTypeJoinedGame).RoomCode
+
+	roomtest.Send(t, s.rooms, p1, EventTypeDropDisc, EventDataDrop{Col: 0})
+	if event := roomtest.WaitForError(t, p1); event.Error == nil || event.Error.Code != ws.ErrorCodeGameNotRunning {
+		t.Errorf("expected %s but got: %+v", ws.ErrorCodeGameNotRunning, event.Error)
+	}
+
+	roomtest.Send(t, s.rooms, p2, EventTypeJoinGame, gameroom.EventDataCode{Code: code})
+	state := GameState{}
+	if err := json.Unmarshal(roomtest.WaitForEvent(t, p2, EventTypeJoinedGame).Data, &state); err != nil {
+		t.Fatal(err)
+	}
+	if state.Status != gameroom.DuelStatusRunning {
+		t.Errorf("expected the game to be running but got: %d", state.Status)
+	}
+}
+
+func TestReconnectWithinGrace(t *testing.T) {
+	s := NewConnectFourService(WithReconnectGrace(time.Minute))
+	code, p1, p2 := startGame(t, s)
+	roomtest.Send(t, s.rooms, p1, EventTypeDropDisc, EventDataDrop{Col: 3})
+	waitForDisc(t, p2, 1)
+
+	s.rooms.Hub.Unregister <- p2
+	roomtest.WaitForEvent(t, p1, EventTypeReconnectCountdown)
+
+	p2 = roomtest.NewClient(s.rooms, "p2")
+	roomtest.Send(t, s.rooms, p2, EventTypeJoinGame, gameroom.EventDataCode{Code: code})
+	event := roomtest.WaitForEvent(t, p2, EventTypeStateUpdate)
+	state := GameState{}
+	if err := json.Unmarshal(event.Data, &state); err != nil {
+		t.Fatal(err)
+	}
+	if state.Board[board_rows-1][3] != 1 || state.Turn != 1 {
+		t.Errorf("expected the game to be kept but got: %+v", state)
+	}
+	roomtest.WaitForEvent(t, p1, EventTypePlayerReconnected)
+}
+
+func TestForfeitAfterGrace(t *testing.T) {
+	s := NewConnectFourService(WithReconnectGrace(50 * time.Millisecond))
+	_, p1, p2 := startGame(t, s)
+
+	s.rooms.Hub.Unregister <- p2
+	event := roomtest.WaitForEvent(t, p1, EventTypeForfeit)
+	type Data struct {
+		Winner    int    `json:"winner"`
+		Forfeited string `json:"forfeited"`
+	}
+	data := Data{}
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Winner != 1 || data.Forfeited != "p2" {
+		t.Errorf("expected p1 to win by forfeit but got: %+v", data)
+	}
+}
@@ -0,0 +1,120 @@
+package connectfour
+
+import (
+	"encoding/json"
+
+	"github.com/FredericoBento/HandGame/internal/services/gameroom"
+	"github.com/FredericoBento/HandGame/internal/ws"
+)
+
+const (
+	board_rows    = 6
+	board_columns = 7
+	// discs_to_win in a row, column or diagonal
+	discs_to_win = 4
+)
+
+// Cell aliases gameroom.Cell
+type Cell = gameroom.Cell
+
+// GameState is the board of a game, the players and the score are kept by
+// the gameroom.Duel playing it
+type GameState struct {
+	gameroom.DuelState
+	// Board holds the player number of the disc in each cell, 0 when empty
+	Board [board_rows][board_columns]int `json:"board"`
+}
+
+var (
+	ErrInvalidColumn = ws.NewError(ws.ErrorCodeOutOfBounds, "Invalid column to drop a disc in").WithField("col")
+	ErrColumnFull    = ws.NewError(ws.ErrorCodeColumnFull, "This column is already full").WithField("col")
+)
+
+func NewGameState(code string) *GameState {
+	return &GameState{
+		DuelState: gameroom.NewDuelState(code),
+	}
+}
+
+// Play drops the disc of the player in the column of the event, see
+// gameroom.DuelRules
+func (state *GameState) Play(player_num int, event *ws.Event) (gameroom.DuelMove, error) {
+	drop := EventDataDrop{}
+	if err := json.Unmarshal(event.Data, &drop); err != nil {
+		return gameroom.DuelMove{}, ErrCouldNotPlay
+	}
+	row, err := state.Drop(player_num, drop.Col)
+	if err != nil {
+		return gameroom.DuelMove{}, err
+	}
+	line, _ := state.CheckWin(row, drop.Col)
+	return gameroom.DuelMove{
+		Row:  row,
+		Col:  drop.Col,
+		Line: line,
+		Full: state.Full(),
+		Data: EventDataDiscDropped{Row: row, Col: drop.Col, Value: player_num},
+	}, nil
+}
+
+// Drop lets the disc of the player fall down the column, it returns the row
+// the disc stopped at
+func (state *GameState) Drop(player_num int, col int) (int, error) {
+	if col < 0 || col >= board_columns {
+		return -1, ErrInvalidColumn
+	}
+	for row := board_rows - 1; row >= 0; row-- {
+		if state.Board[row][col] == 0 {
+			state.Board[row][col] = player_num
+			return row, nil
+		}
+	}
+	return -1, ErrColumnFull
+}
+
+// CheckWin looks for a line of discs_to_win through the disc at row and col,
+// the last one dropped. It returns the cells of the line and the player that
+// made it, or no cells and 0
+func (state *GameState) CheckWin(row int, col int) ([]Cell, int) {
+	player_num := state.Board[row][col]
+	if player_num == 0 {
+		return nil, 0
+	}
+
+	directions := [4][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}}
+	for _, d := range directions {
+		line := []Cell{{Row: row, Col: col}}
+		// Walk both ways from the disc while the discs are the same
+		for _, sign := range []int{1, -1} {
+			r, c := row+sign*d[0], col+sign*d[1]
+			for inBoard(r, c) && state.Board[r][c] == player_num {
+				line = append(line, Cell{Row: r, Col: c})
+				r, c = r+sign*d[0], c+sign*d[1]
+			}
+		}
+		if len(line) >= discs_to_win {
+			return line, player_num
+		}
+	}
+	return nil, 0
+}
+
+// Full tells if the board has no room for another disc, the game is a tie
+// then unless it was won. The top row fills up last
+func (state *GameState) Full() bool {
+	for col := range state.Board[0] {
+		if state.Board[0][col] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func inBoard(row int, col int) bool {
+	return row >= 0 && row < board_rows && col >= 0 && col < board_columns
+}
+
+// Clear empties the board for the next game, see gameroom.DuelRules
+func (state *GameState) Clear() {
+	state.Board = [board_rows][board_columns]int{}
+}
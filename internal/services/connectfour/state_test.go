@@ -0,0 +1,116 @@
+package connectfour
+
+import (
+	"testing"
+
+	"github.com/FredericoBento/HandGame/internal/services/gameroom"
+)
+
+func newTestState() *GameState {
+	state := NewGameState("abcd")
+	state.Player1 = gameroom.NewDuelPlayer("p1")
+	state.Player2 = gameroom.NewDuelPlayer("p2")
+	return state
+}
+
+func TestDropFallsToTheBottom(t *testing.T) {
+	state := newTestState()
+	for i, expected := range []int{5, 4, 3, 2, 1, 0} {
+		row, err := state.Drop(i%2+1, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if row != expected {
+			t.Errorf("expected disc %d at row %d but got: %d", i, expected, row)
+		}
+	}
+	if _, err := state.Drop(1, 3); err != ErrColumnFull {
+		t.Errorf("expected ErrColumnFull but got: %v", err)
+	}
+	for _, col := range []int{-1, board_columns} {
+		if _, err := state.Drop(1, col); err != ErrInvalidColumn {
+			t.Errorf("expected ErrInvalidColumn for column %d but got: %v", col, err)
+		}
+	}
+}
+
+func TestCheckWin(t *testing.T) {
+	tests := []struct {
+		name string
+		// drops are the columns of player 1, player 2 drops in filler
+		drops  []int
+		filler int
+	}{
+		{"Row", []int{0, 1, 2, 3}, 6},
+		{"Column", []int{2, 2, 2, 2}, 6},
+		{"Diagonal", []int{0, 1, 1, 2, 2, 2, 3, 3, 3, 3}, -1},
+		{"AntiDiagonal", []int{6, 5, 5, 4, 4, 4, 3, 3, 3, 3}, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := newTestState()
+			var line []Cell
+			winner := 0
+			for _, col := range tt.drops {
+				row, err := state.Drop(1, col)
+				if err != nil {
+					t.Fatal(err)
+				}
+				line, winner = state.CheckWin(row, col)
+				if winner != 0 {
+					break
+				}
+				if tt.filler >= 0 {
+					if _, err := state.Drop(2, tt.filler); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			if winner != 1 {
+				t.Errorf("expected player 1 to win but got: %d", winner)
+			}
+			if len(line) != discs_to_win {
+				t.Errorf("expected a line of %d but got: %v", discs_to_win, line)
+			}
+		})
+	}
+}
+
+func TestCheckWinNeedsFour(t *testing.T) {
+	state := newTestState()
+	for _, col := range []int{0, 1, 2} {
+		row, _ := state.Drop(1, col)
+		if _, winner := state.CheckWin(row, col); winner != 0 {
+			t.Errorf("expected no winner with three discs but got: %d", winner)
+		}
+	}
+	// A gap breaks the line
+	row, _ := state.Drop(1, 4)
+	if _, winner := state.CheckWin(row, 4); winner != 0 {
+		t.Errorf("expected no winner over a gap but got: %d", winner)
+	}
+}
+
+func TestCheckWinTie(t *testing.T) {
+	state := newTestState()
+	// Columns paired up in blocks of three never line up four
+	pattern := [board_columns]int{1, 1, 2, 2, 1, 1, 2}
+	for row := board_rows - 1; row >= 0; row-- {
+		for col := range board_columns {
+			player := pattern[col]
+			if (board_rows-1-row)/3%2 == 1 {
+				player = 3 - player
+			}
+			state.Board[row][col] = player
+		}
+	}
+	state.Board[0][6] = 0
+	row, _ := state.Drop(1, 6)
+	if _, winner := state.CheckWin(row, 6); winner != 0 {
+		t.Fatalf("expected no winner but got: %d", winner)
+	}
+	if !state.Full() {
+		t.Errorf("expected a tie on a full board but got: %+v", state.Board)
+	}
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/database/repository"
+	"github.com/FredericoBento/HandGame/internal/logger"
+	"github.com/FredericoBento/HandGame/internal/models"
+)
+
+var (
+	ErrCouldNotCreateFriendLogger = errors.New("could not create friend_service logger")
+	ErrCannotFriendYourself       = errors.New("cannot send a friend request to yourself")
+	ErrAlreadyFriends             = errors.New("users are already friends")
+	ErrFriendRequestPending       = errors.New("friend request already sent")
+	ErrNoFriendRequest            = errors.New("there is no friend request to accept")
+	ErrCouldNotGetFriends         = errors.New("could not retrieve friends from repository")
+	ErrCouldNotSaveFriendship     = errors.New("could not save friendship")
+)
+
+type FriendService struct {
+	repo        repository.FriendRepository
+	userService *UserService
+	log         *slog.Logger
+}
+
+func NewFriendService(repo repository.FriendRepository, userService *UserService) *FriendService {
+	lo, err := logger.NewServiceLogger("FriendService", "", false)
+	if err != nil {
+		slog.Error(ErrCouldNotCreateFriendLogger.Error() + " " + err.Error())
+		lo = slog.Default()
+	}
+
+	return &FriendService{
+		repo:        repo,
+		userService: userService,
+		log:         lo,
+	}
+}
+
+// Request asks to for a friendship, when to had already asked from the two
+// become friends right away
+func (fs *FriendService) Request(ctx context.Context, from string, to string) (*models.Friendship, error) {
+	if from == to {
+		return nil, ErrCannotFriendYourself
+	}
+
+	exists, err := fs.userService.UserExists(ctx, to)
+	if err != nil {
+		return nil, ErrUserExistsFailed
+	}
+	if !exists {
+		return nil, ErrCouldNotFindUser
+	}
+
+	friendship, err := fs.repo.Get(ctx, from, to)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		fs.log.Error(err.Error())
+		return nil, ErrCouldNotGetFriends
+	}
+	if friendship != nil {
+		switch {
+		case friendship.Status == models.FriendshipAccepted:
+			return nil, ErrAlreadyFriends
+		case friendship.Requester == from:
+			return nil, ErrFriendRequestPending
+		}
+		if err := fs.Accept(ctx, from, to); err != nil {
+			return nil, err
+		}
+		friendship.Status = models.FriendshipAccepted
+		return friendship, nil
+	}
+
+	friendship = &models.Friendship{
+		Requester: from,
+		Addressee: to,
+		Status:    models.FriendshipPending,
+		CreatedAt: time.Now(),
+	}
+	if err := fs.repo.Create(ctx, friendship); err != nil {
+		fs.log.Error(err.Error())
+		return nil, ErrCouldNotSaveFriendship
+	}
+	return friendship, nil
+}
+
+// Accept accepts the pending request that requester sent to username
+func (fs *FriendService) Accept(ctx context.Context, username string, requester string) error {
+	err := fs.repo.Accept(ctx, requester, username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNoFriendRequest
+	}
+	if err != nil {
+		fs.log.Error(err.Error())
+		return ErrCouldNotSaveFriendship
+	}
+	return nil
+}
+
+// Remove ends the friendship between the two users, it also declines or
+// cancels a pending request
+func (fs *FriendService) Remove(ctx context.Context, username string, other string) error {
+	if err := fs.repo.Delete(ctx, username, other); err != nil {
+		fs.log.Error(err.Error())
+		return ErrCouldNotSaveFriendship
+	}
+	return nil
+}
+
+// List returns the friends of the user along with the pending requests it
+// sent or received
+func (fs *FriendService) List(ctx context.Context, username string) ([]models.Friend, error) {
+	friendships, err := fs.repo.GetByUsername(ctx, username)
+	if err != nil {
+		fs.log.Error(err.Error())
+		return nil, ErrCouldNotGetFriends
+	}
+
+	friends := make([]models.Friend, 0, len(friendships))
+	for _, friendship := range friendships {
+		friends = append(friends, models.Friend{
+			Username: friendship.Other(username),
+			Status:   friendship.Status,
+			Incoming: friendship.Status == models.FriendshipPending && friendship.Addressee == username,
+		})
+	}
+	return friends, nil
+}
+
+func (fs *FriendService) AreFriends(ctx context.Context, username string, other string) (bool, error) {
+	friendship, err := fs.repo.Get(ctx, username, other)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		fs.log.Error(err.Error())
+		return false, ErrCouldNotGetFriends
+	}
+	return friendship.Status == models.FriendshipAccepted, nil
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/mock"
+	"github.com/FredericoBento/HandGame/internal/models"
+)
+
+func newTestFriendService(userExists bool) *FriendService {
+	users := &mock.MockUserRepository{GetByUsernameResult: &models.User{}}
+	if !userExists {
+		users = &mock.MockUserRepository{GetByUsernameError: sql.ErrNoRows}
+	}
+	return NewFriendService(mock.NewMockFriendRepository(), NewUserService(users, time.Minute))
+}
+
+func TestFriendRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		userExists  bool
+		from, to    string
+		expectedErr error
+	}{
+		{"Yourself", true, "alice", "alice", ErrCannotFriendYourself},
+		{"UnknownUser", false, "alice", "nobody", ErrCouldNotFindUser},
+		{"Sent", true, "alice", "bob", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := newTestFriendService(tt.userExists)
+			_, err := fs.Request(context.TODO(), tt.from, tt.to)
+			if err != tt.expectedErr {
+				t.Errorf("expected %v but got: %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestFriendRequestAccept(t *testing.T) {
+	fs := newTestFriendService(true)
+	ctx := context.TODO()
+
+	if _, err := fs.Request(ctx, "alice", "bob"); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if _, err := fs.Request(ctx, "alice", "bob"); err != ErrFriendRequestPending {
+		t.Errorf("expected %v but got: %v", ErrFriendRequestPending, err)
+	}
+	if err := fs.Accept(ctx, "alice", "bob"); err != ErrNoFriendRequest {
+		t.Errorf("expected the requester to not be able to accept but got: %v", err)
+	}
+
+	friends, _ := fs.List(ctx, "bob")
+	if len(friends) != 1 || !friends[0].Incoming || friends[0].Username != "alice" {
+		t.Fatalf("expected an incoming request from alice but got: %+v", friends)
+	}
+
+	if err := fs.Accept(ctx, "bob", "alice"); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if ok, _ := fs.AreFriends(ctx, "alice", "bob"); !ok {
+		t.Errorf("expected alice and bob to be friends")
+	}
+	if _, err := fs.Request(ctx, "bob", "alice"); err != ErrAlreadyFriends {
+		t.Errorf("expected %v but got: %v", ErrAlreadyFriends, err)
+	}
+
+	if err := fs.Remove(ctx, "bob", "alice"); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if ok, _ := fs.AreFriends(ctx, "alice", "bob"); ok {
+		t.Errorf("expected alice and bob to no longer be friends")
+	}
+}
+
+func TestFriendRequestBothWays(t *testing.T) {
+	fs := newTestFriendService(true)
+	ctx := context.TODO()
+
+	fs.Request(ctx, "alice", "bob")
+	friendship, err := fs.Request(ctx, "bob", "alice")
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if friendship.Status != models.FriendshipAccepted {
+		t.Errorf("expected asking back to accept the request but got: %v", friendship.Status)
+	}
+}
@@ -0,0 +1,60 @@
+package gameroom
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/services/chat"
+	"github.com/FredericoBento/HandGame/internal/utils"
+	"github.com/FredericoBento/HandGame/internal/ws"
+)
+
+// EventDataChat is sent by a member to talk to the room, the room gets a
+// chat.Message back in an event of the same type
+type EventDataChat struct {
+	Text string `json:"text"`
+}
+
+// EventDataChatHistory is the scrollback of the room sent to whoever joins it
+type EventDataChatHistory struct {
+	Code     string         `json:"code"`
+	Messages []chat.Message `json:"messages"`
+}
+
+// say sends the message of a player or a spectator to the whole room, it is
+// kept for the ones that join later
+func (room *Room) say(event *ws.Event, client *ws.Client) {
+	data := EventDataChat{}
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		room.SendError(event, client, ErrInvalidData)
+		return
+	}
+	message, err := room.moderator.Check(client.Username, data.Text, time.Now())
+	if err != nil {
+		room.SendError(event, client, err)
+		return
+	}
+	room.chat.Add(message)
+
+	messageEvent := ws.NewEvent(room.events.Chat, room.Code)
+	messageEvent.Data, err = utils.EncodeJSON(message)
+	if err != nil {
+		room.Log.Error(err.Error())
+		return
+	}
+	room.SendAll(&messageEvent)
+}
+
+func (room *Room) sendChatHistory(client *ws.Client) {
+	event := ws.NewEvent(room.events.ChatHistory, room.Code)
+	data, err := utils.EncodeJSON(EventDataChatHistory{
+		Code:     room.Code,
+		Messages: room.chat.Messages(),
+	})
+	if err != nil {
+		room.Log.Error(err.Error())
+		return
+	}
+	event.Data = data
+	room.Reply(client, &event)
+}
@@ -0,0 +1,507 @@
+package gameroom
+
+import (
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/models"
+	"github.com/FredericoBento/HandGame/internal/services"
+	"github.com/FredericoBento/HandGame/internal/ws"
+)
+
+// Duel is an Engine for two players taking turns on a board. It seats the
+// players and takes them back, gives the game to the opponent of a player
+// that forfeits, records the matches and starts a new game after each one.
+// The game only adds its moves and how they win through DuelRules
+type Duel struct {
+	State *DuelState
+	// Rules is the whole state of the game as well, it is what joining
+	// players and spectators get
+	Rules  DuelRules
+	Events DuelEvents
+	// Matches is optional, the games are not recorded without it
+	Matches *MatchRecorder
+	// Bot takes the second seat along with the first one in games against
+	// the computer, it moves BotDelay after the player so it sees its own
+	// move first
+	Bot      DuelBot
+	BotDelay time.Duration
+
+	// games counts the games started, results coming back from the database
+	// only start the next game if no other was started meanwhile
+	games int
+}
+
+// DuelRules are the moves of the game played by a Duel, they are called from
+// the room goroutine
+type DuelRules interface {
+	// Play makes the move the event asks for as the player number whose turn
+	// it is
+	Play(player int, event *ws.Event) (DuelMove, error)
+	// Clear empties the board for the next game
+	Clear()
+}
+
+// DuelBot is a computer player, it plays as player 2
+type DuelBot interface {
+	Name() string
+	// Play makes the move of the bot as DuelRules.Play does for the players
+	Play() (DuelMove, error)
+}
+
+// DuelMove is a move that was made, the cell it took and how it left the
+// game. Line is the cells that won the game with it, Full is set when the
+// board has no room left. Data is sent to the room when the game goes on
+type DuelMove struct {
+	Row  int
+	Col  int
+	Line []Cell
+	Full bool
+	Data any
+}
+
+// DuelEvents are the event types a Duel sends, Play is the one the players
+// move with
+type DuelEvents struct {
+	Joined             ws.EventType
+	OtherPlayerJoined  ws.EventType
+	StateUpdate        ws.EventType
+	PlayerDisconnected ws.EventType
+	PlayerReconnected  ws.EventType
+	Play               ws.EventType
+	Moved              ws.EventType
+	Tie                ws.EventType
+	Victory            ws.EventType
+	Defeat             ws.EventType
+	Forfeit            ws.EventType
+}
+
+type DuelPlayer struct {
+	Username  string `json:"username"`
+	Connected bool   `json:"connected"`
+	Wins      int    `json:"wins"`
+}
+
+// Cell is a place of the board, row 0 is the top one
+type Cell struct {
+	Row int `json:"row"`
+	Col int `json:"col"`
+}
+
+// DuelState is the state every Duel keeps, the games embed it in theirs
+type DuelState struct {
+	Code    string      `json:"code"`
+	Player1 *DuelPlayer `json:"player1"`
+	Player2 *DuelPlayer `json:"player2"`
+	Turn    int         `json:"current_turn"` //odd number player2 even player1
+	Status  DuelStatus  `json:"status"`
+	Ties    int         `json:"ties"`
+	Winner  int         `json:"winner"`
+}
+
+type DuelStatus int
+
+const (
+	DuelStatusPaused   DuelStatus = 0
+	DuelStatusRunning  DuelStatus = 1
+	DuelStatusFinished DuelStatus = 2
+)
+
+// EventDataDuelFinish is the last move of a game that was won, Line is the
+// cells that won it
+type EventDataDuelFinish struct {
+	Winner  int                              `json:"winner"`
+	Player1 *DuelPlayer                      `json:"player1"`
+	Player2 *DuelPlayer                      `json:"player2"`
+	Row     int                              `json:"row"`
+	Col     int                              `json:"col"`
+	Value   int                              `json:"value"`
+	Line    []Cell                           `json:"line"`
+	Ratings map[string]services.RatingChange `json:"ratings,omitempty"`
+}
+
+type EventDataDuelForfeit struct {
+	Winner    int                              `json:"winner"`
+	Forfeited string                           `json:"forfeited"`
+	Player1   *DuelPlayer                      `json:"player1"`
+	Player2   *DuelPlayer                      `json:"player2"`
+	Ratings   map[string]services.RatingChange `json:"ratings,omitempty"`
+}
+
+type EventDataDuelTie struct {
+	Tie     int                              `json:"ties"`
+	Row     int                              `json:"row"`
+	Col     int                              `json:"col"`
+	Value   int                              `json:"value"`
+	Ratings map[string]services.RatingChange `json:"ratings,omitempty"`
+}
+
+var (
+	ErrUnknownEvent   = ws.NewError(ws.ErrorCodeUnknownEvent, "Unknown event received")
+	ErrCouldNotJoin   = ws.NewError(ws.ErrorCodeRoomFull, "Could not join game")
+	ErrGameNotRunning = ws.NewError(ws.ErrorCodeGameNotRunning, "The game is not running")
+	ErrNotYourTurn    = ws.NewError(ws.ErrorCodeNotYourTurn, "Not your turn")
+	ErrNotAPlayer     = ws.NewError(ws.ErrorCodeNotAPlayer, "Only the players can play")
+)
+
+func NewDuelState(code string) DuelState {
+	return DuelState{
+		Code:   code,
+		Status: DuelStatusPaused,
+	}
+}
+
+func NewDuelPlayer(username string) *DuelPlayer {
+	return &DuelPlayer{
+		Username:  username,
+		Connected: true,
+	}
+}
+
+// Player returns the player with the number, 1 or 2
+func (state *DuelState) Player(number int) *DuelPlayer {
+	if number == 1 {
+		return state.Player1
+	}
+	return state.Player2
+}
+
+// Mover is the number of the player whose turn it is
+func (state *DuelState) Mover() int {
+	if state.Turn%2 != 0 {
+		return 2
+	}
+	return 1
+}
+
+// players returns the number of the player with the username, the player and
+// its opponent
+func (state *DuelState) players(username string) (int, *DuelPlayer, *DuelPlayer) {
+	switch {
+	case state.Player1 != nil && state.Player1.Username == username:
+		return 1, state.Player1, state.Player2
+	case state.Player2 != nil && state.Player2.Username == username:
+		return 2, state.Player2, state.Player1
+	}
+	return 0, nil, nil
+}
+
+func (state *DuelState) removePlayer(username string) {
+	switch {
+	case state.Player1 != nil && state.Player1.Username == username:
+		state.Player1 = nil
+	case state.Player2 != nil && state.Player2.Username == username:
+		state.Player2 = nil
+	}
+}
+
+// copyPlayers returns copies of the players as they are now, for results
+// sent once the state may have moved on
+func (state *DuelState) copyPlayers() (*DuelPlayer, *DuelPlayer) {
+	var player1, player2 *DuelPlayer
+	if state.Player1 != nil {
+		p := *state.Player1
+		player1 = &p
+	}
+	if state.Player2 != nil {
+		p := *state.Player2
+		player2 = &p
+	}
+	return player1, player2
+}
+
+// match builds the match of a finished game, it returns false if a player is
+// missing
+func (state *DuelState) match() (*models.Match, bool) {
+	if state.Status != DuelStatusFinished || state.Player1 == nil || state.Player2 == nil {
+		return nil, false
+	}
+
+	result1, result2 := models.MatchResultTie, models.MatchResultTie
+	switch state.Winner {
+	case 1:
+		result1, result2 = models.MatchResultWin, models.MatchResultLoss
+	case 2:
+		result1, result2 = models.MatchResultLoss, models.MatchResultWin
+	}
+
+	return &models.Match{
+		RoomCode:   state.Code,
+		FinishedAt: time.Now(),
+		Participants: []models.MatchParticipant{
+			{Username: state.Player1.Username, Score: state.Player1.Wins, Result: result1},
+			{Username: state.Player2.Username, Score: state.Player2.Wins, Result: result2},
+		},
+	}, true
+}
+
+// OnJoin takes a free player slot for the client or gives back the one it had
+func (d *Duel) OnJoin(room *Room, client *ws.Client) error {
+	state := d.State
+	switch {
+	case state.Player1 == nil:
+		state.Player1 = NewDuelPlayer(client.Username)
+		if d.Bot != nil {
+			if !room.TakeSeat(d.Bot.Name()) {
+				state.Player1 = nil
+				return ErrCouldNotJoin
+			}
+			state.Player2 = NewDuelPlayer(d.Bot.Name())
+		}
+	case state.Player1.Username == client.Username:
+		return d.reconnect(room, state.Player1, state.Player2, client)
+	case state.Player2 == nil:
+		state.Player2 = NewDuelPlayer(client.Username)
+		if err := room.Send(d.Events.OtherPlayerJoined, state.Player2, state.Player1.Username); err != nil {
+			return err
+		}
+	case state.Player2.Username == client.Username:
+		return d.reconnect(room, state.Player2, state.Player1, client)
+	default:
+		return ErrCouldNotJoin
+	}
+	// Moves are taken once both seats are
+	if state.Player1 != nil && state.Player2 != nil {
+		state.Status = DuelStatusRunning
+	}
+
+	event, err := room.NewEvent(d.Events.Joined, d.Rules)
+	if err != nil {
+		return err
+	}
+	room.Reply(client, event)
+	return nil
+}
+
+// OnLeave keeps the slot of the player so it can come back, the other player
+// is told about it
+func (d *Duel) OnLeave(room *Room, client *ws.Client) {
+	_, left, other := d.State.players(client.Username)
+	if left == nil {
+		return
+	}
+	left.Connected = false
+	if other == nil || !other.Connected {
+		return
+	}
+	if err := room.Send(d.Events.PlayerDisconnected, left, other.Username); err != nil {
+		room.Log.Error(err.Error())
+	}
+}
+
+// OnForfeit gives the game to the opponent of a player that did not come
+// back, the slot is freed for someone else to take
+func (d *Duel) OnForfeit(room *Room, username string) {
+	_, left, other := d.State.players(username)
+	if left == nil {
+		return
+	}
+	state := d.State
+	// A game that just finished is not given away, its result is on its way
+	if other != nil && other.Connected && state.Status == DuelStatusRunning {
+		other.Wins += 1
+		state.Status = DuelStatusFinished
+		state.Winner, _, _ = state.players(other.Username)
+		player1, player2 := state.copyPlayers()
+		data := EventDataDuelForfeit{Winner: state.Winner, Forfeited: username, Player1: player1, Player2: player2}
+		d.record(room, func(ratings map[string]services.RatingChange) {
+			data.Ratings = ratings
+			if err := room.Send(d.Events.Forfeit, data); err != nil {
+				room.Log.Error("Could not encode json when broadcasting forfeit")
+			}
+		})
+	}
+	state.removePlayer(username)
+	// The bot does not wait alone for someone else to join
+	if d.Bot != nil && username != d.Bot.Name() {
+		state.removePlayer(d.Bot.Name())
+		room.FreeSeat(d.Bot.Name())
+	}
+	d.restart(true)
+	state.Status = DuelStatusPaused
+}
+
+func (d *Duel) OnEvent(room *Room, client *ws.Client, event *ws.Event) error {
+	if event.Type != d.Events.Play {
+		return ErrUnknownEvent
+	}
+	state := d.State
+	player, _, _ := state.players(client.Username)
+	if player == 0 {
+		return ErrNotAPlayer
+	}
+	// The game waits for the second player, and for a new one after a forfeit
+	if state.Status != DuelStatusRunning {
+		return ErrGameNotRunning
+	}
+	if player != state.Mover() {
+		return ErrNotYourTurn
+	}
+	move, err := d.Rules.Play(player, event)
+	if err != nil {
+		return err
+	}
+	d.played(room, player, move)
+	return nil
+}
+
+func (d *Duel) Snapshot() any {
+	return d.Rules
+}
+
+func (d *Duel) Tick(room *Room, now time.Time) {}
+
+func (d *Duel) OnClose(room *Room) {}
+
+// reconnect gives the player the events it missed, or the whole state if
+// they are no longer kept
+func (d *Duel) reconnect(room *Room, player *DuelPlayer, other *DuelPlayer, client *ws.Client) error {
+	player.Connected = true
+	if !room.Resume(client) {
+		event, err := room.NewEvent(d.Events.StateUpdate, d.Rules)
+		if err != nil {
+			return err
+		}
+		room.Reply(client, event)
+	}
+
+	// Another tab of a player that never left, nobody needs to know
+	if other == nil || len(room.Connections(client.Username)) > 1 {
+		return nil
+	}
+	return room.Send(d.Events.PlayerReconnected, player, other.Username)
+}
+
+// played tells the room about the move of the player. A game it ended is
+// recorded, the result is sent and a new game started once the ratings are
+// back. The bot moves next if it is its turn
+func (d *Duel) played(room *Room, player int, move DuelMove) {
+	state := d.State
+	switch {
+	case move.Line != nil:
+		state.Player(player).Wins += 1
+		state.Status = DuelStatusFinished
+		state.Winner = player
+		player1, player2 := state.copyPlayers()
+		data := EventDataDuelFinish{Winner: player, Player1: player1, Player2: player2,
+			Row: move.Row, Col: move.Col, Value: player, Line: move.Line}
+		game := d.games
+		d.record(room, func(ratings map[string]services.RatingChange) {
+			data.Ratings = ratings
+			d.sendFinish(room, data)
+			d.restartAfter(room, game)
+		})
+	case move.Full:
+		state.Ties += 1
+		state.Status = DuelStatusFinished
+		state.Winner = 0
+		data := EventDataDuelTie{Tie: state.Ties, Row: move.Row, Col: move.Col, Value: player}
+		game := d.games
+		d.record(room, func(ratings map[string]services.RatingChange) {
+			data.Ratings = ratings
+			if err := room.Send(d.Events.Tie, data); err != nil {
+				room.Log.Error("Could not encode json when broadcasting tied game")
+			}
+			d.restartAfter(room, game)
+		})
+	default:
+		state.Turn += 1
+		if err := room.Send(d.Events.Moved, move.Data); err != nil {
+			room.Log.Error(err.Error())
+		}
+		d.scheduleBot(room)
+	}
+}
+
+// restartAfter starts the game after the one numbered game, unless it was
+// started already
+func (d *Duel) restartAfter(room *Room, game int) {
+	if d.games != game {
+		return
+	}
+	d.restart(false)
+	d.scheduleBot(room)
+}
+
+// restart starts a new game, the score of the players is kept unless
+// resetScoreBoard
+func (d *Duel) restart(resetScoreBoard bool) {
+	state := d.State
+	d.games += 1
+	d.Rules.Clear()
+	state.Winner = 0
+	state.Turn = 0
+	if resetScoreBoard {
+		if state.Player1 != nil {
+			state.Player1.Wins = 0
+		}
+		if state.Player2 != nil {
+			state.Player2.Wins = 0
+		}
+		state.Ties = 0
+	}
+	state.Status = DuelStatusRunning
+}
+
+// record records the game that just finished in the background, done is
+// posted back to the room with the rating changes of its players. The room
+// goes on meanwhile, done is called at once if there is nothing to record.
+// Games against the bot are recorded but leave the ratings as they are
+func (d *Duel) record(room *Room, done func(ratings map[string]services.RatingChange)) {
+	match, ok := d.State.match()
+	if d.Matches == nil || !ok {
+		done(nil)
+		return
+	}
+	rated := d.Bot == nil
+	go func() {
+		ratings := d.Matches.Record(match, rated)
+		room.Post(func() {
+			done(ratings)
+		})
+	}()
+}
+
+// scheduleBot makes the bot move after a short pause when it is its turn
+func (d *Duel) scheduleBot(room *Room) {
+	if d.Bot == nil || d.State.Status != DuelStatusRunning || d.State.Mover() != 2 {
+		return
+	}
+	time.AfterFunc(d.BotDelay, func() {
+		room.Post(func() {
+			d.botPlay(room)
+		})
+	})
+}
+
+func (d *Duel) botPlay(room *Room) {
+	state := d.State
+	// The player may have forfeited meanwhile
+	if state.Status != DuelStatusRunning || state.Mover() != 2 || state.Player2 == nil || state.Player2.Username != d.Bot.Name() {
+		return
+	}
+	move, err := d.Bot.Play()
+	if err != nil {
+		room.Log.Error("Bot could not play: " + err.Error())
+		return
+	}
+	d.played(room, 2, move)
+}
+
+func (d *Duel) sendFinish(room *Room, data EventDataDuelFinish) {
+	victoryEvent, err := room.NewEvent(d.Events.Victory, data)
+	if err != nil {
+		room.Log.Error("Could not encode json when broadcasting game finished")
+		return
+	}
+	defeatEvent := *victoryEvent
+	defeatEvent.Type = d.Events.Defeat
+
+	winner, loser := data.Player1, data.Player2
+	if data.Winner == 2 {
+		winner, loser = data.Player2, data.Player1
+	}
+	room.SendTo(winner.Username, victoryEvent)
+	room.SendTo(loser.Username, &defeatEvent)
+	// Spectators are told who won through the victory event
+	room.SendAll(victoryEvent, winner.Username, loser.Username)
+}
@@ -0,0 +1,83 @@
+package gameroom
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/services"
+	"github.com/FredericoBento/HandGame/internal/services/chat"
+	"github.com/FredericoBento/HandGame/internal/services/presence"
+	"github.com/FredericoBento/HandGame/internal/ws"
+)
+
+// Engine holds the rules and the state of the game played in one room. The
+// manager calls it from the room goroutine, one method at a time, so it
+// needs no locks of its own
+type Engine interface {
+	// OnJoin is called once the client took a seat of the room, a player
+	// coming back gets the seat it had. It is called as well for every other
+	// connection of a player already in, see ws.Room.Connections. Returning
+	// an error turns it away
+	OnJoin(room *Room, client *ws.Client) error
+	// OnLeave is called after a player left the room, its seat is kept for
+	// Config.ReconnectGrace unless the engine frees it
+	OnLeave(room *Room, client *ws.Client)
+	// OnForfeit is called when a player that left did not come back in
+	// time, its seat is freed right after
+	OnForfeit(room *Room, username string)
+	// OnEvent gets every event of a player that is not about joining or
+	// leaving rooms, the error returned is sent back to the player
+	OnEvent(room *Room, client *ws.Client, event *ws.Event) error
+	// Snapshot is the state of the game as sent to spectators
+	Snapshot() any
+	// Tick is called every Config.TickInterval while the room is open
+	Tick(room *Room, now time.Time)
+	// OnClose is called once when the room is closed
+	OnClose(room *Room)
+}
+
+// EngineFactory creates the engine of a new room, options is the data of the
+// event that asked for the room, it is empty for rooms made by matchmaking
+type EngineFactory func(room *Room, options json.RawMessage) (Engine, error)
+
+// Events are the event types a game uses for the messages the manager
+// handles itself, every one of them must be set
+type Events struct {
+	Create           ws.EventType
+	Join             ws.EventType
+	Spectate         ws.EventType
+	SpectatorsUpdate ws.EventType
+	FindMatch        ws.EventType
+	CancelFindMatch  ws.EventType
+	// Countdown carries EventDataCountdown to the room while a player is away
+	Countdown ws.EventType
+	// Chat carries EventDataChat from a member and the chat.Message back to
+	// the room, ChatHistory carries EventDataChatHistory to whoever joins
+	Chat        ws.EventType
+	ChatHistory ws.EventType
+}
+
+type Config struct {
+	Game string
+	// MaxPlayers is the number of seats of a room, spectators take none
+	MaxPlayers int
+	CodeLength int
+	// TickInterval makes the rooms call Engine.Tick, rooms do not tick when
+	// it is zero
+	TickInterval time.Duration
+	// ReconnectGrace is how long the seat of a player that dropped is kept
+	// for it to come back, the game is forfeited after that. Players leaving
+	// for another room or a zero grace forfeit at once
+	ReconnectGrace time.Duration
+	Events         Events
+	NewEngine      EngineFactory
+	// Ratings is optional, matchmaking pairs everyone as a new player
+	// without it
+	Ratings *services.RatingService
+	// Chat checks the messages of every room, the default chat.Moderator is
+	// used without it
+	Chat *chat.Moderator
+	// Presence is optional, it is told who plays where and which rooms have
+	// a free seat
+	Presence *presence.Service
+}
@@ -0,0 +1,408 @@
+package gameroom
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"github.com/FredericoBento/HandGame/internal/services/chat"
+	"github.com/FredericoBento/HandGame/internal/services/matchmaking"
+	"github.com/FredericoBento/HandGame/internal/utils"
+	"github.com/FredericoBento/HandGame/internal/ws"
+)
+
+const (
+	default_code_length = 4
+)
+
+var (
+	ErrInternal          = ws.NewError(ws.ErrorCodeInternal, "A internal server error has occured, try again later")
+	ErrInvalidData       = ws.NewError(ws.ErrorCodeInvalidData, "Could not read the event")
+	ErrInvalidCode       = ws.NewError(ws.ErrorCodeRoomNotFound, "Invalid Code, Room does not exists").WithField("code")
+	ErrAlreadyInRoom     = ws.NewError(ws.ErrorCodeAlreadyInRoom, "You are already in a room")
+	ErrSpectatorCantPlay = ws.NewError(ws.ErrorCodeNotAPlayer, "Spectators can not play")
+)
+
+// EventDataCreateRoom is optional, rooms allow spectators unless told otherwise
+type EventDataCreateRoom struct {
+	AllowSpectators *bool `json:"allow_spectators,omitempty"`
+}
+
+type EventDataCode struct {
+	Code string `json:"code"`
+}
+
+// EventDataJoin is sent to join a room, LastSeq is the sequence number of the
+// last event a returning player got, see Room.Resume
+type EventDataJoin struct {
+	Code    string `json:"code"`
+	LastSeq uint64 `json:"last_seq,omitempty"`
+}
+
+type EventDataSpectate struct {
+	Code       string `json:"code"`
+	State      any    `json:"state"`
+	Spectators int    `json:"spectators"`
+}
+
+type EventDataSpectators struct {
+	Code       string `json:"code"`
+	Spectators int    `json:"spectators"`
+}
+
+// EventDataCountdown is sent to the room while a player is away, Seconds is
+// what is left before the game is forfeited
+type EventDataCountdown struct {
+	Code     string `json:"code"`
+	Username string `json:"username"`
+	Seconds  int    `json:"seconds"`
+}
+
+// Manager runs the rooms of one game. It handles the hub, joining, leaving,
+// spectating and matchmaking, and hands every other event of a player to the
+// engine of its room. The hub goroutine owns the clients and the rooms map,
+// each room goroutine owns its members and its engine
+type Manager struct {
+	Hub    *ws.Hub
+	Log    *slog.Logger
+	rooms  map[string]*Room
+	config Config
+	queue  *matchmaking.Queue
+}
+
+func NewManager(config Config, log *slog.Logger) *Manager {
+	if config.CodeLength <= 0 {
+		config.CodeLength = default_code_length
+	}
+	if log == nil {
+		log = slog.Default()
+	}
+	if config.Chat == nil {
+		config.Chat = chat.NewModerator()
+	}
+	return &Manager{
+		Hub:    ws.NewHub(),
+		Log:    log,
+		rooms:  make(map[string]*Room),
+		config: config,
+		queue:  matchmaking.NewQueue(config.Game),
+	}
+}
+
+// Run starts the hub and the matchmaking goroutines
+func (m *Manager) Run() {
+	go m.Hub.Run(m)
+	go m.queue.Run(m.StartMatch)
+}
+
+func (m *Manager) Register(client *ws.Client) {
+	m.Hub.Register <- client
+}
+
+// Do runs f on the hub goroutine and waits for it
+func (m *Manager) Do(f func()) bool {
+	return m.Hub.Do(f)
+}
+
+// Room returns the open room with the given code, it must be called from the
+// hub goroutine
+func (m *Manager) Room(code string) (*Room, bool) {
+	room, ok := m.rooms[code]
+	return room, ok
+}
+
+// EachRoom runs f on the goroutine of every open room, one room at a time,
+// and waits for them. It must not be called from the hub nor a room goroutine
+func (m *Manager) EachRoom(f func(room *Room)) {
+	m.Do(func() {
+		for _, room := range m.rooms {
+			room.Do(func() {
+				f(room)
+			})
+		}
+	})
+}
+
+func (m *Manager) OnRegister(client *ws.Client) {
+	m.Hub.AddClient(client)
+	if m.config.Presence != nil {
+		m.config.Presence.Connect(client.Username)
+	}
+	m.Log.Info("User " + client.Username + " has connected")
+}
+
+func (m *Manager) OnUnregister(client *ws.Client) {
+	m.Log.Info("User " + client.Username + " has disconnected")
+	m.Disconnect(client)
+}
+
+// ReadMessageHandler hands the event over to the hub goroutine, pings are
+// answered straight away as they touch nothing shared
+func (m *Manager) ReadMessageHandler(client *ws.Client, event ws.Event) {
+	if event.Type == ws.EventTypePing {
+		ws.HandleEventPing(&event, client)
+		return
+	}
+	m.Hub.Do(func() {
+		m.handleEvent(client, &event)
+	})
+}
+
+func (m *Manager) handleEvent(client *ws.Client, event *ws.Event) {
+	events := m.config.Events
+	switch event.Type {
+	case events.Create:
+		m.HandleEventCreate(event, client)
+	case events.Join:
+		m.HandleEventJoin(event, client)
+	case events.Spectate:
+		m.HandleEventSpectate(event, client)
+	case events.FindMatch:
+		m.HandleEventFindMatch(event, client)
+	case events.CancelFindMatch:
+		m.HandleEventCancelFindMatch(event, client)
+	default:
+		m.handleRoomEvent(event, client)
+	}
+}
+
+func (m *Manager) handleRoomEvent(event *ws.Event, client *ws.Client) {
+	room, ok := m.rooms[client.RoomCode]
+	if !ok {
+		m.Log.Error("Unknown event received", "type", event.Type)
+		return
+	}
+	if event.Type == m.config.Events.Chat {
+		room.Do(func() {
+			room.say(event, client)
+		})
+		return
+	}
+	if client.Spectating {
+		m.SendError(event, client, ErrSpectatorCantPlay)
+		return
+	}
+	room.Do(func() {
+		if err := room.Engine.OnEvent(room, client, event); err != nil {
+			room.SendError(event, client, err)
+		}
+	})
+}
+
+func (m *Manager) HandleEventCreate(event *ws.Event, client *ws.Client) {
+	options := EventDataCreateRoom{}
+	if len(event.Data) > 0 {
+		if err := json.Unmarshal(event.Data, &options); err != nil {
+			m.SendError(event, client, ErrInvalidData)
+			return
+		}
+	}
+	room, err := m.openRoom(options, event.Data)
+	if err != nil {
+		m.Log.Error("Could not create room: " + err.Error())
+		// Options the engine refused are told apart, anything else is ours
+		var eventErr *ws.Error
+		if !errors.As(err, &eventErr) {
+			eventErr = ErrInternal
+		}
+		m.SendError(event, client, eventErr)
+		return
+	}
+	if !m.join(event, client, room, 0) {
+		// Nobody else knows the code yet, only the creator could use it
+		m.closeRoom(room)
+	}
+}
+
+func (m *Manager) HandleEventJoin(event *ws.Event, client *ws.Client) {
+	data := EventDataJoin{}
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		m.SendError(event, client, ErrInvalidData)
+		return
+	}
+	room, ok := m.rooms[data.Code]
+	if !ok {
+		m.SendError(event, client, ErrInvalidCode)
+		return
+	}
+	m.join(event, client, room, data.LastSeq)
+}
+
+func (m *Manager) HandleEventSpectate(event *ws.Event, client *ws.Client) {
+	m.queue.Leave(client.Username)
+	if client.RoomCode != "" && !client.Spectating {
+		m.SendError(event, client, ErrAlreadyInRoom)
+		return
+	}
+	data := EventDataCode{}
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		m.SendError(event, client, ErrInvalidData)
+		return
+	}
+	room, ok := m.rooms[data.Code]
+	if !ok {
+		m.SendError(event, client, ErrInvalidCode)
+		return
+	}
+
+	m.stopSpectating(client)
+	ok = room.Do(func() {
+		room.spectate(event, client)
+	})
+	if !ok {
+		m.SendError(event, client, ErrInvalidCode)
+	}
+	m.trackActivity(client.Username)
+}
+
+// Disconnect takes the client out of the queue and of its room, it is called
+// by the hub goroutine once the connection is gone
+func (m *Manager) Disconnect(client *ws.Client) {
+	m.queue.LeaveClient(client)
+	if client.Spectating {
+		m.stopSpectating(client)
+	} else {
+		m.leaveRoom(client, true)
+	}
+	// Events the rooms still send to the client are dropped from now on
+	m.Hub.RemoveClient(client)
+	client.Close()
+	if m.config.Presence != nil {
+		m.trackActivity(client.Username)
+		m.config.Presence.Disconnect(client.Username)
+	}
+}
+
+func (m *Manager) SendError(event *ws.Event, client *ws.Client, err error) {
+	sendError(m.Log, event, client, err)
+}
+
+func (m *Manager) openRoom(options EventDataCreateRoom, data json.RawMessage) (*Room, error) {
+	room := newRoom(m.uniqueCode(), m.config.MaxPlayers, m.config.Events, m.Log)
+	room.grace = m.config.ReconnectGrace
+	room.moderator = m.config.Chat
+	room.presence = m.config.Presence
+	room.game = m.config.Game
+	room.onEmpty = func() {
+		m.Hub.Post(func() {
+			m.closeIfEmpty(room)
+		})
+	}
+	if options.AllowSpectators != nil {
+		room.AllowSpectators = *options.AllowSpectators
+	}
+	engine, err := m.config.NewEngine(room, data)
+	if err != nil {
+		return nil, err
+	}
+	room.Engine = engine
+	m.rooms[room.Code] = room
+	m.Hub.Rooms[room.Code] = room.Room
+	room.start(m.config.TickInterval)
+	m.Log.Info("Room opened", "code", room.Code)
+	return room, nil
+}
+
+// join returns true if the client took a seat of the room, lastSeq is where
+// a returning player may resume from
+func (m *Manager) join(event *ws.Event, client *ws.Client, room *Room, lastSeq uint64) bool {
+	if client.RoomCode == room.Code && !client.Spectating {
+		m.SendError(event, client, ErrAlreadyInRoom)
+		return false
+	}
+	m.queue.Leave(client.Username)
+	m.stopSpectating(client)
+	m.leaveRoom(client, false)
+
+	joined := false
+	ok := room.Do(func() {
+		joined = room.join(event, client, lastSeq)
+	})
+	if !ok {
+		m.SendError(event, client, ErrInvalidCode)
+	}
+	m.trackActivity(client.Username)
+	return joined
+}
+
+// leaveRoom takes a player out of its room, the room is closed if it was the
+// last one playing. The player is waited for if wait is true, see
+// Config.ReconnectGrace
+func (m *Manager) leaveRoom(client *ws.Client, wait bool) {
+	if client.Spectating {
+		return
+	}
+	room, ok := m.rooms[client.RoomCode]
+	if !ok {
+		return
+	}
+	empty := false
+	room.Do(func() {
+		empty = room.leave(client, wait)
+	})
+	if empty {
+		m.closeRoom(room)
+	}
+}
+
+// stopSpectating takes the client out of the room it is watching, if any
+func (m *Manager) stopSpectating(client *ws.Client) {
+	if !client.Spectating {
+		return
+	}
+	room, ok := m.rooms[client.RoomCode]
+	if !ok {
+		client.RoomCode = ""
+		client.Spectating = false
+		return
+	}
+	room.Do(func() {
+		room.stopSpectating(client)
+	})
+}
+
+// closeIfEmpty closes a room that asked for it unless someone came back to
+// it in the meantime
+func (m *Manager) closeIfEmpty(room *Room) {
+	if m.rooms[room.Code] != room {
+		return
+	}
+	empty := false
+	room.Do(func() {
+		empty = room.empty()
+	})
+	if empty {
+		m.closeRoom(room)
+	}
+}
+
+func (m *Manager) closeRoom(room *Room) {
+	delete(m.rooms, room.Code)
+	delete(m.Hub.Rooms, room.Code)
+	var members []*ws.Client
+	room.Do(func() {
+		members = room.Members()
+		room.close()
+	})
+	room.stop()
+	for _, c := range members {
+		m.trackActivity(c.Username)
+	}
+	m.Log.Info("Room closed", "code", room.Code)
+}
+
+func (m *Manager) uniqueCode() string {
+	code := utils.RandomString(m.config.CodeLength)
+	for {
+		if _, ok := m.rooms[code]; !ok {
+			return code
+		}
+		code = utils.RandomString(m.config.CodeLength)
+	}
+}
+
+// sendError is called from the goroutine owning the client
+func sendError(log *slog.Logger, event *ws.Event, client *ws.Client, err error) {
+	log.Error(err.Error())
+	client.SendErrorEventWithError(event, err)
+}
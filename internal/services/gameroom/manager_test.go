@@ -0,0 +1,509 @@
+package gameroom
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/roomtest"
+	"github.com/FredericoBento/HandGame/internal/services/chat"
+	"github.com/FredericoBento/HandGame/internal/services/presence"
+	"github.com/FredericoBento/HandGame/internal/ws"
+)
+
+const (
+	eventTypeCreate = iota + 1
+	eventTypeJoin
+	eventTypeSpectate
+	eventTypeSpectators
+	eventTypeFindMatch
+	eventTypeCancelFindMatch
+	eventTypeJoined
+	eventTypePlay
+	eventTypeUnknown
+	eventTypeCountdown
+	eventTypeChat
+	eventTypeChatHistory
+)
+
+var errBadMove = errors.New("bad move")
+
+// fakeEngine is only touched from the room goroutine but for ticks and
+// closed, which the tests read from their own
+type fakeEngine struct {
+	joins    []string
+	resumed  []string
+	leaves   []string
+	forfeits []string
+	plays    []string
+	ticks    atomic.Int32
+	closed   chan struct{}
+}
+
+func (e *fakeEngine) OnJoin(room *Room, client *ws.Client) error {
+	if slices.Contains(e.joins, client.Username) && room.Resume(client) {
+		e.resumed = append(e.resumed, client.Username)
+	}
+	e.joins = append(e.joins, client.Username)
+	event := ws.NewEvent(eventTypeJoined, room.Code)
+	room.Reply(client, &event)
+	return nil
+}
+
+func (e *fakeEngine) OnLeave(room *Room, client *ws.Client) {
+	e.leaves = append(e.leaves, client.Username)
+}
+
+func (e *fakeEngine) OnForfeit(room *Room, username string) {
+	e.forfeits = append(e.forfeits, username)
+}
+
+func (e *fakeEngine) OnEvent(room *Room, client *ws.Client, event *ws.Event) error {
+	if event.Type != eventTypePlay {
+		return errBadMove
+	}
+	e.plays = append(e.plays, client.Username)
+	played := ws.NewEvent(eventTypePlay, room.Code)
+	room.SendAll(&played)
+	return nil
+}
+
+func (e *fakeEngine) Snapshot() any {
+	return e.plays
+}
+
+func (e *fakeEngine) Tick(room *Room, now time.Time) {
+	e.ticks.Add(1)
+}
+
+func (e *fakeEngine) OnClose(room *Room) {
+	close(e.closed)
+}
+
+func newTestManager(tickInterval time.Duration, grace time.Duration) (*Manager, chan *fakeEngine) {
+	engines := make(chan *fakeEngine, 8)
+	m := NewManager(Config{
+		Game:           "test",
+		MaxPlayers:     2,
+		TickInterval:   tickInterval,
+		ReconnectGrace: grace,
+		Events: Events{
+			Create:           eventTypeCreate,
+			Join:             eventTypeJoin,
+			Spectate:         eventTypeSpectate,
+			SpectatorsUpdate: eventTypeSpectators,
+			FindMatch:        eventTypeFindMatch,
+			CancelFindMatch:  eventTypeCancelFindMatch,
+			Countdown:        eventTypeCountdown,
+			Chat:             eventTypeChat,
+			ChatHistory:      eventTypeChatHistory,
+		},
+		NewEngine: func(room *Room, options json.RawMessage) (Engine, error) {
+			engine := &fakeEngine{closed: make(chan struct{})}
+			engines <- engine
+			return engine, nil
+		},
+	}, nil)
+	m.Run()
+	return m, engines
+}
+
+// nextEvent skips everything but events of the given type and errors
+func nextEvent(t *testing.T, client *ws.Client, eventType ws.EventType) *ws.Event {
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-client.Event:
+			if event.Type == eventType || event.IsError {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("expected %s to get event %d but got nothing", client.Username, eventType)
+			return nil
+		}
+	}
+}
+
+func openRoom(t *testing.T, m *Manager, p1 *ws.Client, p2 *ws.Client) string {
+	roomtest.Send(t, m, p1, eventTypeCreate, nil)
+	code := nextEvent(t, p1, eventTypeJoined).RoomCode
+	roomtest.Send(t, m, p2, eventTypeJoin, EventDataCode{Code: code})
+	if event := nextEvent(t, p2, eventTypeJoined); event.IsError {
+		t.Fatalf("expected p2 to join but got: %s", event.Data)
+	}
+	return code
+}
+
+// inRoom runs f on the goroutine of the room, it returns false if the room
+// is closed
+func inRoom(m *Manager, code string, f func(room *Room)) bool {
+	found := false
+	m.Do(func() {
+		room, ok := m.Room(code)
+		if !ok {
+			return
+		}
+		found = room.Do(func() {
+			f(room)
+		})
+	})
+	return found
+}
+
+func TestRoomIsFull(t *testing.T) {
+	m, _ := newTestManager(0, 0)
+	p1, p2, p3 := roomtest.NewClient(m, "p1"), roomtest.NewClient(m, "p2"), roomtest.NewClient(m, "p3")
+	code := openRoom(t, m, p1, p2)
+
+	roomtest.Send(t, m, p3, eventTypeJoin, EventDataCode{Code: code})
+	if event := nextEvent(t, p3, eventTypeJoined); !event.IsError {
+		t.Errorf("expected p3 to be turned away but got: %+v", event)
+	}
+}
+
+func TestRejoinKeepsSeat(t *testing.T) {
+	m, engines := newTestManager(0, time.Minute)
+	p1, p2, p3 := roomtest.NewClient(m, "p1"), roomtest.NewClient(m, "p2"), roomtest.NewClient(m, "p3")
+	code := openRoom(t, m, p1, p2)
+	engine := <-engines
+
+	m.Hub.Unregister <- p2
+	roomtest.Send(t, m, p3, eventTypeJoin, EventDataCode{Code: code})
+	if event := nextEvent(t, p3, eventTypeJoined); !event.IsError {
+		t.Errorf("expected the seat of p2 to be kept but p3 got: %+v", event)
+	}
+
+	p2 = roomtest.NewClient(m, "p2")
+	roomtest.Send(t, m, p2, eventTypeJoin, EventDataCode{Code: code})
+	if event := nextEvent(t, p2, eventTypeJoined); event.IsError {
+		t.Fatalf("expected p2 to rejoin but got: %s", event.Data)
+	}
+
+	var joins, leaves, forfeits []string
+	inRoom(m, code, func(room *Room) {
+		joins = append(joins, engine.joins...)
+		leaves = append(leaves, engine.leaves...)
+		forfeits = append(forfeits, engine.forfeits...)
+	})
+	if len(joins) != 3 || len(leaves) != 1 {
+		t.Errorf("expected 3 joins and 1 leave but got: %v, %v", joins, leaves)
+	}
+	if len(forfeits) != 0 {
+		t.Errorf("expected no forfeit but got: %v", forfeits)
+	}
+}
+
+func TestRejoinResumes(t *testing.T) {
+	m, engines := newTestManager(0, time.Minute)
+	p1, p2 := roomtest.NewClient(m, "p1"), roomtest.NewClient(m, "p2")
+	code := openRoom(t, m, p1, p2)
+	engine := <-engines
+
+	roomtest.Send(t, m, p1, eventTypePlay, nil)
+	lastSeq := nextEvent(t, p2, eventTypePlay).Seq
+	m.Hub.Unregister <- p2
+	roomtest.Send(t, m, p1, eventTypePlay, nil)
+	roomtest.Send(t, m, p1, eventTypePlay, nil)
+
+	p2 = roomtest.NewClient(m, "p2")
+	roomtest.Send(t, m, p2, eventTypeJoin, EventDataJoin{Code: code, LastSeq: lastSeq})
+	// The countdown p1 got while p2 was away is not for p2
+	for i := 0; i < 2; i++ {
+		event := nextEvent(t, p2, eventTypePlay)
+		if event.IsError || event.Seq <= lastSeq {
+			t.Fatalf("expected the plays after %d to be replayed in order but got: %+v", lastSeq, event)
+		}
+		lastSeq = event.Seq
+	}
+
+	var resumed []string
+	inRoom(m, code, func(room *Room) {
+		resumed = append(resumed, engine.resumed...)
+	})
+	if len(resumed) != 1 || resumed[0] != "p2" {
+		t.Errorf("expected p2 to resume but got: %v", resumed)
+	}
+}
+
+func TestPlayerInSeveralTabs(t *testing.T) {
+	m, engines := newTestManager(0, time.Minute)
+	p1, p2 := roomtest.NewClient(m, "p1"), roomtest.NewClient(m, "p2")
+	code := openRoom(t, m, p1, p2)
+	engine := <-engines
+
+	tab := roomtest.NewClient(m, "p1")
+	roomtest.Send(t, m, tab, eventTypeJoin, EventDataCode{Code: code})
+	if event := nextEvent(t, tab, eventTypeJoined); event.IsError {
+		t.Fatalf("expected another tab of p1 to join but got: %s", event.Data)
+	}
+
+	roomtest.Send(t, m, p2, eventTypePlay, nil)
+	nextEvent(t, p1, eventTypePlay)
+	nextEvent(t, tab, eventTypePlay)
+
+	m.Hub.Unregister <- p1
+	roomtest.Send(t, m, p2, eventTypePlay, nil)
+	if event := nextEvent(t, tab, eventTypePlay); event.IsError {
+		t.Fatalf("expected the tab left open to keep playing but got: %s", event.Data)
+	}
+
+	var leaves []string
+	var players int
+	inRoom(m, code, func(room *Room) {
+		leaves = append(leaves, engine.leaves...)
+		players = len(room.Clients)
+	})
+	if len(leaves) != 0 || players != 2 {
+		t.Errorf("expected p1 to still be in the room but got: %v leaves and %d players", leaves, players)
+	}
+}
+
+func TestChat(t *testing.T) {
+	m, _ := newTestManager(0, time.Minute)
+	p1, p2, p3 := roomtest.NewClient(m, "p1"), roomtest.NewClient(m, "p2"), roomtest.NewClient(m, "p3")
+	code := openRoom(t, m, p1, p2)
+
+	roomtest.Send(t, m, p1, eventTypeChat, EventDataChat{Text: "good luck"})
+	event := nextEvent(t, p2, eventTypeChat)
+	message := chat.Message{}
+	if err := json.Unmarshal(event.Data, &message); err != nil || event.IsError {
+		t.Fatalf("expected p2 to get the message but got: %s", event.Data)
+	}
+	if message.From != "p1" || message.Text != "good luck" {
+		t.Errorf("expected the message of p1 but got: %+v", message)
+	}
+
+	roomtest.Send(t, m, p3, eventTypeSpectate, EventDataCode{Code: code})
+	event = nextEvent(t, p3, eventTypeChatHistory)
+	history := EventDataChatHistory{}
+	if err := json.Unmarshal(event.Data, &history); err != nil || event.IsError {
+		t.Fatalf("expected p3 to get the scrollback but got: %s", event.Data)
+	}
+	if len(history.Messages) != 1 || history.Messages[0].From != "p1" {
+		t.Errorf("expected the message of p1 in the scrollback but got: %+v", history.Messages)
+	}
+
+	roomtest.Send(t, m, p3, eventTypeChat, EventDataChat{Text: "hello"})
+	if event := nextEvent(t, p1, eventTypeChat); event.IsError {
+		t.Errorf("expected spectators to chat but got: %s", event.Data)
+	}
+}
+
+func TestChatRateLimit(t *testing.T) {
+	m, _ := newTestManager(0, time.Minute)
+	p1, p2 := roomtest.NewClient(m, "p1"), roomtest.NewClient(m, "p2")
+	openRoom(t, m, p1, p2)
+
+	var event *ws.Event
+	for i := 0; i < 10 && (event == nil || !event.IsError); i++ {
+		roomtest.Send(t, m, p1, eventTypeChat, EventDataChat{Text: "spam"})
+		event = nextEvent(t, p1, eventTypeChat)
+	}
+	if !event.IsError {
+		t.Errorf("expected p1 to be rate limited but got: %+v", event)
+	}
+}
+
+func TestForfeitAfterGrace(t *testing.T) {
+	m, engines := newTestManager(0, 50*time.Millisecond)
+	p1, p2, p3 := roomtest.NewClient(m, "p1"), roomtest.NewClient(m, "p2"), roomtest.NewClient(m, "p3")
+	code := openRoom(t, m, p1, p2)
+	engine := <-engines
+
+	m.Hub.Unregister <- p2
+	event := nextEvent(t, p1, eventTypeCountdown)
+	data := EventDataCountdown{}
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Username != "p2" || data.Seconds != 1 {
+		t.Errorf("expected countdown of p2 with 1 second left but got: %+v", data)
+	}
+
+	deadline := time.After(2 * time.Second)
+	for {
+		var forfeits []string
+		inRoom(m, code, func(room *Room) {
+			forfeits = append(forfeits, engine.forfeits...)
+		})
+		if len(forfeits) == 1 && forfeits[0] == "p2" {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("expected p2 to forfeit but got: %v", forfeits)
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	roomtest.Send(t, m, p3, eventTypeJoin, EventDataCode{Code: code})
+	if event := nextEvent(t, p3, eventTypeJoined); event.IsError {
+		t.Errorf("expected p3 to take the seat of p2 but got: %s", event.Data)
+	}
+}
+
+func TestLeavingForAnotherRoomForfeits(t *testing.T) {
+	m, engines := newTestManager(0, time.Minute)
+	p1, p2 := roomtest.NewClient(m, "p1"), roomtest.NewClient(m, "p2")
+	code := openRoom(t, m, p1, p2)
+	engine := <-engines
+
+	roomtest.Send(t, m, p2, eventTypeCreate, nil)
+	nextEvent(t, p2, eventTypeJoined)
+
+	var forfeits []string
+	inRoom(m, code, func(room *Room) {
+		forfeits = append(forfeits, engine.forfeits...)
+	})
+	if len(forfeits) != 1 || forfeits[0] != "p2" {
+		t.Errorf("expected p2 to forfeit at once but got: %v", forfeits)
+	}
+}
+
+func TestRoomClosesAfterGrace(t *testing.T) {
+	m, engines := newTestManager(0, 50*time.Millisecond)
+	p1, p2 := roomtest.NewClient(m, "p1"), roomtest.NewClient(m, "p2")
+	code := openRoom(t, m, p1, p2)
+	engine := <-engines
+
+	m.Hub.Unregister <- p1
+	m.Hub.Unregister <- p2
+	if !inRoom(m, code, func(room *Room) {}) {
+		t.Fatalf("expected room %s to wait for its players", code)
+	}
+
+	select {
+	case <-engine.closed:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected engine to be closed")
+	}
+	if inRoom(m, code, func(room *Room) {}) {
+		t.Errorf("expected room %s to be gone", code)
+	}
+}
+
+func TestRoomClosesWhenEmpty(t *testing.T) {
+	m, engines := newTestManager(0, 0)
+	p1, p2 := roomtest.NewClient(m, "p1"), roomtest.NewClient(m, "p2")
+	code := openRoom(t, m, p1, p2)
+	engine := <-engines
+
+	m.Hub.Unregister <- p1
+	m.Hub.Unregister <- p2
+
+	select {
+	case <-engine.closed:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected engine to be closed")
+	}
+	if inRoom(m, code, func(room *Room) {}) {
+		t.Errorf("expected room %s to be gone", code)
+	}
+}
+
+func TestEventsReachEngine(t *testing.T) {
+	m, engines := newTestManager(0, 0)
+	p1, p2, spectator := roomtest.NewClient(m, "p1"), roomtest.NewClient(m, "p2"), roomtest.NewClient(m, "spectator")
+	code := openRoom(t, m, p1, p2)
+	engine := <-engines
+
+	roomtest.Send(t, m, p1, eventTypePlay, nil)
+	roomtest.Send(t, m, p2, eventTypeUnknown, nil)
+	if event := nextEvent(t, p2, eventTypeJoined); !event.IsError {
+		t.Errorf("expected engine error to reach p2 but got: %+v", event)
+	}
+
+	roomtest.Send(t, m, spectator, eventTypeSpectate, EventDataCode{Code: code})
+	event := nextEvent(t, spectator, eventTypeSpectate)
+	if event.IsError {
+		t.Fatalf("expected spectator to watch but got: %s", event.Data)
+	}
+	data := EventDataSpectate{}
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Code != code || data.Spectators != 1 {
+		t.Errorf("expected snapshot of %s with 1 spectator but got: %+v", code, data)
+	}
+
+	roomtest.Send(t, m, spectator, eventTypePlay, nil)
+	if event := nextEvent(t, spectator, eventTypeJoined); !event.IsError {
+		t.Errorf("expected spectator to be refused but got: %+v", event)
+	}
+
+	var plays []string
+	inRoom(m, code, func(room *Room) {
+		plays = append(plays, engine.plays...)
+	})
+	if len(plays) != 1 || plays[0] != "p1" {
+		t.Errorf("expected only p1 to play but got: %v", plays)
+	}
+}
+
+func TestRoomTicks(t *testing.T) {
+	m, engines := newTestManager(time.Millisecond, 0)
+	p1 := roomtest.NewClient(m, "p1")
+	roomtest.Send(t, m, p1, eventTypeCreate, nil)
+	nextEvent(t, p1, eventTypeJoined)
+	engine := <-engines
+
+	deadline := time.After(2 * time.Second)
+	for engine.ticks.Load() < 3 {
+		select {
+		case <-deadline:
+			t.Fatalf("expected engine to tick but got: %d ticks", engine.ticks.Load())
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+// waitForLobby polls the presence service until ok, the manager reports to
+// it without waiting
+func waitForLobby(t *testing.T, lobby *presence.Service, ok func(presence.Snapshot) bool) presence.Snapshot {
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		snapshot := lobby.Snapshot()
+		if ok(snapshot) {
+			return snapshot
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the lobby to be updated but got: %+v", snapshot)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPresence(t *testing.T) {
+	m, _ := newTestManager(0, time.Minute)
+	lobby := presence.NewService()
+	m.config.Presence = lobby
+	p1, p2 := roomtest.NewClient(m, "p1"), roomtest.NewClient(m, "p2")
+
+	roomtest.Send(t, m, p1, eventTypeCreate, nil)
+	code := nextEvent(t, p1, eventTypeJoined).RoomCode
+	waitForLobby(t, lobby, func(snapshot presence.Snapshot) bool {
+		return len(snapshot.Rooms) == 1 && snapshot.Rooms[0].Code == code && snapshot.Rooms[0].Host == "p1"
+	})
+
+	roomtest.Send(t, m, p2, eventTypeJoin, EventDataCode{Code: code})
+	nextEvent(t, p2, eventTypeJoined)
+	waitForLobby(t, lobby, func(snapshot presence.Snapshot) bool {
+		if len(snapshot.Rooms) != 0 || len(snapshot.Users) != 2 {
+			return false
+		}
+		for _, user := range snapshot.Users {
+			if len(user.Activities) != 1 || user.Activities[0].Code != code {
+				return false
+			}
+		}
+		return true
+	})
+
+	m.Hub.Unregister <- p2
+	waitForLobby(t, lobby, func(snapshot presence.Snapshot) bool {
+		return len(snapshot.Users) == 1 && snapshot.Users[0].Username == "p1"
+	})
+}
@@ -0,0 +1,40 @@
+package gameroom
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/FredericoBento/HandGame/internal/database/repository"
+	"github.com/FredericoBento/HandGame/internal/models"
+	"github.com/FredericoBento/HandGame/internal/services"
+)
+
+// MatchRecorder stores the finished matches of a game and updates the
+// ratings of their players, Matches and Ratings are optional
+type MatchRecorder struct {
+	Game    string
+	Matches repository.MatchRepository
+	Ratings *services.RatingService
+	Log     *slog.Logger
+}
+
+// Record stores the match and, when rated, updates the ratings of its
+// players, the rating changes are returned so they can be sent along with the
+// result. It waits on the database, do not call it from the room goroutine
+func (r *MatchRecorder) Record(match *models.Match, rated bool) map[string]services.RatingChange {
+	match.Game = r.Game
+	if r.Matches != nil {
+		if err := r.Matches.Create(context.Background(), match); err != nil {
+			r.Log.Error("Could not record match: "+err.Error(), "code", match.RoomCode)
+		}
+	}
+	if r.Ratings == nil || !rated {
+		return nil
+	}
+	changes, err := r.Ratings.UpdateRatings(context.Background(), match.Game, match.Participants)
+	if err != nil {
+		r.Log.Error("Could not update ratings: "+err.Error(), "code", match.RoomCode)
+		return nil
+	}
+	return changes
+}
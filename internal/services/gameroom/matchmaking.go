@@ -0,0 +1,106 @@
+package gameroom
+
+import (
+	"context"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/models"
+	"github.com/FredericoBento/HandGame/internal/services/matchmaking"
+	"github.com/FredericoBento/HandGame/internal/utils"
+	"github.com/FredericoBento/HandGame/internal/ws"
+)
+
+type EventDataQueue struct {
+	Queued  bool `json:"queued"`
+	Players int  `json:"players"`
+}
+
+// HandleEventFindMatch puts the client in the matchmaking queue, the room is
+// created by StartMatch once an opponent is found
+func (m *Manager) HandleEventFindMatch(event *ws.Event, client *ws.Client) {
+	if client.RoomCode != "" && !client.Spectating {
+		m.SendError(event, client, ErrAlreadyInRoom)
+		return
+	}
+
+	err := m.queue.Join(client, m.playerRating(client.Username), time.Now())
+	if err != nil {
+		m.SendError(event, client, err)
+		return
+	}
+	m.sendQueueState(client, m.config.Events.FindMatch, true)
+}
+
+func (m *Manager) HandleEventCancelFindMatch(event *ws.Event, client *ws.Client) {
+	m.queue.Leave(client.Username)
+	m.sendQueueState(client, m.config.Events.CancelFindMatch, false)
+}
+
+// StartMatch is called by the matchmaking queue with two players that were
+// paired, the first one creates the room and the second joins it
+func (m *Manager) StartMatch(pair matchmaking.Pair) {
+	m.Hub.Do(func() {
+		m.startMatch(pair)
+	})
+}
+
+func (m *Manager) startMatch(pair matchmaking.Pair) {
+	// Either side may have left or joined another room while being paired,
+	// the other one goes back in the queue keeping its place
+	host, guest := pair[0], pair[1]
+	if !m.isAvailable(host.Client) || !m.isAvailable(guest.Client) {
+		for _, t := range pair {
+			if m.isAvailable(t.Client) {
+				m.queue.Join(t.Client, t.Rating, t.QueuedAt)
+			}
+		}
+		return
+	}
+
+	createEvent := ws.NewSimpleEvent(m.config.Events.Create)
+	m.HandleEventCreate(&createEvent, host.Client)
+	code := host.Client.RoomCode
+	if code == "" {
+		m.Log.Error("Could not create matched room", "host", host.Client.Username)
+		m.queue.Join(guest.Client, guest.Rating, guest.QueuedAt)
+		return
+	}
+
+	joinEvent := ws.NewEvent(m.config.Events.Join, code)
+	data, err := utils.EncodeJSON(EventDataCode{Code: code})
+	if err != nil {
+		m.Log.Error(err.Error())
+		return
+	}
+	joinEvent.Data = data
+	m.HandleEventJoin(&joinEvent, guest.Client)
+	m.Log.Info("Matched players", "code", code, "player1", host.Client.Username, "player2", guest.Client.Username)
+}
+
+func (m *Manager) isAvailable(client *ws.Client) bool {
+	c, ok := m.Hub.Clients[client.ID]
+	return ok && c == client && (client.RoomCode == "" || client.Spectating)
+}
+
+func (m *Manager) playerRating(username string) float64 {
+	if m.config.Ratings == nil {
+		return models.DefaultRating
+	}
+	rating, err := m.config.Ratings.GetRating(context.Background(), username, m.config.Game)
+	if err != nil {
+		m.Log.Error(err.Error())
+		return models.DefaultRating
+	}
+	return rating.Rating
+}
+
+func (m *Manager) sendQueueState(client *ws.Client, eventType ws.EventType, queued bool) {
+	event := ws.NewSimpleEvent(eventType)
+	data, err := utils.EncodeJSON(EventDataQueue{Queued: queued, Players: m.queue.Len()})
+	if err != nil {
+		m.Log.Error(err.Error())
+		return
+	}
+	event.Data = data
+	client.SendEvent(&event)
+}
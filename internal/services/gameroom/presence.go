@@ -0,0 +1,45 @@
+package gameroom
+
+import (
+	"github.com/FredericoBento/HandGame/internal/services/presence"
+)
+
+// trackActivity tells the presence service where the user is in the game,
+// from the rooms its connections are in, a seat wins over spectating. It
+// must be called from the hub goroutine
+func (m *Manager) trackActivity(username string) {
+	if m.config.Presence == nil {
+		return
+	}
+	activity := presence.Activity{Game: m.config.Game}
+	for _, c := range m.Hub.Connections(username) {
+		if c.RoomCode == "" {
+			continue
+		}
+		activity.Code = c.RoomCode
+		activity.Spectating = c.Spectating
+		if !c.Spectating {
+			break
+		}
+	}
+	m.config.Presence.SetActivity(username, activity)
+}
+
+// reportSeats lists the room as joinable while it has players and a free
+// seat, it must be called from the room goroutine
+func (room *Room) reportSeats() {
+	if room.presence == nil {
+		return
+	}
+	if len(room.seats) == 0 || len(room.seats) >= room.MaxClients {
+		room.presence.CloseRoom(room.game, room.Code)
+		return
+	}
+	room.presence.OpenRoom(presence.Room{
+		Game:       room.game,
+		Code:       room.Code,
+		Host:       room.seats[0],
+		Players:    len(room.seats),
+		MaxPlayers: room.MaxClients,
+	})
+}
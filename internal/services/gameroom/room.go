@@ -0,0 +1,382 @@
+package gameroom
+
+import (
+	"log/slog"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/services/chat"
+	"github.com/FredericoBento/HandGame/internal/services/presence"
+	"github.com/FredericoBento/HandGame/internal/utils"
+	"github.com/FredericoBento/HandGame/internal/ws"
+)
+
+// Room is a ws.Room playing a game, everything but Post and Broadcast must be
+// called from the room goroutine, that is from inside the Engine methods or
+// from commands given to Do
+type Room struct {
+	*ws.Room
+	Engine Engine
+	Log    *slog.Logger
+
+	// seats are the usernames that play in the room, a seat is kept while its
+	// player is disconnected so it can come back
+	seats   []string
+	events  Events
+	ticking chan struct{}
+
+	// away are the players that left and may still come back, grace is how
+	// long they are waited for
+	away  map[string]*absence
+	grace time.Duration
+	// onEmpty is called from the room goroutine once the last player that
+	// was waited for did not come back
+	onEmpty func()
+	// resumeFrom is the last sequence number the player joining got, it is
+	// only set during Engine.OnJoin, resumed tells if Resume got it back
+	resumeFrom uint64
+	resumed    bool
+
+	chat      *chat.History
+	moderator *chat.Moderator
+
+	// presence is told about the free seats of the room, if set
+	presence *presence.Service
+	game     string
+}
+
+// absence is a player that left the room, back is closed if it returns
+// before the deadline
+type absence struct {
+	deadline time.Time
+	back     chan struct{}
+}
+
+const (
+	countdown_interval = time.Second
+)
+
+func newRoom(code string, maxPlayers int, events Events, log *slog.Logger) *Room {
+	return &Room{
+		Room:   ws.NewRoom(code, maxPlayers),
+		Log:    log,
+		seats:  make([]string, 0, maxPlayers),
+		events: events,
+		away:   make(map[string]*absence),
+		chat:   chat.NewHistory(0),
+	}
+}
+
+// start runs the room goroutine and the ticker feeding Engine.Tick, if any
+func (room *Room) start(tickInterval time.Duration) {
+	room.Start()
+	if tickInterval <= 0 {
+		return
+	}
+	room.ticking = make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(tickInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				room.Post(func() {
+					room.Engine.Tick(room, now)
+				})
+			case <-room.ticking:
+				return
+			}
+		}
+	}()
+}
+
+func (room *Room) stop() {
+	if room.ticking != nil {
+		close(room.ticking)
+	}
+	room.Stop()
+}
+
+// HasSeat tells if the username plays in the room, connected or not
+func (room *Room) HasSeat(username string) bool {
+	return slices.Contains(room.seats, username)
+}
+
+// Seats returns the usernames playing in the room in the order they sat
+func (room *Room) Seats() []string {
+	return slices.Clone(room.seats)
+}
+
+// FreeSeat lets someone else take the seat of the username, engines call it
+// for players that can not come back
+func (room *Room) FreeSeat(username string) {
+	room.seats = slices.DeleteFunc(room.seats, func(seat string) bool {
+		return seat == username
+	})
+	room.reportSeats()
+}
+
+// TakeSeat seats a player that has no connection, like a bot, it returns
+// false if the room is full. Engines call it from OnJoin
+func (room *Room) TakeSeat(username string) bool {
+	ok, _ := room.takeSeat(username)
+	return ok
+}
+
+// takeSeat returns false if the room is full, taken is true if the seat was
+// not the username's already
+func (room *Room) takeSeat(username string) (ok bool, taken bool) {
+	if room.HasSeat(username) {
+		return true, false
+	}
+	if len(room.seats) >= room.MaxClients {
+		return false, false
+	}
+	room.seats = append(room.seats, username)
+	room.reportSeats()
+	return true, true
+}
+
+// Send sends an event with the data to the usernames, or to the whole room
+// if none are given
+func (room *Room) Send(eventType ws.EventType, data any, usernames ...string) error {
+	event, err := room.NewEvent(eventType, data)
+	if err != nil {
+		return err
+	}
+	if len(usernames) == 0 {
+		room.SendAll(event)
+		return nil
+	}
+	for _, username := range usernames {
+		room.SendTo(username, event)
+	}
+	return nil
+}
+
+// NewEvent makes an event of the room with the data, for engines that send
+// it later or to a single connection
+func (room *Room) NewEvent(eventType ws.EventType, data any) (*ws.Event, error) {
+	event := ws.NewEvent(eventType, room.Code)
+	bytes, err := utils.EncodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	event.Data = bytes
+	return &event, nil
+}
+
+func (room *Room) SendError(event *ws.Event, client *ws.Client, err error) {
+	sendError(room.Log, event, client, err)
+}
+
+// Resume replays to a returning player the events it missed since the last
+// one it got, as it told when joining. It returns false if there is nothing
+// to resume from or the gap is no longer kept, the engine sends the whole
+// state of the game then. It is only meant for Engine.OnJoin
+func (room *Room) Resume(client *ws.Client) bool {
+	if room.resumeFrom == 0 {
+		return false
+	}
+	room.resumed = room.Replay(client, room.resumeFrom)
+	return room.resumed
+}
+
+func (room *Room) join(event *ws.Event, client *ws.Client, lastSeq uint64) bool {
+	ok, taken := room.takeSeat(client.Username)
+	if !ok {
+		room.SendError(event, client, ws.ErrRoomIsFull)
+		return false
+	}
+	err := room.AddClient(client)
+	if err == nil {
+		room.resumeFrom, room.resumed = lastSeq, false
+		err = room.Engine.OnJoin(room, client)
+		room.resumeFrom = 0
+		if err != nil {
+			room.RemoveClient(client)
+		}
+	}
+	if err != nil {
+		if taken {
+			room.FreeSeat(client.Username)
+		}
+		room.SendError(event, client, err)
+		return false
+	}
+	room.returned(client.Username)
+	// A player that resumed got the messages it missed replayed
+	if !room.resumed {
+		room.sendChatHistory(client)
+	}
+	return true
+}
+
+// leave returns true once no player is left in the room nor waited for. A
+// player with other connections to the room is still in. The seat of the
+// player is kept for the grace of the room if wait is true, otherwise the
+// game is forfeited at once
+func (room *Room) leave(client *ws.Client, wait bool) bool {
+	if !room.HasClient(client) {
+		return false
+	}
+	if err := room.RemoveClient(client); err != nil {
+		room.Log.Error(err.Error())
+		return false
+	}
+	if room.HasPlayer(client.Username) {
+		return false
+	}
+	room.Engine.OnLeave(room, client)
+	if room.HasSeat(client.Username) {
+		if wait && room.grace > 0 {
+			room.awaitReturn(client.Username)
+		} else {
+			room.forfeit(client.Username)
+		}
+	}
+	return room.empty()
+}
+
+func (room *Room) empty() bool {
+	return len(room.Clients) == 0 && len(room.away) == 0
+}
+
+// awaitReturn counts down the grace of a player that left, the others are
+// told every second how long is left
+func (room *Room) awaitReturn(username string) {
+	a := &absence{
+		deadline: time.Now().Add(room.grace),
+		back:     make(chan struct{}),
+	}
+	room.away[username] = a
+	room.sendCountdown(username, a)
+
+	go func(grace time.Duration) {
+		ticker := time.NewTicker(countdown_interval)
+		defer ticker.Stop()
+		expired := time.NewTimer(grace)
+		defer expired.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				room.Post(func() {
+					if room.away[username] == a {
+						room.sendCountdown(username, a)
+					}
+				})
+			case <-expired.C:
+				room.Post(func() {
+					if room.away[username] == a {
+						room.expire(username)
+					}
+				})
+				return
+			case <-a.back:
+				return
+			}
+		}
+	}(room.grace)
+}
+
+// returned stops the countdown of a player that came back in time
+func (room *Room) returned(username string) {
+	a, ok := room.away[username]
+	if !ok {
+		return
+	}
+	close(a.back)
+	delete(room.away, username)
+}
+
+func (room *Room) expire(username string) {
+	delete(room.away, username)
+	room.forfeit(username)
+	if room.empty() && room.onEmpty != nil {
+		room.onEmpty()
+	}
+}
+
+func (room *Room) forfeit(username string) {
+	room.Log.Info("Game forfeited", "code", room.Code, "username", username)
+	room.Engine.OnForfeit(room, username)
+	room.FreeSeat(username)
+}
+
+func (room *Room) sendCountdown(username string, a *absence) {
+	seconds := int(math.Ceil(time.Until(a.deadline).Seconds()))
+	event := ws.NewEvent(room.events.Countdown, room.Code)
+	data, err := utils.EncodeJSON(EventDataCountdown{
+		Code:     room.Code,
+		Username: username,
+		Seconds:  max(seconds, 0),
+	})
+	if err != nil {
+		room.Log.Error(err.Error())
+		return
+	}
+	event.Data = data
+	room.SendAll(&event, username)
+}
+
+func (room *Room) spectate(event *ws.Event, client *ws.Client) {
+	err := room.AddSpectator(client)
+	if err != nil {
+		room.SendError(event, client, err)
+		return
+	}
+
+	spectateEvent := ws.NewEvent(room.events.Spectate, room.Code)
+	spectateEvent.Data, err = utils.EncodeJSON(EventDataSpectate{
+		Code:       room.Code,
+		State:      room.Engine.Snapshot(),
+		Spectators: room.SpectatorCount(),
+	})
+	if err != nil {
+		room.RemoveSpectator(client)
+		room.SendError(event, client, ErrInternal)
+		return
+	}
+	room.Reply(client, &spectateEvent)
+	room.sendChatHistory(client)
+	room.sendSpectators()
+}
+
+func (room *Room) stopSpectating(client *ws.Client) {
+	if err := room.RemoveSpectator(client); err != nil {
+		room.Log.Error(err.Error())
+		return
+	}
+	room.sendSpectators()
+}
+
+func (room *Room) sendSpectators() {
+	event := ws.NewEvent(room.events.SpectatorsUpdate, room.Code)
+	data, err := utils.EncodeJSON(EventDataSpectators{Code: room.Code, Spectators: room.SpectatorCount()})
+	if err != nil {
+		room.Log.Error(err.Error())
+		return
+	}
+	event.Data = data
+	room.SendAll(&event)
+}
+
+// close lets every member go and tells the engine about it
+func (room *Room) close() {
+	for username := range room.away {
+		room.returned(username)
+	}
+	for _, c := range room.Spectators {
+		room.RemoveSpectator(c)
+	}
+	for username := range room.Clients {
+		for _, c := range room.Connections(username) {
+			room.RemoveClient(c)
+		}
+	}
+	room.Engine.OnClose(room)
+	if room.presence != nil {
+		room.presence.CloseRoom(room.game, room.Code)
+	}
+}
@@ -0,0 +1,249 @@
+package handgame
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/services/gameroom"
+	"github.com/FredericoBento/HandGame/internal/ws"
+)
+
+// engine plays the match of one room, the rules are in state.go
+type engine struct {
+	state *GameState
+}
+
+func (s *HandGameService) newEngine(room *gameroom.Room, options json.RawMessage) (gameroom.Engine, error) {
+	opts := EventDataCreateGame{}
+	if len(options) > 0 {
+		if err := json.Unmarshal(options, &opts); err != nil {
+			return nil, err
+		}
+	}
+	state, err := NewGameState(room.Code, opts.BestOf)
+	if err != nil {
+		return nil, err
+	}
+	return &engine{state: state}, nil
+}
+
+// OnJoin takes a free player slot for the client or gives back the one it had
+func (e *engine) OnJoin(room *gameroom.Room, client *ws.Client) error {
+	state := e.state
+	if playerNum := state.PlayerNumber(client.Username); playerNum != 0 {
+		return e.reconnect(room, playerNum, client)
+	}
+
+	playerNum, err := state.AddPlayer(client.Username)
+	if err != nil {
+		return err
+	}
+	event, err := e.stateEvent(room, EventTypeJoinedGame, playerNum)
+	if err != nil {
+		state.RemovePlayer(client.Username)
+		return err
+	}
+	room.Reply(client, event)
+
+	if opponent := state.Opponent(playerNum); opponent != nil {
+		if err := room.Send(EventTypeOtherPlayerJoined, state.GetPlayer(playerNum), opponent.Username); err != nil {
+			room.Log.Error(err.Error())
+		}
+	}
+	return nil
+}
+
+// OnLeave keeps the slot of the player so it can come back, the opponent is
+// told about it
+func (e *engine) OnLeave(room *gameroom.Room, client *ws.Client) {
+	state := e.state
+	playerNum := state.PlayerNumber(client.Username)
+	player := state.GetPlayer(playerNum)
+	if player == nil {
+		return
+	}
+	player.Connected = false
+	opponent := state.Opponent(playerNum)
+	if opponent == nil || !opponent.Connected {
+		return
+	}
+	if err := room.Send(EventTypePlayerDisconnected, player, opponent.Username); err != nil {
+		room.Log.Error(err.Error())
+	}
+}
+
+// OnForfeit gives a running match to the opponent of a player that did not
+// come back, the opponent then waits in the room for someone else
+func (e *engine) OnForfeit(room *gameroom.Room, username string) {
+	state := e.state
+	playerNum := state.PlayerNumber(username)
+	data := EventDataForfeit{Forfeited: username, Player1: state.Player1, Player2: state.Player2}
+	given, err := state.Forfeit(playerNum)
+	if err != nil {
+		return
+	}
+	// The scores are taken before the restart clears them
+	var forfeit *ws.Event
+	if given {
+		data.Winner = state.Winner
+		if forfeit, err = room.NewEvent(EventTypeForfeit, data); err != nil {
+			room.Log.Error(err.Error())
+		}
+	}
+	state.Restart()
+	e.sendStates(room)
+	if forfeit != nil {
+		room.SendAll(forfeit)
+	}
+}
+
+func (e *engine) OnEvent(room *gameroom.Room, client *ws.Client, event *ws.Event) error {
+	playerNum := e.state.PlayerNumber(client.Username)
+	if playerNum == 0 {
+		return ErrPlayerNotInMatch
+	}
+	switch event.Type {
+	case EventTypeCommitHand:
+		return e.commit(room, playerNum, event)
+	case EventTypeRequestRematch:
+		return e.rematch(room, playerNum)
+	}
+	return gameroom.ErrUnknownEvent
+}
+
+// Snapshot is the state without the hands of the players
+func (e *engine) Snapshot() any {
+	return EventDataState{GameState: e.state}
+}
+
+func (e *engine) Tick(room *gameroom.Room, now time.Time) {}
+
+func (e *engine) OnClose(room *gameroom.Room) {}
+
+// reconnect gives the player the events it missed, or the whole state if
+// they are no longer kept
+func (e *engine) reconnect(room *gameroom.Room, playerNum int, client *ws.Client) error {
+	state := e.state
+	player := state.GetPlayer(playerNum)
+	player.Connected = true
+	if !room.Resume(client) {
+		event, err := e.stateEvent(room, EventTypeStateUpdate, playerNum)
+		if err != nil {
+			return err
+		}
+		room.Reply(client, event)
+	}
+
+	// Another tab of a player that never left, nobody needs to know
+	opponent := state.Opponent(playerNum)
+	if opponent == nil || len(room.Connections(client.Username)) > 1 {
+		return nil
+	}
+	return room.Send(EventTypePlayerReconnected, player, opponent.Username)
+}
+
+// commit keeps the hand of the player hidden until its opponent picked one
+// too, the round is revealed to the whole room then
+func (e *engine) commit(room *gameroom.Room, playerNum int, event *ws.Event) error {
+	data := EventDataHand{}
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		return gameroom.ErrInvalidData
+	}
+	state := e.state
+	ready, err := state.Commit(playerNum, data.Hand)
+	if err != nil {
+		return err
+	}
+
+	player := state.GetPlayer(playerNum)
+	if err := room.Send(EventTypeHandCommitted, data, player.Username); err != nil {
+		room.Log.Error(err.Error())
+	}
+	if !ready {
+		if err := room.Send(EventTypeOpponentCommitted, player, state.Opponent(playerNum).Username); err != nil {
+			room.Log.Error(err.Error())
+		}
+		return nil
+	}
+
+	round, err := state.Reveal()
+	if err != nil {
+		room.Log.Error(err.Error())
+		return nil
+	}
+	err = room.Send(EventTypeRoundResult, EventDataRoundResult{
+		Round:   round,
+		Player1: state.Player1,
+		Player2: state.Player2,
+		Ties:    state.Ties,
+	})
+	if err != nil {
+		room.Log.Error("Could not encode json when broadcasting round result")
+		return nil
+	}
+	if !state.IsFinished() {
+		return nil
+	}
+	err = room.Send(EventTypeMatchFinished, EventDataMatchFinished{
+		Winner:  state.Winner,
+		Player1: state.Player1,
+		Player2: state.Player2,
+		History: state.History,
+	})
+	if err != nil {
+		room.Log.Error("Could not encode json when broadcasting match finished")
+	}
+	return nil
+}
+
+// rematch restarts the match once both players asked for it
+func (e *engine) rematch(room *gameroom.Room, playerNum int) error {
+	state := e.state
+	restarted, err := state.RequestRematch(playerNum)
+	if err != nil {
+		return err
+	}
+	if !restarted {
+		if err := room.Send(EventTypeRematchRequested, state.GetPlayer(playerNum)); err != nil {
+			room.Log.Error(err.Error())
+		}
+		return nil
+	}
+	e.sendStates(room)
+	return nil
+}
+
+// stateEvent is the state as seen by the player number, with the hand it
+// committed this round
+func (e *engine) stateEvent(room *gameroom.Room, eventType ws.EventType, playerNum int) (*ws.Event, error) {
+	return room.NewEvent(eventType, EventDataState{
+		GameState: e.state,
+		PlayerNum: playerNum,
+		MyHand:    e.state.CommittedHand(playerNum),
+	})
+}
+
+// sendStates sends every player the state as it sees it, spectators see it
+// as neither player
+func (e *engine) sendStates(room *gameroom.Room) {
+	players := make([]string, 0, 2)
+	for playerNum := 1; playerNum <= 2; playerNum++ {
+		player := e.state.GetPlayer(playerNum)
+		if player == nil {
+			continue
+		}
+		event, err := e.stateEvent(room, EventTypeStateUpdate, playerNum)
+		if err != nil {
+			room.Log.Error(err.Error())
+			return
+		}
+		room.SendTo(player.Username, event)
+		players = append(players, player.Username)
+	}
+	event, err := e.stateEvent(room, EventTypeStateUpdate, 0)
+	if err != nil {
+		room.Log.Error(err.Error())
+		return
+	}
+	room.SendAll(event, players...)
+}
@@ -0,0 +1,81 @@
+package handgame
+
+import (
+	"github.com/FredericoBento/HandGame/internal/services/gameroom"
+)
+
+const (
+	EventTypeCreateGame        = 1
+	EventTypeJoinGame          = 2
+	EventTypeJoinedGame        = 22
+	EventTypeOtherPlayerJoined = 23
+
+	EventTypeCommitHand        = 3
+	EventTypeHandCommitted     = 31
+	EventTypeOpponentCommitted = 32
+	EventTypeRoundResult       = 33
+	EventTypeMatchFinished     = 34
+
+	EventTypeRequestRematch   = 35
+	EventTypeRematchRequested = 36
+
+	EventTypePlayerDisconnected = 5
+	EventTypePlayerReconnected  = 6
+
+	EventTypeStateUpdate = 9
+
+	EventTypeFindMatch       = 13
+	EventTypeCancelFindMatch = 14
+
+	EventTypeSpectateGame     = 15
+	EventTypeSpectatorsUpdate = 16
+
+	EventTypeReconnectCountdown = 17
+	EventTypeForfeit            = 18
+
+	EventTypeChatMessage = 37
+	EventTypeChatHistory = 38
+)
+
+// EventDataCreateGame are the options of the create event, the room ones are
+// in gameroom.EventDataCreateRoom
+type EventDataCreateGame struct {
+	gameroom.EventDataCreateRoom
+	BestOf int `json:"best_of"`
+}
+
+type EventDataHand struct {
+	Hand Hand `json:"hand"`
+}
+
+// EventDataState is the game state as seen by one of the players, it carries
+// the hand that player already committed this round so it survives reconnects.
+// Spectators get it with a zero PlayerNum
+type EventDataState struct {
+	*GameState
+	PlayerNum int  `json:"player_num"`
+	MyHand    Hand `json:"my_hand"`
+}
+
+type EventDataRoundResult struct {
+	Round   Round   `json:"round"`
+	Player1 *Player `json:"player1"`
+	Player2 *Player `json:"player2"`
+	Ties    int     `json:"ties"`
+}
+
+type EventDataMatchFinished struct {
+	Winner  int     `json:"winner"`
+	Player1 *Player `json:"player1"`
+	Player2 *Player `json:"player2"`
+	History []Round `json:"history"`
+}
+
+// EventDataForfeit is sent to the room when a player did not come back in
+// time, the match is given to the other one
+type EventDataForfeit struct {
+	Winner    int     `json:"winner"`
+	Forfeited string  `json:"forfeited"`
+	Player1   *Player `json:"player1"`
+	Player2   *Player `json:"player2"`
+}
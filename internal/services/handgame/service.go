@@ -0,0 +1,204 @@
+package handgame
+
+import (
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/logger"
+	"github.com/FredericoBento/HandGame/internal/middleware"
+	"github.com/FredericoBento/HandGame/internal/models"
+	"github.com/FredericoBento/HandGame/internal/services"
+	"github.com/FredericoBento/HandGame/internal/services/gameroom"
+	"github.com/FredericoBento/HandGame/internal/services/presence"
+	"github.com/FredericoBento/HandGame/internal/ws"
+	"github.com/gorilla/websocket"
+)
+
+type HandGameService struct {
+	Name   string
+	Status *services.Status
+	Log    *slog.Logger
+	rooms  *gameroom.Manager
+	// presence is told who plays where, it is optional
+	presence *presence.Service
+
+	reconnectGrace time.Duration
+}
+
+type HandGameServiceOption func(*HandGameService)
+
+// WithPresence makes the service tell the lobby who plays where and which
+// rooms wait for an opponent
+func WithPresence(p *presence.Service) HandGameServiceOption {
+	return func(s *HandGameService) {
+		s.presence = p
+	}
+}
+
+// WithReconnectGrace sets how long a player that dropped has to come back
+// before the match is given to its opponent
+func WithReconnectGrace(grace time.Duration) HandGameServiceOption {
+	return func(s *HandGameService) {
+		s.reconnectGrace = grace
+	}
+}
+
+// RoomInfo is the public view of a room waiting for an opponent
+type RoomInfo struct {
+	Code   string
+	Host   string
+	BestOf int
+}
+
+var (
+	upgrader = websocket.Upgrader{
+		CheckOrigin:     func(r *http.Request) bool { return true },
+		ReadBufferSize:  512,
+		WriteBufferSize: 512,
+	}
+
+	events = gameroom.Events{
+		Create:           EventTypeCreateGame,
+		Join:             EventTypeJoinGame,
+		Spectate:         EventTypeSpectateGame,
+		SpectatorsUpdate: EventTypeSpectatorsUpdate,
+		FindMatch:        EventTypeFindMatch,
+		CancelFindMatch:  EventTypeCancelFindMatch,
+		Countdown:        EventTypeReconnectCountdown,
+		Chat:             EventTypeChatMessage,
+		ChatHistory:      EventTypeChatHistory,
+	}
+)
+
+const (
+	logFileName = "HandgameService"
+
+	default_reconnect_grace = 30 * time.Second
+)
+
+func NewHandGameService(opts ...HandGameServiceOption) *HandGameService {
+	lo, err := logger.NewServiceLogger(logFileName, "", true)
+	if err != nil {
+		lo = slog.Default()
+	}
+	service := &HandGameService{
+		Name:   "HandGameService",
+		Status: services.NewStatus(),
+		Log:    lo,
+
+		reconnectGrace: default_reconnect_grace,
+	}
+	for _, option := range opts {
+		option(service)
+	}
+	service.rooms = gameroom.NewManager(gameroom.Config{
+		Game:           models.GameHandGame,
+		MaxPlayers:     2,
+		ReconnectGrace: service.reconnectGrace,
+		Events:         events,
+		NewEngine:      service.newEngine,
+		Presence:       service.presence,
+	}, lo)
+	service.rooms.Run()
+	return service
+}
+
+// ReadMessageHandler hands the event over to the rooms, the rules of the
+// game are in state.go
+func (s *HandGameService) ReadMessageHandler(client *ws.Client, event ws.Event) {
+	s.rooms.ReadMessageHandler(client, event)
+}
+
+func (s *HandGameService) HandleWebSocketConnection() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, isLogged := middleware.GetUserFromContext(r)
+		if !isLogged {
+			s.Log.Error("Error User not logged:")
+			return
+		}
+
+		conn, err := ws.Accept(&upgrader, w, r)
+		if err != nil {
+			s.Log.Error("Error upgrading to WebSocket: " + err.Error())
+			return
+		}
+
+		client := ws.NewClient(conn, user.Username)
+		s.rooms.Register(client)
+
+		go client.ReadPump(s.rooms.Hub, s.ReadMessageHandler)
+		go client.WritePump()
+	}
+}
+
+// ListOpenRooms returns the rooms still waiting for a second player, filtered
+// by code or host username when search is not empty
+func (s *HandGameService) ListOpenRooms(search string) []RoomInfo {
+	search = strings.ToLower(strings.TrimSpace(search))
+	rooms := make([]RoomInfo, 0)
+	s.rooms.EachRoom(func(room *gameroom.Room) {
+		e, ok := room.Engine.(*engine)
+		if !ok {
+			return
+		}
+		host := e.state.Host()
+		if host == nil || !host.Connected {
+			return
+		}
+		if search != "" &&
+			!strings.Contains(strings.ToLower(room.Code), search) &&
+			!strings.Contains(strings.ToLower(host.Username), search) {
+			return
+		}
+		rooms = append(rooms, RoomInfo{
+			Code:   room.Code,
+			Host:   host.Username,
+			BestOf: e.state.BestOf,
+		})
+	})
+	sort.Slice(rooms, func(i, j int) bool {
+		return rooms[i].Code < rooms[j].Code
+	})
+	return rooms
+}
+
+func (s *HandGameService) Start() error {
+	s.Status.SetActive()
+	s.Log.Info(s.Name + " Started")
+	return nil
+}
+
+func (s *HandGameService) Stop() error {
+	s.Status.SetInactive()
+	s.Log.Warn(s.Name + " Stopped")
+	return nil
+}
+
+func (s *HandGameService) Resume() error {
+	s.Status.SetActive()
+	s.Log.Info(s.Name + " Resumed")
+	return nil
+}
+
+func (s *HandGameService) GetStatus() services.StatusChecker {
+	return s.Status
+}
+
+func (s *HandGameService) GetRoute() string {
+	return "/handgame"
+}
+
+func (s *HandGameService) GetName() string {
+	return s.Name
+}
+
+func (s *HandGameService) GetLogs() ([]logger.PrettyLogs, error) {
+	logs, err := logger.GetServiceLogs(logFileName)
+	if err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
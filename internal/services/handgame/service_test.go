@@ -0,0 +1,135 @@
+package handgame
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/roomtest"
+	"github.com/FredericoBento/HandGame/internal/services/chat"
+	"github.com/FredericoBento/HandGame/internal/services/gameroom"
+	"github.com/FredericoBento/HandGame/internal/ws"
+)
+
+func startGame(t *testing.T, s *HandGameService, bestOf int) (string, *ws.Client, *ws.Client) {
+	p1 := roomtest.NewClient(s.rooms, "p1")
+	p2 := roomtest.NewClient(s.rooms, "p2")
+	roomtest.Send(t, s.rooms, p1, EventTypeCreateGame, EventDataCreateGame{BestOf: bestOf})
+	code := roomtest.WaitForEvent(t, p1, EventTypeJoinedGame).RoomCode
+	roomtest.Send(t, s.rooms, p2, EventTypeJoinGame, gameroom.EventDataCode{Code: code})
+	roomtest.WaitForEvent(t, p2, EventTypeJoinedGame)
+	roomtest.WaitForEvent(t, p1, EventTypeOtherPlayerJoined)
+	return code, p1, p2
+}
+
+func TestPlayMatch(t *testing.T) {
+	s := NewHandGameService()
+	_, p1, p2 := startGame(t, s, 1)
+
+	roomtest.Send(t, s.rooms, p1, EventTypeCommitHand, EventDataHand{Hand: hand_rock})
+	roomtest.WaitForEvent(t, p1, EventTypeHandCommitted)
+	roomtest.WaitForEvent(t, p2, EventTypeOpponentCommitted)
+	roomtest.Send(t, s.rooms, p2, EventTypeCommitHand, EventDataHand{Hand: hand_scissors})
+
+	data := EventDataMatchFinished{}
+	if err := json.Unmarshal(roomtest.WaitForEvent(t, p2, EventTypeMatchFinished).Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Winner != 1 {
+		t.Errorf("expected player 1 to win but got: %d", data.Winner)
+	}
+
+	roomtest.Send(t, s.rooms, p1, EventTypeRequestRematch, nil)
+	roomtest.WaitForEvent(t, p2, EventTypeRematchRequested)
+	roomtest.Send(t, s.rooms, p2, EventTypeRequestRematch, nil)
+
+	state := EventDataState{}
+	if err := json.Unmarshal(roomtest.WaitForEvent(t, p1, EventTypeStateUpdate).Data, &state); err != nil {
+		t.Fatal(err)
+	}
+	if state.PlayerNum != 1 || state.Status != game_status_running {
+		t.Errorf("expected a running match for player 1 but got: %+v", state)
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	s := NewHandGameService()
+	code, p1, p2 := startGame(t, s, 3)
+
+	roomtest.Send(t, s.rooms, p1, EventTypeCommitHand, EventDataHand{Hand: hand_paper})
+	roomtest.WaitForEvent(t, p1, EventTypeHandCommitted)
+
+	s.rooms.Hub.Unregister <- p1
+	roomtest.WaitForEvent(t, p2, EventTypePlayerDisconnected)
+
+	p1 = roomtest.NewClient(s.rooms, "p1")
+	roomtest.Send(t, s.rooms, p1, EventTypeJoinGame, gameroom.EventDataCode{Code: code})
+	roomtest.WaitForEvent(t, p2, EventTypePlayerReconnected)
+
+	state := EventDataState{}
+	if err := json.Unmarshal(roomtest.WaitForEvent(t, p1, EventTypeStateUpdate).Data, &state); err != nil {
+		t.Fatal(err)
+	}
+	if state.PlayerNum != 1 {
+		t.Errorf("expected to come back as player 1 but got: %d", state.PlayerNum)
+	}
+	if state.MyHand != hand_paper {
+		t.Errorf("expected committed hand %d but got: %d", hand_paper, state.MyHand)
+	}
+}
+
+func TestListOpenRooms(t *testing.T) {
+	s := NewHandGameService()
+	host := roomtest.NewClient(s.rooms, "host")
+	roomtest.Send(t, s.rooms, host, EventTypeCreateGame, EventDataCreateGame{BestOf: 5})
+	code := roomtest.WaitForEvent(t, host, EventTypeJoinedGame).RoomCode
+
+	rooms := s.ListOpenRooms("HOS")
+	if len(rooms) != 1 || rooms[0].Code != code || rooms[0].BestOf != 5 {
+		t.Fatalf("expected room %s to be listed but got: %+v", code, rooms)
+	}
+	if rooms := s.ListOpenRooms("nobody"); len(rooms) != 0 {
+		t.Errorf("expected no rooms but got: %+v", rooms)
+	}
+
+	guest := roomtest.NewClient(s.rooms, "guest")
+	roomtest.Send(t, s.rooms, guest, EventTypeJoinGame, gameroom.EventDataCode{Code: code})
+	roomtest.WaitForEvent(t, guest, EventTypeJoinedGame)
+	if rooms := s.ListOpenRooms(""); len(rooms) != 0 {
+		t.Errorf("expected full rooms to be hidden but got: %+v", rooms)
+	}
+}
+
+func TestForfeitAfterGrace(t *testing.T) {
+	s := NewHandGameService(WithReconnectGrace(50 * time.Millisecond))
+	code, p1, p2 := startGame(t, s, 3)
+
+	s.rooms.Hub.Unregister <- p2
+	roomtest.WaitForEvent(t, p1, EventTypeReconnectCountdown)
+	data := EventDataForfeit{}
+	if err := json.Unmarshal(roomtest.WaitForEvent(t, p1, EventTypeForfeit).Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Winner != 1 || data.Forfeited != "p2" {
+		t.Errorf("expected p1 to win by forfeit but got: %+v", data)
+	}
+
+	rooms := s.ListOpenRooms("")
+	if len(rooms) != 1 || rooms[0].Code != code || rooms[0].Host != "p1" {
+		t.Errorf("expected p1 to wait for a new opponent but got: %+v", rooms)
+	}
+}
+
+func TestChatMessage(t *testing.T) {
+	s := NewHandGameService()
+	_, p1, p2 := startGame(t, s, 3)
+
+	roomtest.Send(t, s.rooms, p2, EventTypeChatMessage, gameroom.EventDataChat{Text: "gg"})
+	message := chat.Message{}
+	if err := json.Unmarshal(roomtest.WaitForEvent(t, p1, EventTypeChatMessage).Data, &message); err != nil {
+		t.Fatal(err)
+	}
+	if message.From != "p2" || message.Text != "gg" {
+		t.Errorf("expected the message of p2 but got: %+v", message)
+	}
+}
@@ -0,0 +1,342 @@
+package handgame
+
+import (
+	"errors"
+
+	"github.com/FredericoBento/HandGame/internal/ws"
+)
+
+type Hand int
+
+type Player struct {
+	Username  string `json:"username"`
+	Connected bool   `json:"connected"`
+	Wins      int    `json:"wins"`
+	Committed bool   `json:"committed"`
+	Rematch   bool   `json:"rematch"`
+	hand      Hand
+}
+
+type Round struct {
+	Number int  `json:"number"`
+	Hand1  Hand `json:"hand1"`
+	Hand2  Hand `json:"hand2"`
+	Winner int  `json:"winner"`
+}
+
+type GameState struct {
+	Code    string     `json:"code"`
+	Player1 *Player    `json:"player1"`
+	Player2 *Player    `json:"player2"`
+	BestOf  int        `json:"best_of"`
+	Round   int        `json:"round"`
+	Ties    int        `json:"ties"`
+	History []Round    `json:"history"`
+	Status  GameStatus `json:"status"`
+	Winner  int        `json:"winner"`
+}
+
+type GameStatus int
+
+const (
+	hand_none     = 0
+	hand_rock     = 1
+	hand_paper    = 2
+	hand_scissors = 3
+
+	game_status_waiting  = 0
+	game_status_running  = 1
+	game_status_finished = 2
+
+	default_best_of = 3
+	max_best_of     = 9
+)
+
+var (
+	ErrInvalidHand      = ws.NewError(ws.ErrorCodeInvalidPlay, "Invalid hand, choose rock, paper or scissors").WithField("hand")
+	ErrAlreadyCommitted = ws.NewError(ws.ErrorCodeAlreadyPlayed, "You already picked a hand this round")
+	ErrGameNotRunning   = ws.NewError(ws.ErrorCodeGameNotRunning, "Game is not running")
+	ErrPlayerNotFound   = errors.New("Player was not found")
+	ErrGameAlreadyFull  = ws.NewError(ws.ErrorCodeRoomFull, "Game already has both players")
+	ErrNoPlayerRemoved  = errors.New("No player was removed")
+	ErrInvalidBestOf    = ws.NewError(ws.ErrorCodeInvalidOptions, "Best of must be an odd number between 1 and 9").WithField("best_of")
+	ErrMatchNotFinished = errors.New("Match is not finished yet")
+	ErrNotEnoughPlayers = ws.NewError(ws.ErrorCodeGameNotRunning, "Waiting for the second player")
+	ErrPlayerNotInMatch = ws.NewError(ws.ErrorCodeNotAPlayer, "You are not playing in this match")
+)
+
+func NewGameState(code string, bestOf int) (*GameState, error) {
+	if bestOf == 0 {
+		bestOf = default_best_of
+	}
+	if bestOf < 1 || bestOf > max_best_of || bestOf%2 == 0 {
+		return nil, ErrInvalidBestOf
+	}
+	return &GameState{
+		Code:    code,
+		Player1: nil,
+		Player2: nil,
+		BestOf:  bestOf,
+		Round:   1,
+		History: make([]Round, 0),
+		Status:  game_status_waiting,
+		Winner:  0,
+	}, nil
+}
+
+func NewPlayer(username string) *Player {
+	return &Player{
+		Username:  username,
+		Connected: true,
+	}
+}
+
+func (h Hand) IsValid() bool {
+	return h == hand_rock || h == hand_paper || h == hand_scissors
+}
+
+// Beats reports whether h wins against other, a none hand never wins
+func (h Hand) Beats(other Hand) bool {
+	switch h {
+	case hand_rock:
+		return other == hand_scissors
+	case hand_paper:
+		return other == hand_rock
+	case hand_scissors:
+		return other == hand_paper
+	}
+	return false
+}
+
+// AddPlayer takes the first free slot, the match runs once both are taken. A
+// player left alone after a forfeit may hold either of them
+func (state *GameState) AddPlayer(username string) (int, error) {
+	playerNum := 0
+	switch {
+	case state.Player1 == nil:
+		state.Player1 = NewPlayer(username)
+		playerNum = 1
+	case state.Player2 == nil:
+		state.Player2 = NewPlayer(username)
+		playerNum = 2
+	default:
+		return 0, ErrGameAlreadyFull
+	}
+	if state.Player1 != nil && state.Player2 != nil {
+		state.Status = game_status_running
+	}
+	return playerNum, nil
+}
+
+func (state *GameState) RemovePlayer(username string) error {
+	if state.Player1 != nil && state.Player1.Username == username {
+		state.Player1 = nil
+		return nil
+	}
+	if state.Player2 != nil && state.Player2.Username == username {
+		state.Player2 = nil
+		return nil
+	}
+	return ErrNoPlayerRemoved
+}
+
+// PlayerNumber returns 1 or 2 for a player of this game, 0 otherwise
+func (state *GameState) PlayerNumber(username string) int {
+	if state.Player1 != nil && state.Player1.Username == username {
+		return 1
+	}
+	if state.Player2 != nil && state.Player2.Username == username {
+		return 2
+	}
+	return 0
+}
+
+func (state *GameState) GetPlayer(playerNum int) *Player {
+	switch playerNum {
+	case 1:
+		return state.Player1
+	case 2:
+		return state.Player2
+	}
+	return nil
+}
+
+func (state *GameState) Opponent(playerNum int) *Player {
+	if playerNum == 1 {
+		return state.Player2
+	}
+	return state.Player1
+}
+
+func (state *GameState) IsOpen() bool {
+	return (state.Player1 == nil || state.Player2 == nil) && state.Status == game_status_waiting
+}
+
+// Host is the player waiting alone in an open game, nil otherwise
+func (state *GameState) Host() *Player {
+	if !state.IsOpen() {
+		return nil
+	}
+	if state.Player1 != nil {
+		return state.Player1
+	}
+	return state.Player2
+}
+
+// Commit stores the hand of a player without revealing it, the round is ready
+// to be resolved once both players have committed
+func (state *GameState) Commit(playerNum int, hand Hand) (bool, error) {
+	if state.Status != game_status_running {
+		if state.Player1 == nil || state.Player2 == nil {
+			return false, ErrNotEnoughPlayers
+		}
+		return false, ErrGameNotRunning
+	}
+	if !hand.IsValid() {
+		return false, ErrInvalidHand
+	}
+	player := state.GetPlayer(playerNum)
+	if player == nil {
+		return false, ErrPlayerNotFound
+	}
+	if player.Committed {
+		return false, ErrAlreadyCommitted
+	}
+
+	player.hand = hand
+	player.Committed = true
+
+	return state.Player1.Committed && state.Player2.Committed, nil
+}
+
+// CommittedHand returns the hand the player picked this round, only meant to be sent back to that same player
+func (state *GameState) CommittedHand(playerNum int) Hand {
+	player := state.GetPlayer(playerNum)
+	if player == nil || !player.Committed {
+		return hand_none
+	}
+	return player.hand
+}
+
+// Reveal resolves the current round, it updates the scoreboard and finishes
+// the match when one of the players reaches the needed wins
+func (state *GameState) Reveal() (Round, error) {
+	if state.Player1 == nil || state.Player2 == nil {
+		return Round{}, ErrNotEnoughPlayers
+	}
+	if !state.Player1.Committed || !state.Player2.Committed {
+		return Round{}, ErrGameNotRunning
+	}
+
+	round := Round{
+		Number: state.Round,
+		Hand1:  state.Player1.hand,
+		Hand2:  state.Player2.hand,
+		Winner: 0,
+	}
+
+	if round.Hand1.Beats(round.Hand2) {
+		round.Winner = 1
+		state.Player1.Wins += 1
+	} else if round.Hand2.Beats(round.Hand1) {
+		round.Winner = 2
+		state.Player2.Wins += 1
+	} else {
+		state.Ties += 1
+	}
+
+	state.History = append(state.History, round)
+	state.Round += 1
+	state.clearHands()
+
+	winsNeeded := state.WinsNeeded()
+	if state.Player1.Wins >= winsNeeded {
+		state.Status = game_status_finished
+		state.Winner = 1
+	} else if state.Player2.Wins >= winsNeeded {
+		state.Status = game_status_finished
+		state.Winner = 2
+	}
+
+	return round, nil
+}
+
+func (state *GameState) WinsNeeded() int {
+	return state.BestOf/2 + 1
+}
+
+func (state *GameState) IsFinished() bool {
+	return state.Status == game_status_finished
+}
+
+// RequestRematch marks the player as wanting a rematch, the scoreboard is
+// restarted once both players have asked for it
+func (state *GameState) RequestRematch(playerNum int) (bool, error) {
+	if state.Status != game_status_finished {
+		return false, ErrMatchNotFinished
+	}
+	player := state.GetPlayer(playerNum)
+	if player == nil {
+		return false, ErrPlayerNotFound
+	}
+	player.Rematch = true
+	if state.Player1 == nil || state.Player2 == nil {
+		return false, nil
+	}
+	if !state.Player1.Rematch || !state.Player2.Rematch {
+		return false, nil
+	}
+	state.Restart()
+	return true, nil
+}
+
+// Forfeit frees the slot of a player that left for good, a running match is
+// given to its opponent, which is then left waiting for someone else. It
+// returns true if the match was given
+func (state *GameState) Forfeit(playerNum int) (bool, error) {
+	player := state.GetPlayer(playerNum)
+	if player == nil {
+		return false, ErrPlayerNotFound
+	}
+	opponent := state.Opponent(playerNum)
+	given := opponent != nil && opponent.Connected && state.Status == game_status_running
+	if given {
+		state.Status = game_status_finished
+		state.Winner = 3 - playerNum
+	}
+	state.RemovePlayer(player.Username)
+	return given, nil
+}
+
+// Restart clears the scoreboard keeping the players, the match waits for a
+// second player if one is missing
+func (state *GameState) Restart() {
+	state.Round = 1
+	state.Ties = 0
+	state.Winner = 0
+	state.History = make([]Round, 0)
+	if state.Player1 != nil {
+		state.Player1.Wins = 0
+		state.Player1.Rematch = false
+	}
+	if state.Player2 != nil {
+		state.Player2.Wins = 0
+		state.Player2.Rematch = false
+	}
+	state.clearHands()
+	state.Status = game_status_waiting
+	if state.Player1 != nil && state.Player2 != nil {
+		state.Status = game_status_running
+	}
+}
+
+func (state *GameState) clearHands() {
+	if state.Player1 != nil {
+		state.Player1.hand = hand_none
+		state.Player1.Committed = false
+	}
+	if state.Player2 != nil {
+		state.Player2.hand = hand_none
+		state.Player2.Committed = false
+	}
+}
@@ -0,0 +1,216 @@
+package handgame
+
+import (
+	"testing"
+)
+
+func TestHandBeats(t *testing.T) {
+	tests := []struct {
+		name     string
+		hand     Hand
+		other    Hand
+		expected bool
+	}{
+		{name: "Rock beats scissors", hand: hand_rock, other: hand_scissors, expected: true},
+		{name: "Paper beats rock", hand: hand_paper, other: hand_rock, expected: true},
+		{name: "Scissors beats paper", hand: hand_scissors, other: hand_paper, expected: true},
+		{name: "Rock loses to paper", hand: hand_rock, other: hand_paper, expected: false},
+		{name: "Same hand does not win", hand: hand_rock, other: hand_rock, expected: false},
+		{name: "None never wins", hand: hand_none, other: hand_scissors, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.hand.Beats(tt.other); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNewGameState(t *testing.T) {
+	tests := []struct {
+		name        string
+		bestOf      int
+		expectedErr error
+	}{
+		{name: "Default best of", bestOf: 0, expectedErr: nil},
+		{name: "Best of five", bestOf: 5, expectedErr: nil},
+		{name: "Even best of", bestOf: 4, expectedErr: ErrInvalidBestOf},
+		{name: "Too many rounds", bestOf: 11, expectedErr: ErrInvalidBestOf},
+		{name: "Negative best of", bestOf: -1, expectedErr: ErrInvalidBestOf},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewGameState("abcd", tt.bestOf)
+			if err != tt.expectedErr {
+				t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func newRunningState(t *testing.T, bestOf int) *GameState {
+	state, err := NewGameState("abcd", bestOf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.AddPlayer("p1")
+	state.AddPlayer("p2")
+	return state
+}
+
+func playRound(t *testing.T, state *GameState, hand1 Hand, hand2 Hand) Round {
+	ready, err := state.Commit(1, hand1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ready {
+		t.Fatal("expected round to wait for the second hand")
+	}
+	ready, err = state.Commit(2, hand2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ready {
+		t.Fatal("expected round to be ready after both hands")
+	}
+	round, err := state.Reveal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return round
+}
+
+func TestCommit(t *testing.T) {
+	t.Run("WaitingForOpponent", func(t *testing.T) {
+		state, _ := NewGameState("abcd", 3)
+		state.AddPlayer("p1")
+
+		_, err := state.Commit(1, hand_rock)
+		if err != ErrNotEnoughPlayers {
+			t.Errorf("expected error %v, got %v", ErrNotEnoughPlayers, err)
+		}
+	})
+
+	t.Run("InvalidHand", func(t *testing.T) {
+		state := newRunningState(t, 3)
+
+		_, err := state.Commit(1, Hand(7))
+		if err != ErrInvalidHand {
+			t.Errorf("expected error %v, got %v", ErrInvalidHand, err)
+		}
+	})
+
+	t.Run("CommitTwice", func(t *testing.T) {
+		state := newRunningState(t, 3)
+
+		state.Commit(1, hand_rock)
+		_, err := state.Commit(1, hand_paper)
+		if err != ErrAlreadyCommitted {
+			t.Errorf("expected error %v, got %v", ErrAlreadyCommitted, err)
+		}
+		if state.CommittedHand(1) != hand_rock {
+			t.Errorf("expected committed hand to stay rock, got %v", state.CommittedHand(1))
+		}
+	})
+}
+
+func TestBestOf(t *testing.T) {
+	t.Run("TieDoesNotCount", func(t *testing.T) {
+		state := newRunningState(t, 3)
+
+		round := playRound(t, state, hand_rock, hand_rock)
+		if round.Winner != 0 {
+			t.Errorf("expected tie, got winner %d", round.Winner)
+		}
+		if state.Ties != 1 || state.Player1.Wins != 0 || state.Player2.Wins != 0 {
+			t.Errorf("unexpected scoreboard %d-%d ties %d", state.Player1.Wins, state.Player2.Wins, state.Ties)
+		}
+		if state.CommittedHand(1) != hand_none || state.Player1.Committed {
+			t.Errorf("expected hands to be cleared after reveal")
+		}
+	})
+
+	t.Run("MatchFinishes", func(t *testing.T) {
+		state := newRunningState(t, 3)
+
+		playRound(t, state, hand_rock, hand_scissors)
+		if state.IsFinished() {
+			t.Fatal("expected match to keep going after one win")
+		}
+		playRound(t, state, hand_rock, hand_paper)
+		playRound(t, state, hand_paper, hand_rock)
+
+		if !state.IsFinished() {
+			t.Fatal("expected match to be finished")
+		}
+		if state.Winner != 1 {
+			t.Errorf("expected player 1 to win, got %d", state.Winner)
+		}
+		if len(state.History) != 3 {
+			t.Errorf("expected 3 rounds in history, got %d", len(state.History))
+		}
+
+		_, err := state.Commit(1, hand_rock)
+		if err != ErrGameNotRunning {
+			t.Errorf("expected error %v, got %v", ErrGameNotRunning, err)
+		}
+	})
+
+	t.Run("Rematch", func(t *testing.T) {
+		state := newRunningState(t, 1)
+
+		playRound(t, state, hand_scissors, hand_paper)
+
+		restarted, err := state.RequestRematch(1)
+		if err != nil || restarted {
+			t.Fatalf("expected rematch to wait for the opponent, got %v %v", restarted, err)
+		}
+		restarted, err = state.RequestRematch(2)
+		if err != nil || !restarted {
+			t.Fatalf("expected rematch to restart, got %v %v", restarted, err)
+		}
+		if state.Player1.Wins != 0 || state.Round != 1 || state.IsFinished() {
+			t.Errorf("expected scoreboard to be cleared")
+		}
+	})
+}
+
+func TestForfeit(t *testing.T) {
+	t.Run("RunningMatchIsGiven", func(t *testing.T) {
+		state := newRunningState(t, 3)
+		playRound(t, state, hand_rock, hand_scissors)
+
+		given, err := state.Forfeit(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !given || state.Winner != 2 || state.Player1 != nil {
+			t.Errorf("expected the match to be given to p2, got %+v", state)
+		}
+
+		state.Restart()
+		if !state.IsOpen() || state.Host() != state.Player2 || state.Player2.Wins != 0 {
+			t.Errorf("expected p2 to wait for a new opponent, got %+v", state)
+		}
+		playerNum, err := state.AddPlayer("p3")
+		if err != nil || playerNum != 1 || state.Status != game_status_running {
+			t.Errorf("expected p3 to take the free slot and start the match, got %d %v", playerNum, err)
+		}
+	})
+
+	t.Run("FinishedMatchIsKept", func(t *testing.T) {
+		state := newRunningState(t, 1)
+		playRound(t, state, hand_paper, hand_rock)
+
+		given, err := state.Forfeit(2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if given || state.Winner != 1 {
+			t.Errorf("expected the finished match to keep its winner, got %+v", state)
+		}
+	})
+}
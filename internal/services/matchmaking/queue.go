@@ -0,0 +1,209 @@
+package matchmaking
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/ws"
+)
+
+const (
+	// default_interval is how often the queue looks for pairs
+	default_interval = time.Second
+
+	// A player accepts opponents within default_window rating points, the
+	// window grows by default_window_step every second spent in the queue
+	default_window      = 50
+	default_window_step = 25
+	default_window_max  = 800
+)
+
+var (
+	ErrAlreadyQueued = ws.NewError(ws.ErrorCodeAlreadyQueued, "You are already looking for a match")
+)
+
+// Ticket is a client waiting in the queue
+type Ticket struct {
+	Client   *ws.Client
+	Rating   float64
+	QueuedAt time.Time
+}
+
+// Pair is two tickets that were matched, the first one waited the longest
+type Pair [2]*Ticket
+
+// Queue pairs the players of a game, preferring opponents of a similar rating
+type Queue struct {
+	Game string
+
+	mu      sync.Mutex
+	tickets []*Ticket
+
+	window     float64
+	windowStep float64
+	windowMax  float64
+}
+
+type QueueOption func(*Queue)
+
+// WithWindow changes the initial rating window, how much it widens each
+// second and its maximum
+func WithWindow(initial float64, step float64, max float64) QueueOption {
+	return func(q *Queue) {
+		q.window = initial
+		q.windowStep = step
+		q.windowMax = max
+	}
+}
+
+func NewQueue(game string, opts ...QueueOption) *Queue {
+	q := &Queue{
+		Game:       game,
+		tickets:    make([]*Ticket, 0),
+		window:     default_window,
+		windowStep: default_window_step,
+		windowMax:  default_window_max,
+	}
+	for _, option := range opts {
+		option(q)
+	}
+	return q
+}
+
+func (q *Queue) Join(client *ws.Client, rating float64, now time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, t := range q.tickets {
+		if t.Client.Username == client.Username {
+			return ErrAlreadyQueued
+		}
+	}
+	q.tickets = append(q.tickets, &Ticket{
+		Client:   client,
+		Rating:   rating,
+		QueuedAt: now,
+	})
+	return nil
+}
+
+// Leave removes the player from the queue, it returns false if it was not in it
+func (q *Queue) Leave(username string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, t := range q.tickets {
+		if t.Client.Username == username {
+			q.tickets = append(q.tickets[:i], q.tickets[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// LeaveClient removes the player from the queue only if it joined it through
+// that connection, it returns false otherwise
+func (q *Queue) LeaveClient(client *ws.Client) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, t := range q.tickets {
+		if t.Client == client {
+			q.tickets = append(q.tickets[:i], q.tickets[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (q *Queue) Contains(username string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, t := range q.tickets {
+		if t.Client.Username == username {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.tickets)
+}
+
+// Window is the rating difference the ticket accepts at the given time
+func (q *Queue) Window(t *Ticket, now time.Time) float64 {
+	waited := now.Sub(t.QueuedAt).Seconds()
+	if waited < 0 {
+		waited = 0
+	}
+	return math.Min(q.window+q.windowStep*waited, q.windowMax)
+}
+
+// Match takes every pair it can out of the queue. The players that waited
+// the longest pick first, each one the closest rating within the wider of
+// the two windows
+func (q *Queue) Match(now time.Time) []Pair {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	sort.SliceStable(q.tickets, func(i, j int) bool {
+		return q.tickets[i].QueuedAt.Before(q.tickets[j].QueuedAt)
+	})
+
+	matched := make([]bool, len(q.tickets))
+	pairs := make([]Pair, 0)
+	for i, a := range q.tickets {
+		if matched[i] {
+			continue
+		}
+		best := -1
+		bestDiff := math.Inf(1)
+		for j := i + 1; j < len(q.tickets); j++ {
+			if matched[j] {
+				continue
+			}
+			b := q.tickets[j]
+			diff := math.Abs(a.Rating - b.Rating)
+			if diff > math.Max(q.Window(a, now), q.Window(b, now)) {
+				continue
+			}
+			if diff < bestDiff {
+				best, bestDiff = j, diff
+			}
+		}
+		if best >= 0 {
+			matched[i], matched[best] = true, true
+			pairs = append(pairs, Pair{a, q.tickets[best]})
+		}
+	}
+
+	remaining := q.tickets[:0]
+	for i, t := range q.tickets {
+		if !matched[i] {
+			remaining = append(remaining, t)
+		}
+	}
+	for i := len(remaining); i < len(q.tickets); i++ {
+		q.tickets[i] = nil
+	}
+	q.tickets = remaining
+	return pairs
+}
+
+// Run looks for pairs every second and hands them to onMatch, it never returns
+func (q *Queue) Run(onMatch func(pair Pair)) {
+	ticker := time.NewTicker(default_interval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		for _, pair := range q.Match(now) {
+			onMatch(pair)
+		}
+	}
+}
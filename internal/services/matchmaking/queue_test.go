@@ -0,0 +1,130 @@
+package matchmaking
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/ws"
+)
+
+func newTestQueue() *Queue {
+	return NewQueue("test", WithWindow(50, 25, 400))
+}
+
+func client(username string) *ws.Client {
+	return &ws.Client{Username: username}
+}
+
+func TestQueueJoinLeave(t *testing.T) {
+	q := newTestQueue()
+	now := time.Now()
+
+	if err := q.Join(client("a"), 1200, now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := q.Join(client("a"), 1200, now); err != ErrAlreadyQueued {
+		t.Errorf("expected %v, got %v", ErrAlreadyQueued, err)
+	}
+	if !q.Contains("a") || q.Len() != 1 {
+		t.Errorf("expected a to be queued")
+	}
+	if !q.Leave("a") {
+		t.Errorf("expected a to leave the queue")
+	}
+	if q.Leave("a") {
+		t.Errorf("expected leaving twice to do nothing")
+	}
+}
+
+func TestQueueLeaveClient(t *testing.T) {
+	q := newTestQueue()
+	queued := client("a")
+	q.Join(queued, 1200, time.Now())
+
+	if q.LeaveClient(client("a")) {
+		t.Errorf("expected another connection of a not to take it out of the queue")
+	}
+	if !q.LeaveClient(queued) || q.Contains("a") {
+		t.Errorf("expected a to leave the queue")
+	}
+}
+
+func TestQueueWindow(t *testing.T) {
+	q := newTestQueue()
+	now := time.Now()
+	ticket := &Ticket{QueuedAt: now}
+
+	tests := []struct {
+		name     string
+		waited   time.Duration
+		expected float64
+	}{
+		{"JustQueued", 0, 50},
+		{"AfterTwoSeconds", 2 * time.Second, 100},
+		{"Capped", time.Minute, 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := q.Window(ticket, now.Add(tt.waited)); got != tt.expected {
+				t.Errorf("expected window %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestQueueMatch(t *testing.T) {
+	t.Run("PrefersClosestRating", func(t *testing.T) {
+		q := newTestQueue()
+		now := time.Now()
+		q.Join(client("a"), 1200, now)
+		q.Join(client("b"), 1240, now.Add(time.Millisecond))
+		q.Join(client("c"), 1210, now.Add(2*time.Millisecond))
+
+		pairs := q.Match(now.Add(3 * time.Millisecond))
+		if len(pairs) != 1 {
+			t.Fatalf("expected one pair, got %d", len(pairs))
+		}
+		if pairs[0][0].Client.Username != "a" || pairs[0][1].Client.Username != "c" {
+			t.Errorf("expected a to be paired with c, got %s and %s", pairs[0][0].Client.Username, pairs[0][1].Client.Username)
+		}
+		if !q.Contains("b") || q.Len() != 1 {
+			t.Errorf("expected b to keep waiting")
+		}
+	})
+
+	t.Run("WidensOverTime", func(t *testing.T) {
+		q := newTestQueue()
+		now := time.Now()
+		q.Join(client("a"), 1200, now)
+		q.Join(client("b"), 1400, now)
+
+		if pairs := q.Match(now); len(pairs) != 0 {
+			t.Errorf("expected no pair while ratings are far apart")
+		}
+		if pairs := q.Match(now.Add(5 * time.Second)); len(pairs) != 0 {
+			t.Errorf("expected no pair with a window of 175 for a difference of 200")
+		}
+		if pairs := q.Match(now.Add(6 * time.Second)); len(pairs) != 1 {
+			t.Errorf("expected a pair once the window is wide enough")
+		}
+		if q.Len() != 0 {
+			t.Errorf("expected the queue to be empty, got %d", q.Len())
+		}
+	})
+
+	t.Run("LongestWaitingPicksFirst", func(t *testing.T) {
+		q := newTestQueue()
+		now := time.Now()
+		q.Join(client("late"), 1500, now.Add(10*time.Second))
+		q.Join(client("early"), 1450, now)
+		q.Join(client("other"), 1480, now.Add(5*time.Second))
+
+		pairs := q.Match(now.Add(10 * time.Second))
+		if len(pairs) != 1 {
+			t.Fatalf("expected one pair, got %d", len(pairs))
+		}
+		if pairs[0][0].Client.Username != "early" || pairs[0][1].Client.Username != "other" {
+			t.Errorf("expected early to be paired with other, got %s and %s", pairs[0][0].Client.Username, pairs[0][1].Client.Username)
+		}
+	})
+}
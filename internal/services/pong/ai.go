@@ -0,0 +1,136 @@
+package pong
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	ai_username = "Computer (practice)"
+
+	default_ai_reaction = 150 * time.Millisecond
+	default_ai_error    = 15
+)
+
+// AIOptions tune the computer paddle, they come with the create event of a
+// practice room. Zero values take the defaults
+type AIOptions struct {
+	ReactionMs int     `json:"reaction_ms"`
+	Error      float64 `json:"error"`
+}
+
+// AIPaddle plays Player2 for someone practising alone. It sees the ball as
+// it was Reaction ago and aims up to Error away from where the ball will
+// reach its paddle, moving no faster than a player could
+type AIPaddle struct {
+	Username string
+	Reaction time.Duration
+	Error    float64
+
+	// sightings are the ball positions seen in the last Reaction, the oldest
+	// first
+	sightings []sighting
+	// aim is the error of the current rally, it is drawn again every time
+	// the ball turns
+	aim    float64
+	lastDx float64
+}
+
+type sighting struct {
+	at   time.Time
+	ball Ball
+}
+
+func NewAIPaddle(options AIOptions) *AIPaddle {
+	ai := &AIPaddle{
+		Username: ai_username,
+		Reaction: time.Duration(options.ReactionMs) * time.Millisecond,
+		Error:    options.Error,
+	}
+	if ai.Reaction <= 0 {
+		ai.Reaction = default_ai_reaction
+	}
+	if ai.Error <= 0 {
+		ai.Error = default_ai_error
+	}
+	return ai
+}
+
+// observe records the ball as it is at now
+func (ai *AIPaddle) observe(now time.Time, ball Ball) {
+	ai.sightings = append(ai.sightings, sighting{at: now, ball: ball})
+}
+
+// seen returns the ball as it was Reaction before now, ok is false until the
+// AI has been watching for that long
+func (ai *AIPaddle) seen(now time.Time) (Ball, bool) {
+	cutoff := now.Add(-ai.Reaction)
+	// Keep the newest sighting that is old enough, drop the ones before it
+	i := 0
+	for i+1 < len(ai.sightings) && !ai.sightings[i+1].at.After(cutoff) {
+		i++
+	}
+	ai.sightings = ai.sightings[i:]
+	if len(ai.sightings) == 0 || ai.sightings[0].at.After(cutoff) {
+		return Ball{}, false
+	}
+	return ai.sightings[0].ball, true
+}
+
+// Move returns where the AI puts its paddle at now, elapsed is the time since
+// it last moved. moved is false if the paddle stays where it is
+func (ai *AIPaddle) Move(now time.Time, state *GameState, elapsed time.Duration) (y float64, moved bool) {
+	paddle := state.Player2.Paddle
+	ai.observe(now, *state.Ball)
+	ball, ok := ai.seen(now)
+	if !ok {
+		return paddle.Position.Y, false
+	}
+
+	top := 25.0
+	bottom := state.Canvas.Height + 25
+	target := top + state.Canvas.Height/2
+	if ball.Direction != ball_direction_none && ball.Dx > 0 {
+		// A new rally coming its way
+		if ai.lastDx <= 0 {
+			ai.aim = (rand.Float64()*2 - 1) * ai.Error
+		}
+		target = predictY(ball, paddle.Position.X, top+ball.Radius, bottom-ball.Radius) + ai.aim
+	}
+	ai.lastDx = ball.Dx
+
+	// The paddle position is its top edge
+	target = math.Max(top, math.Min(target-paddle.Length/2, bottom-paddle.Length))
+	step := paddle.Speed * elapsed.Seconds()
+	y = paddle.Position.Y
+	switch {
+	case math.Abs(target-y) <= step:
+		y = target
+	case target > y:
+		y += step
+	default:
+		y -= step
+	}
+	return y, y != paddle.Position.Y
+}
+
+// predictY follows the ball bouncing between low and high until it gets to
+// x, it returns the y it will be at
+func predictY(ball Ball, x float64, low float64, high float64) float64 {
+	if ball.Dx == 0 || high <= low {
+		return ball.Position.Y
+	}
+	steps := (x - ball.Position.X) / ball.Dx
+	y := ball.Position.Y + ball.Dy*steps - low
+	// Fold the straight path back into the court, every bounce mirrors it
+	span := high - low
+	y = math.Mod(y, 2*span)
+	if y < 0 {
+		y += 2 * span
+	}
+	if y > span {
+		y = 2*span - y
+	}
+	return low + y
+}
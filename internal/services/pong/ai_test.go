@@ -0,0 +1,107 @@
+package pong
+
+import (
+	"math"
+	"testing"
+
+	"github.com/FredericoBento/HandGame/internal/models"
+)
+
+func TestPredictYBounces(t *testing.T) {
+	tests := []struct {
+		name     string
+		ball     Ball
+		expected float64
+	}{
+		{"Straight", Ball{Dx: 5, Dy: 0, Position: models.Vector2D{X: 100, Y: 100}}, 100},
+		{"Diagonal", Ball{Dx: 5, Dy: 5, Position: models.Vector2D{X: 100, Y: 100}}, 150},
+		{"OffTheBottom", Ball{Dx: 5, Dy: 5, Position: models.Vector2D{X: 100, Y: 180}}, 170},
+		{"OffTheTop", Ball{Dx: 5, Dy: -5, Position: models.Vector2D{X: 100, Y: 30}}, 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := predictY(tt.ball, 150, 0, 200)
+			if math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("expected %v but got: %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func newTestAISimulation(t *testing.T, options AIOptions) (*Simulation, *fakeClock, *recordingListener) {
+	state := NewGameState(nil, 0, 0)
+	state.AddPlayer("p1", nil)
+	ai := NewAIPaddle(options)
+	ai.Error = 0
+	state.AddPlayer(ai.Username, nil)
+
+	clock := newFakeClock()
+	listener := &recordingListener{}
+	sim := NewSimulation("abcd", state, clock, listener)
+	sim.ai = ai
+	sim.Start()
+	t.Cleanup(sim.Stop)
+	return sim, clock, listener
+}
+
+func paddleY(sim *Simulation) float64 {
+	var y float64
+	sim.Do(func(state *GameState) {
+		y = state.Player2.Paddle.Position.Y
+	})
+	return y
+}
+
+// advance ticks the simulation n times, waiting for each tick to be handled
+// so that they are not folded into one by the ticker reading the clock late
+func advance(sim *Simulation, clock *fakeClock, n int) {
+	for range n {
+		clock.Advance(simulation_timestep)
+		sim.Do(func(state *GameState) {})
+	}
+}
+
+func TestAIPaddleFollowsTheBall(t *testing.T) {
+	sim, clock, listener := newTestAISimulation(t, AIOptions{ReactionMs: 50})
+	start := paddleY(sim)
+
+	sim.Do(func(state *GameState) {
+		state.Ball.Position.Y = 60
+		state.Ball.Direction = ball_direction_right
+		state.Ball.Dx = 1
+	})
+	// Nothing seen yet, the reaction delay has not gone by
+	advance(sim, clock, 4)
+	if paddleY(sim) != start {
+		t.Errorf("expected the paddle to wait for the reaction delay but it moved")
+	}
+
+	advance(sim, clock, 60)
+	var expected float64
+	sim.Do(func(state *GameState) {
+		expected = math.Max(25, state.Ball.Position.Y-state.Player2.Paddle.Length/2)
+	})
+	if y := paddleY(sim); math.Abs(y-expected) > 1 {
+		t.Errorf("expected the paddle at %v but got: %v", expected, y)
+	}
+	if len(listener.paddles) == 0 {
+		t.Errorf("expected the paddle moves to be relayed")
+	}
+}
+
+func TestAIPaddleSpeedIsLimited(t *testing.T) {
+	sim, clock, _ := newTestAISimulation(t, AIOptions{ReactionMs: 10})
+	start := paddleY(sim)
+	sim.Do(func(state *GameState) {
+		state.Ball.Position.Y = 300
+		state.Ball.Direction = ball_direction_right
+		state.Ball.Dx = 1
+	})
+	advance(sim, clock, 2)
+
+	maxStep := default_paddle_speed * simulation_timestep.Seconds()
+	if moved := paddleY(sim) - start; moved <= 0 || moved > maxStep+1e-9 {
+		t.Errorf("expected the paddle to move at most %v but got: %v", maxStep, moved)
+	}
+}
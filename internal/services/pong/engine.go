@@ -0,0 +1,357 @@
+package pong
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/services"
+	"github.com/FredericoBento/HandGame/internal/services/gameroom"
+	"github.com/FredericoBento/HandGame/internal/utils"
+	"github.com/FredericoBento/HandGame/internal/ws"
+)
+
+// engine plays the game of one room, see gameroom.Engine. The simulation owns
+// the game state and runs its own fixed step loop, the engine only hands it
+// the players and their moves, and relays its outputs to the room
+type engine struct {
+	service *PongService
+	room    *gameroom.Room
+	sim     *Simulation
+	// ai plays as Player2 in practice rooms, the simulation moves it
+	ai *AIPaddle
+}
+
+func (s *PongService) newEngine(room *gameroom.Room, options json.RawMessage) (gameroom.Engine, error) {
+	opts := EventDataCreateRoom{}
+	if len(options) > 0 {
+		if err := json.Unmarshal(options, &opts); err != nil {
+			return nil, err
+		}
+	}
+	e := &engine{service: s, room: room}
+	e.sim = NewSimulation(room.Code, NewGameState(nil, 0, 0), s.clock, e)
+	if opts.AI != nil {
+		e.ai = NewAIPaddle(*opts.AI)
+		e.sim.ai = e.ai
+	}
+	e.sim.Start()
+	return e, nil
+}
+
+// OnJoin adds the player to the simulation, the first one is told the room
+// was created and the others that they joined it. A player coming back gets
+// the whole game state
+func (e *engine) OnJoin(room *gameroom.Room, client *ws.Client) error {
+	var err error
+	var rejoined bool
+	var isPlayer1 bool
+	var player1Username string
+	var snapshot json.RawMessage
+	e.sim.Do(func(state *GameState) {
+		if state.ReconnectPlayer(client.Username) == nil {
+			rejoined = true
+			snapshot, err = utils.EncodeJSON(state)
+			return
+		}
+		isPlayer1 = state.Player1 == nil
+		err = state.AddPlayer(client.Username, nil)
+		if err == nil && e.ai != nil {
+			err = state.AddPlayer(e.ai.Username, nil)
+		}
+		if state.Player1 != nil {
+			player1Username = state.Player1.Username
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if rejoined {
+		return e.sendReconnected(room, client, snapshot)
+	}
+
+	if e.ai != nil {
+		if !room.TakeSeat(e.ai.Username) {
+			return ErrServerError
+		}
+		if err := e.sendCreated(room, client); err != nil {
+			return err
+		}
+		return e.sendAIJoined(room, client)
+	}
+
+	if len(room.Clients) == 1 {
+		return e.sendCreated(room, client)
+	}
+
+	var otherClientUsername string
+	for _, c := range room.Clients {
+		if c.Username == client.Username {
+			continue
+		}
+		otherClientUsername = c.Username
+		bytes, err := utils.EncodeJSON(EventDataPlayerJoined{
+			Code:      room.Code,
+			Player:    client.Username,
+			IsPlayer1: isPlayer1,
+		})
+		if err != nil {
+			return err
+		}
+		event := ws.NewEvent(EventTypePlayerJoinedRoom, room.Code)
+		event.Data = bytes
+		room.SendTo(c.Username, &event)
+	}
+	bytes, err := utils.EncodeJSON(EventDataJoinedRoom{
+		Code:      room.Code,
+		Username:  client.Username,
+		Player:    otherClientUsername,
+		IsPlayer1: otherClientUsername == player1Username,
+	})
+	if err != nil {
+		return err
+	}
+	joinedEvent := ws.NewSimpleEvent(EventTypeJoinedRoom)
+	joinedEvent.Data = bytes
+	room.Reply(client, &joinedEvent)
+	return nil
+}
+
+// sendReconnected gives the player that came back the events it missed, or
+// the whole game state if they are no longer kept, and tells the others it
+// is playing again
+func (e *engine) sendReconnected(room *gameroom.Room, client *ws.Client, snapshot json.RawMessage) error {
+	if !room.Resume(client) {
+		bytes, err := utils.EncodeJSON(EventDataSync{
+			Code:     room.Code,
+			Username: client.Username,
+			State:    snapshot,
+		})
+		if err != nil {
+			return err
+		}
+		syncEvent := ws.NewEvent(EventTypeSyncGameState, room.Code)
+		syncEvent.Data = bytes
+		room.Reply(client, &syncEvent)
+	}
+
+	// Another tab of a player that never left, nobody needs to know
+	if len(room.Connections(client.Username)) > 1 {
+		return nil
+	}
+	bytes, err := utils.EncodeJSON(ws.EventDataUser{Username: client.Username})
+	if err != nil {
+		return err
+	}
+	event := ws.NewEvent(EventTypePlayerReconnected, room.Code)
+	event.Data = bytes
+	room.SendAll(&event, client.Username)
+	return nil
+}
+
+func (e *engine) sendCreated(room *gameroom.Room, client *ws.Client) error {
+	bytes, err := utils.EncodeJSON(EventDataCreatedRoom{
+		Code:     room.Code,
+		Username: client.Username,
+	})
+	if err != nil {
+		return err
+	}
+	createdRoomEvent := ws.NewEvent(EventTypeCreatedRoom, room.Code)
+	createdRoomEvent.Data = bytes
+	room.Reply(client, &createdRoomEvent)
+	return nil
+}
+
+// sendAIJoined tells the player of a practice room that the computer took
+// the other seat, as if it had joined
+func (e *engine) sendAIJoined(room *gameroom.Room, client *ws.Client) error {
+	bytes, err := utils.EncodeJSON(EventDataPlayerJoined{
+		Code:   room.Code,
+		Player: e.ai.Username,
+	})
+	if err != nil {
+		return err
+	}
+	event := ws.NewEvent(EventTypePlayerJoinedRoom, room.Code)
+	event.Data = bytes
+	room.Reply(client, &event)
+	return nil
+}
+
+// OnLeave pauses the game until the player comes back or forfeits, its
+// seat is kept
+func (e *engine) OnLeave(room *gameroom.Room, client *ws.Client) {
+	e.sim.Do(func(state *GameState) {
+		state.DisconnectPlayer(client.Username)
+	})
+	if len(room.Clients) == 0 {
+		return
+	}
+
+	bytes, err := utils.EncodeJSON(ws.EventDataUser{Username: client.Username})
+	if err != nil {
+		e.service.Log.Error("Could not encode json while broadcasting cliennt disconnect")
+		return
+	}
+	event := ws.NewEvent(ws.EventTypeUserDisconnected, room.Code)
+	event.Data = bytes
+	room.SendAll(&event)
+}
+
+// OnForfeit takes the player out of the simulation, a game in progress is
+// won by its opponent. The scores are reset for whoever takes the seat
+func (e *engine) OnForfeit(room *gameroom.Room, username string) {
+	var forfeited bool
+	var player1, player2 Player
+	e.sim.Do(func(state *GameState) {
+		if state.Player1 != nil && state.Player2 != nil && state.Status != game_status_finished {
+			forfeited = true
+			player1, player2 = *state.Player1, *state.Player2
+		}
+		state.RemovePlayer(username)
+		// The computer does not wait alone for someone else to join
+		if e.ai != nil && username != e.ai.Username {
+			state.RemovePlayer(e.ai.Username)
+		}
+		for _, player := range []*Player{state.Player1, state.Player2} {
+			if player != nil {
+				player.Score = 0
+			}
+		}
+		state.Status = game_status_paused
+		state.Ball.recenter(state.Canvas.Width, state.Canvas.Height+50)
+	})
+	if e.ai != nil && username != e.ai.Username {
+		room.FreeSeat(e.ai.Username)
+	}
+	if forfeited {
+		go e.finishGame(room.Code, player1, player2, username)
+	}
+}
+
+func (e *engine) OnEvent(room *gameroom.Room, client *ws.Client, event *ws.Event) error {
+	switch event.Type {
+	case EventTypePaddleMoved:
+		data := EventPaddleMoveData{}
+		err := json.Unmarshal(event.Data, &data)
+		if err != nil {
+			e.service.Log.Error("Invalid data for paddle pressed event")
+			return gameroom.ErrInvalidData
+		}
+		e.sim.MovePaddle(client.Username, data.Paddle_y)
+
+	case EventTypeBallShot:
+		e.sim.ShootBall(client.Username)
+
+	default:
+		return ErrUnknownEvent
+	}
+	return nil
+}
+
+// Snapshot is encoded by the simulation, the state is only safe to read
+// from there
+func (e *engine) Snapshot() any {
+	var data json.RawMessage
+	e.sim.Do(func(state *GameState) {
+		bytes, err := utils.EncodeJSON(state)
+		if err != nil {
+			e.service.Log.Error(err.Error())
+			return
+		}
+		data = bytes
+	})
+	return data
+}
+
+func (e *engine) Tick(room *gameroom.Room, now time.Time) {}
+
+func (e *engine) OnClose(room *gameroom.Room) {
+	e.sim.Stop()
+}
+
+// OnBallUpdate is called by the room simulation after every tick the ball moved
+func (e *engine) OnBallUpdate(code string, ball Ball) {
+	data, err := utils.EncodeJSON(ball.Position)
+	if err != nil {
+		e.service.Log.Error(err.Error())
+		return
+	}
+	binary, err := EncodeBallFrame(&ball)
+	if err != nil {
+		e.service.Log.Error(err.Error())
+		return
+	}
+	event := ws.NewSimpleEvent(EventTypeBallUpdate)
+	event.Data = data
+	event.Binary = binary
+	e.room.Stream(&event)
+}
+
+// OnGoal is called by the room simulation when a player scores
+func (e *engine) OnGoal(code string, player1Score int, player2Score int) {
+	data, err := utils.EncodeJSON(EventDataGoal{
+		Player1Score: player1Score,
+		Player2Score: player2Score,
+	})
+	if err != nil {
+		e.service.Log.Error(err.Error())
+		return
+	}
+	binary, err := EncodeGoalFrame(player1Score, player2Score)
+	if err != nil {
+		e.service.Log.Error(err.Error())
+		return
+	}
+	event := ws.NewSimpleEvent(EventTypeGoal)
+	event.Data = data
+	event.Binary = binary
+	e.room.Broadcast(&event)
+}
+
+// OnPaddleMoved relays the paddle position to everyone in the room but its owner
+func (e *engine) OnPaddleMoved(code string, username string, y float64) {
+	data, err := utils.EncodeJSON(EventPaddleMoveData{Paddle_y: y, Username: username})
+	if err != nil {
+		e.service.Log.Error(err.Error())
+		return
+	}
+	event := ws.NewSimpleEvent(EventTypePaddleMoved)
+	event.Data = data
+	e.room.Stream(&event, username)
+}
+
+// OnGameFinished is called by the room simulation when a player reaches the
+// points needed to win, the match is recorded and the room told who won
+func (e *engine) OnGameFinished(code string, player1 Player, player2 Player) {
+	go e.finishGame(code, player1, player2, "")
+}
+
+// finishGame records the match, forfeited is the player that gave it up if
+// it was not played to the end
+func (e *engine) finishGame(code string, player1 Player, player2 Player, forfeited string) {
+	// Practice against the computer is neither recorded nor rated
+	var ratings map[string]services.RatingChange
+	if e.ai == nil {
+		ratings = e.service.RecordMatch(code, player1, player2, forfeited)
+	}
+
+	winner := player1.Username
+	if forfeited == player1.Username || (forfeited == "" && player2.Score > player1.Score) {
+		winner = player2.Username
+	}
+	data, err := utils.EncodeJSON(EventDataGameFinished{
+		Winner:       winner,
+		Forfeited:    forfeited,
+		Player1Score: player1.Score,
+		Player2Score: player2.Score,
+		Ratings:      ratings,
+	})
+	if err != nil {
+		e.service.Log.Error(err.Error())
+		return
+	}
+	event := ws.NewSimpleEvent(EventTypeGameFinished)
+	event.Data = data
+	e.room.Broadcast(&event)
+}
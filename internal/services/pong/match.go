@@ -0,0 +1,57 @@
+package pong
+
+import (
+	"context"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/models"
+	"github.com/FredericoBento/HandGame/internal/services"
+)
+
+// RecordMatch stores the result of a finished game and updates the ratings of
+// both players, the rating changes are returned so they can be sent along with
+// the result. forfeited is the player that gave the game up, it is empty for
+// games played to the end. It waits on the database, do not call it from the
+// simulation
+func (s *PongService) RecordMatch(code string, player1 Player, player2 Player, forfeited string) map[string]services.RatingChange {
+	match := newMatch(code, player1, player2, forfeited)
+	if s.matches != nil {
+		if err := s.matches.Create(context.Background(), match); err != nil {
+			s.Log.Error("Could not record match: "+err.Error(), "code", code)
+		}
+	}
+	if s.ratings == nil {
+		return nil
+	}
+	changes, err := s.ratings.UpdateRatings(context.Background(), match.Game, match.Participants)
+	if err != nil {
+		s.Log.Error("Could not update ratings: "+err.Error(), "code", code)
+		return nil
+	}
+	return changes
+}
+
+// newMatch builds the match of a finished game, a player that forfeited
+// loses whatever the score
+func newMatch(code string, player1 Player, player2 Player, forfeited string) *models.Match {
+	result1, result2 := models.MatchResultTie, models.MatchResultTie
+	if forfeited == player2.Username {
+		result1, result2 = models.MatchResultWin, models.MatchResultLoss
+	} else if forfeited == player1.Username {
+		result1, result2 = models.MatchResultLoss, models.MatchResultWin
+	} else if player1.Score > player2.Score {
+		result1, result2 = models.MatchResultWin, models.MatchResultLoss
+	} else if player2.Score > player1.Score {
+		result1, result2 = models.MatchResultLoss, models.MatchResultWin
+	}
+
+	return &models.Match{
+		Game:       models.GamePong,
+		RoomCode:   code,
+		FinishedAt: time.Now(),
+		Participants: []models.MatchParticipant{
+			{Username: player1.Username, Score: player1.Score, Result: result1},
+			{Username: player2.Username, Score: player2.Score, Result: result2},
+		},
+	}
+}
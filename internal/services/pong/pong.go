@@ -1,83 +1,133 @@
 package pong
 
 import (
-	"errors"
 	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/FredericoBento/HandGame/internal/database/repository"
 	"github.com/FredericoBento/HandGame/internal/logger"
 	"github.com/FredericoBento/HandGame/internal/middleware"
+	"github.com/FredericoBento/HandGame/internal/models"
 	"github.com/FredericoBento/HandGame/internal/services"
+	"github.com/FredericoBento/HandGame/internal/services/gameroom"
+	"github.com/FredericoBento/HandGame/internal/services/presence"
 	"github.com/FredericoBento/HandGame/internal/ws"
 	"github.com/gorilla/websocket"
 )
 
 type PongService struct {
-	Name       string
-	Status     *services.Status
-	Log        *slog.Logger
-	Hub        *ws.Hub
-	GameStates map[string]*GameState
+	Name    string
+	Status  *services.Status
+	Log     *slog.Logger
+	rooms   *gameroom.Manager
+	clock   Clock
+	matches repository.MatchRepository
+	ratings *services.RatingService
+	// presence is told who plays where, it is optional
+	presence *presence.Service
+
+	reconnectGrace time.Duration
 }
 
+type PongServiceOption func(*PongService)
+
 var (
 	upgrader = websocket.Upgrader{
 		CheckOrigin:     func(r *http.Request) bool { return true },
 		ReadBufferSize:  512,
 		WriteBufferSize: 512,
+		Subprotocols:    []string{SubprotocolProtobuf, SubprotocolJSON},
+	}
+
+	events = gameroom.Events{
+		Create:           EventTypeCreateRoom,
+		Join:             EventTypeJoinRoom,
+		Spectate:         EventTypeSpectateRoom,
+		SpectatorsUpdate: EventTypeSpectatorsUpdate,
+		FindMatch:        EventTypeFindMatch,
+		CancelFindMatch:  EventTypeCancelFindMatch,
+		Countdown:        EventTypeReconnectCountdown,
+		Chat:             EventTypeMessage,
+		ChatHistory:      EventTypeChatHistory,
 	}
+)
 
-	ErrInvalidCode = errors.New("Invalid Code, Room does not exists")
+const (
+	default_reconnect_grace = 30 * time.Second
 )
 
-func NewPongService() *PongService {
+// WithClock replaces the time source used by the room simulations
+func WithClock(clock Clock) PongServiceOption {
+	return func(s *PongService) {
+		s.clock = clock
+	}
+}
+
+// WithMatchRepository makes the service record every finished game
+func WithMatchRepository(repo repository.MatchRepository) PongServiceOption {
+	return func(s *PongService) {
+		s.matches = repo
+	}
+}
+
+// WithRatingService makes the service update the ratings of the players of
+// every finished game
+func WithRatingService(ratings *services.RatingService) PongServiceOption {
+	return func(s *PongService) {
+		s.ratings = ratings
+	}
+}
+
+// WithPresence makes the service tell the lobby who plays where and which
+// rooms have a free seat
+func WithPresence(p *presence.Service) PongServiceOption {
+	return func(s *PongService) {
+		s.presence = p
+	}
+}
+
+// WithReconnectGrace sets how long a player that dropped has to come back
+// before the game is given to its opponent
+func WithReconnectGrace(grace time.Duration) PongServiceOption {
+	return func(s *PongService) {
+		s.reconnectGrace = grace
+	}
+}
+
+func NewPongService(opts ...PongServiceOption) *PongService {
 	lo, err := logger.NewServiceLogger("PongService", "", true)
 	if err != nil {
 		lo = slog.Default()
 	}
 	service := &PongService{
-		Name:       "PongService",
-		Status:     services.NewStatus(),
-		Log:        lo,
-		Hub:        ws.NewHub(),
-		GameStates: make(map[string]*GameState),
-	}
+		Name:   "PongService",
+		Status: services.NewStatus(),
+		Log:    lo,
+		clock:  NewRealClock(),
 
-	// go service.Hub.Run()
-	go service.Run(service.Hub)
+		reconnectGrace: default_reconnect_grace,
+	}
+	for _, option := range opts {
+		option(service)
+	}
+	service.rooms = gameroom.NewManager(gameroom.Config{
+		Game:           models.GamePong,
+		MaxPlayers:     2,
+		ReconnectGrace: service.reconnectGrace,
+		Events:         events,
+		NewEngine:      service.newEngine,
+		Ratings:        service.ratings,
+		Presence:       service.presence,
+	}, lo)
+	service.rooms.Run()
 	return service
 }
 
+// ReadMessageHandler hands the event over to the rooms, the rules of the
+// game are in engine.go
 func (s *PongService) ReadMessageHandler(client *ws.Client, event ws.Event) {
-	switch event.Type {
-	case ws.EventTypePing:
-		ws.HandleEventPing(&event, client)
-		return
-
-	case EventTypeMessage:
-		s.HandleEventMessage(&event, client)
-		return
-
-	case EventTypeCreateRoom:
-		s.HandleEventCreateRoom(&event, client)
-		return
-
-	case EventTypeJoinRoom:
-		s.HandleEventJoinRoom(&event, client)
-		return
-
-	case EventTypePaddleMoved:
-		s.HandleEventPaddleMove(&event, client)
-		return
-
-	case EventTypeBallShot:
-		s.HandleEventBallShot(&event, client)
-		return
-
-	default:
-		slog.Error("Unknown event received")
-		return
-	}
+	s.rooms.ReadMessageHandler(client, event)
 }
 
 func (s *PongService) HandleWebSocketConnection() http.HandlerFunc {
@@ -88,14 +138,15 @@ func (s *PongService) HandleWebSocketConnection() http.HandlerFunc {
 			return
 		}
 
-		conn, err := upgrader.Upgrade(w, r, nil)
+		conn, err := ws.Accept(&upgrader, w, r)
 		if err != nil {
-			s.Log.Error("Error upgrading to WebSocket:", err)
+			s.Log.Error("Error upgrading to WebSocket: " + err.Error())
 			return
 		}
 
 		client := ws.NewClient(conn, user.Username)
-		s.Hub.Register <- client
+		client.Binary = conn.Subprotocol() == SubprotocolProtobuf
+		s.rooms.Register(client)
 
 		// go func() {
 		// 	ticker := time.NewTicker(5 * time.Second)
@@ -109,7 +160,7 @@ func (s *PongService) HandleWebSocketConnection() http.HandlerFunc {
 		// 	}
 		// }()
 
-		go client.ReadPump(s.Hub, s.ReadMessageHandler)
+		go client.ReadPump(s.rooms.Hub, s.ReadMessageHandler)
 		go client.WritePump()
 	}
 }
@@ -0,0 +1,76 @@
+package pong
+
+import (
+	"errors"
+
+	pb "github.com/FredericoBento/HandGame/internal/models/protomodels"
+	"google.golang.org/protobuf/proto"
+)
+
+// Subprotocols offered on the pong websocket, a client that negotiates
+// SubprotocolProtobuf receives the hot path events (ball updates and goals)
+// as binary frames, everything else and every other client uses json
+const (
+	SubprotocolProtobuf = "pong.protobuf.v1"
+	SubprotocolJSON     = "pong.json.v1"
+)
+
+var (
+	ErrInvalidFrame = errors.New("Invalid binary frame")
+)
+
+// A binary frame is a single byte with the event type followed by the
+// protobuf encoding of the message that event carries (see pong_game.proto)
+//
+//	EventTypeBallUpdate -> Ball
+//	EventTypeGoal       -> PongGameState (only the player scores are set)
+func encodeFrame(eventType byte, message proto.Message) ([]byte, error) {
+	payload, err := proto.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	frame := make([]byte, 0, len(payload)+1)
+	frame = append(frame, eventType)
+	return append(frame, payload...), nil
+}
+
+// EncodeBallFrame encodes the ball position and velocity as a binary EventTypeBallUpdate frame
+func EncodeBallFrame(ball *Ball) ([]byte, error) {
+	return encodeFrame(EventTypeBallUpdate, &pb.Ball{
+		X:  float32(ball.Position.X),
+		Y:  float32(ball.Position.Y),
+		Vx: float32(ball.Dx),
+		Vy: float32(ball.Dy),
+	})
+}
+
+// EncodeGoalFrame encodes the current score as a binary EventTypeGoal frame
+func EncodeGoalFrame(player1Score int, player2Score int) ([]byte, error) {
+	return encodeFrame(EventTypeGoal, &pb.PongGameState{
+		Player1:    &pb.Player{Id: 1, Score: int32(player1Score)},
+		Player2:    &pb.Player{Id: 2, Score: int32(player2Score)},
+		HasStarted: true,
+	})
+}
+
+// DecodeFrame splits a binary frame into its event type and message
+func DecodeFrame(frame []byte) (int, proto.Message, error) {
+	if len(frame) < 1 {
+		return 0, nil, ErrInvalidFrame
+	}
+
+	var message proto.Message
+	switch int(frame[0]) {
+	case EventTypeBallUpdate:
+		message = &pb.Ball{}
+	case EventTypeGoal:
+		message = &pb.PongGameState{}
+	default:
+		return 0, nil, ErrInvalidFrame
+	}
+
+	if err := proto.Unmarshal(frame[1:], message); err != nil {
+		return 0, nil, err
+	}
+	return int(frame[0]), message, nil
+}
@@ -0,0 +1,75 @@
+package pong
+
+import (
+	"testing"
+
+	"github.com/FredericoBento/HandGame/internal/models"
+	pb "github.com/FredericoBento/HandGame/internal/models/protomodels"
+)
+
+func TestBallFrameRoundTrip(t *testing.T) {
+	ball := &Ball{Position: models.Vector2D{X: 120.5, Y: 80}, Dx: 1.5, Dy: -2}
+
+	frame, err := EncodeBallFrame(ball)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if frame[0] != EventTypeBallUpdate {
+		t.Fatalf("expected frame type %d, got %d", EventTypeBallUpdate, frame[0])
+	}
+
+	eventType, message, err := DecodeFrame(frame)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if eventType != EventTypeBallUpdate {
+		t.Errorf("expected event type %d, got %d", EventTypeBallUpdate, eventType)
+	}
+	decoded, ok := message.(*pb.Ball)
+	if !ok {
+		t.Fatalf("expected *Ball message, got %T", message)
+	}
+	if decoded.GetX() != 120.5 || decoded.GetY() != 80 || decoded.GetVx() != 1.5 || decoded.GetVy() != -2 {
+		t.Errorf("unexpected ball %v", decoded)
+	}
+}
+
+func TestGoalFrameRoundTrip(t *testing.T) {
+	frame, err := EncodeGoalFrame(3, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	eventType, message, err := DecodeFrame(frame)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if eventType != EventTypeGoal {
+		t.Errorf("expected event type %d, got %d", EventTypeGoal, eventType)
+	}
+	state, ok := message.(*pb.PongGameState)
+	if !ok {
+		t.Fatalf("expected *PongGameState message, got %T", message)
+	}
+	if state.GetPlayer1().GetScore() != 3 || state.GetPlayer2().GetScore() != 1 {
+		t.Errorf("expected score 3-1, got %d-%d", state.GetPlayer1().GetScore(), state.GetPlayer2().GetScore())
+	}
+}
+
+func TestDecodeInvalidFrame(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame []byte
+	}{
+		{"empty", []byte{}},
+		{"unknown type", []byte{EventTypePaddleMoved, 0x08, 0x01}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := DecodeFrame(tt.frame); err == nil {
+				t.Errorf("expected an error for frame %v", tt.frame)
+			}
+		})
+	}
+}
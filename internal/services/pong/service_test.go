@@ -0,0 +1,149 @@
+package pong
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/roomtest"
+	"github.com/FredericoBento/HandGame/internal/services/gameroom"
+	"github.com/FredericoBento/HandGame/internal/ws"
+)
+
+func startGame(t *testing.T, s *PongService) (string, *ws.Client, *ws.Client) {
+	p1 := roomtest.NewClient(s.rooms, "p1")
+	p2 := roomtest.NewClient(s.rooms, "p2")
+	roomtest.Send(t, s.rooms, p1, EventTypeCreateRoom, nil)
+	code := roomtest.WaitForEvent(t, p1, EventTypeCreatedRoom).RoomCode
+	roomtest.Send(t, s.rooms, p2, EventTypeJoinRoom, EventDataCodePlayer{Code: code, Player: "p2"})
+	roomtest.WaitForEvent(t, p2, EventTypeJoinedRoom)
+	return code, p1, p2
+}
+
+// TestConcurrentRoom is meant to be run with -race, the players, spectators
+// and the simulation all reach the room at once
+func TestConcurrentRoom(t *testing.T) {
+	clock := newFakeClock()
+	s := NewPongService(WithClock(clock), WithReconnectGrace(0))
+	p1 := roomtest.NewClient(s.rooms, "p1")
+	p2 := roomtest.NewClient(s.rooms, "p2")
+
+	roomtest.Send(t, s.rooms, p1, EventTypeCreateRoom, nil)
+	code := roomtest.WaitForEvent(t, p1, EventTypeCreatedRoom).RoomCode
+	roomtest.Send(t, s.rooms, p2, EventTypeJoinRoom, EventDataCodePlayer{Code: code, Player: "p2"})
+	roomtest.WaitForEvent(t, p2, EventTypeJoinedRoom)
+	roomtest.Send(t, s.rooms, p1, EventTypeBallShot, nil)
+
+	var wg sync.WaitGroup
+	for _, player := range []*ws.Client{p1, p2} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 30; i++ {
+				roomtest.Send(t, s.rooms, player, EventTypePaddleMoved, EventPaddleMoveData{Paddle_y: float64(i)})
+			}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			spectator := roomtest.NewClient(s.rooms, fmt.Sprintf("spectator%d", i))
+			roomtest.Send(t, s.rooms, spectator, EventTypeSpectateRoom, gameroom.EventDataCode{Code: code})
+			s.rooms.Hub.Unregister <- spectator
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 30; i++ {
+			clock.Advance(simulation_timestep)
+		}
+	}()
+	wg.Wait()
+
+	s.rooms.Hub.Unregister <- p1
+	roomtest.WaitForEvent(t, p2, ws.EventTypeUserDisconnected)
+	s.rooms.Hub.Unregister <- p2
+
+	var roomExists bool
+	s.rooms.Do(func() {
+		_, roomExists = s.rooms.Room(code)
+	})
+	if roomExists {
+		t.Errorf("expected room %s to be closed", code)
+	}
+}
+
+func TestReconnectWithinGrace(t *testing.T) {
+	s := NewPongService(WithClock(newFakeClock()), WithReconnectGrace(time.Minute))
+	code, p1, p2 := startGame(t, s)
+
+	s.rooms.Hub.Unregister <- p2
+	roomtest.WaitForEvent(t, p1, EventTypeReconnectCountdown)
+
+	p2 = roomtest.NewClient(s.rooms, "p2")
+	roomtest.Send(t, s.rooms, p2, EventTypeJoinRoom, EventDataCodePlayer{Code: code, Player: "p2"})
+	event := roomtest.WaitForEvent(t, p2, EventTypeSyncGameState)
+	type Data struct {
+		Code  string    `json:"code"`
+		State GameState `json:"state"`
+	}
+	data := Data{}
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Code != code || data.State.Player2 == nil || !data.State.Player2.Connected {
+		t.Errorf("expected p2 to be back in %s but got: %+v", code, data)
+	}
+	roomtest.WaitForEvent(t, p1, EventTypePlayerReconnected)
+}
+
+func TestForfeitAfterGrace(t *testing.T) {
+	s := NewPongService(WithClock(newFakeClock()), WithReconnectGrace(50*time.Millisecond))
+	_, p1, p2 := startGame(t, s)
+
+	s.rooms.Hub.Unregister <- p2
+	event := roomtest.WaitForEvent(t, p1, EventTypeGameFinished)
+	type Data struct {
+		Winner    string `json:"winner"`
+		Forfeited string `json:"forfeited"`
+	}
+	data := Data{}
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Winner != "p1" || data.Forfeited != "p2" {
+		t.Errorf("expected p1 to win by forfeit but got: %+v", data)
+	}
+}
+
+func TestPracticeRoom(t *testing.T) {
+	s := NewPongService(WithClock(newFakeClock()))
+	p1 := roomtest.NewClient(s.rooms, "p1")
+	roomtest.Send(t, s.rooms, p1, EventTypeCreateRoom, map[string]any{"ai": AIOptions{ReactionMs: 100}})
+	code := roomtest.WaitForEvent(t, p1, EventTypeCreatedRoom).RoomCode
+
+	event := roomtest.WaitForEvent(t, p1, EventTypePlayerJoinedRoom)
+	data := EventDataCodePlayer{}
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Code != code || data.Player != ai_username {
+		t.Errorf("expected the computer to join %s but got: %+v", code, data)
+	}
+
+	// The seat of the computer is taken
+	p2 := roomtest.NewClient(s.rooms, "p2")
+	roomtest.Send(t, s.rooms, p2, EventTypeJoinRoom, EventDataCodePlayer{Code: code, Player: "p2"})
+	select {
+	case event := <-p2.Event:
+		if !event.IsError {
+			t.Errorf("expected p2 to be refused but got event %d", event.Type)
+		}
+	case <-time.After(2 * time.Second):
+		t.Errorf("expected p2 to be refused but got nothing")
+	}
+}
@@ -0,0 +1,254 @@
+package pong
+
+import (
+	"time"
+)
+
+const (
+	// simulation_timestep is the fixed step of the physics, ball speeds are
+	// expressed in units per step
+	simulation_timestep = 10 * time.Millisecond
+
+	// max_steps_per_tick avoids a spiral of death when the ticker falls behind
+	max_steps_per_tick = 5
+)
+
+// SimulationListener receives the outputs of a room simulation, it is called
+// from the simulation goroutine so implementations must not block on it
+type SimulationListener interface {
+	OnBallUpdate(code string, ball Ball)
+	OnGoal(code string, player1Score int, player2Score int)
+	OnPaddleMoved(code string, username string, y float64)
+	OnGameFinished(code string, player1 Player, player2 Player)
+}
+
+type simulationCommand func(state *GameState)
+
+// Simulation runs the game loop of a single room, it is the only owner of the
+// GameState once started, every change goes through its command channel
+type Simulation struct {
+	Code     string
+	state    *GameState
+	clock    Clock
+	listener SimulationListener
+	commands chan simulationCommand
+	stop     chan struct{}
+	done     chan struct{}
+
+	accumulator time.Duration
+	lastTick    time.Time
+
+	// ai moves the paddle of Player2 when it plays, if set
+	ai *AIPaddle
+}
+
+func NewSimulation(code string, state *GameState, clock Clock, listener SimulationListener) *Simulation {
+	if clock == nil {
+		clock = NewRealClock()
+	}
+	return &Simulation{
+		Code:     code,
+		state:    state,
+		clock:    clock,
+		listener: listener,
+		commands: make(chan simulationCommand),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+func (sim *Simulation) Start() {
+	go sim.run()
+}
+
+// Stop ends the game loop and waits for it to exit
+func (sim *Simulation) Stop() {
+	select {
+	case <-sim.stop:
+	default:
+		close(sim.stop)
+	}
+	<-sim.done
+}
+
+// Do runs f inside the simulation goroutine and waits for it to finish, it
+// is how handlers read or change the state without racing with the loop
+func (sim *Simulation) Do(f func(state *GameState)) bool {
+	finished := make(chan struct{})
+	command := func(state *GameState) {
+		f(state)
+		close(finished)
+	}
+	select {
+	case sim.commands <- command:
+	case <-sim.done:
+		return false
+	}
+	<-finished
+	return true
+}
+
+// MovePaddle moves the paddle of the player and relays the new position to the room
+func (sim *Simulation) MovePaddle(username string, y float64) {
+	sim.Do(func(state *GameState) {
+		if state.Player1 == nil || state.Player2 == nil {
+			return
+		}
+		var paddle *Paddle
+		if state.Player1.Username == username {
+			state.UpdatePlayer1Paddle(y)
+			paddle = state.Player1.Paddle
+		} else if state.Player2.Username == username {
+			state.UpdatePlayer2Paddle(y)
+			paddle = state.Player2.Paddle
+		} else {
+			return
+		}
+		sim.listener.OnPaddleMoved(sim.Code, username, paddle.Position.Y)
+	})
+}
+
+// ShootBall serves the ball away from the player that shot it, a ball
+// already in play is left untouched
+func (sim *Simulation) ShootBall(username string) {
+	sim.Do(func(state *GameState) {
+		if state.Ball.Direction != ball_direction_none {
+			return
+		}
+		if state.Player1 == nil || state.Player2 == nil {
+			return
+		}
+		if state.Player1.Username != username && state.Player2.Username != username {
+			return
+		}
+		if !state.Player1.Connected || !state.Player2.Connected {
+			return
+		}
+		if state.Status == game_status_finished {
+			state.Player1.Score = 0
+			state.Player2.Score = 0
+		}
+		state.Status = game_status_running
+		if state.Player1.Username == username {
+			state.Ball.Direction = ball_direction_right
+			state.Ball.Dx = state.Ball.Speed
+		} else if state.Player2.Username == username {
+			state.Ball.Direction = ball_direction_left
+			state.Ball.Dx = -state.Ball.Speed
+		}
+	})
+}
+
+func (sim *Simulation) run() {
+	defer close(sim.done)
+
+	ticker := sim.clock.NewTicker(simulation_timestep)
+	defer ticker.Stop()
+
+	sim.lastTick = sim.clock.Now()
+
+	for {
+		select {
+		case <-sim.stop:
+			return
+
+		case command := <-sim.commands:
+			command(sim.state)
+
+		case <-ticker.C():
+			sim.tick()
+		}
+	}
+}
+
+// tick advances the simulation by as many fixed steps as the elapsed time
+// allows, the ball position is sent once per tick
+func (sim *Simulation) tick() {
+	now := sim.clock.Now()
+	sim.accumulator += now.Sub(sim.lastTick)
+	sim.lastTick = now
+
+	steps := 0
+	moved := false
+	for sim.accumulator >= simulation_timestep {
+		sim.accumulator -= simulation_timestep
+		if steps >= max_steps_per_tick {
+			continue
+		}
+		if sim.step() {
+			moved = true
+		}
+		steps++
+	}
+
+	if moved {
+		sim.listener.OnBallUpdate(sim.Code, *sim.state.Ball)
+	}
+	sim.driveAI(now, time.Duration(steps)*simulation_timestep)
+}
+
+// driveAI lets the AI move its paddle through UpdatePlayer2Paddle, like a
+// player would, once per tick
+func (sim *Simulation) driveAI(now time.Time, elapsed time.Duration) {
+	state := sim.state
+	if sim.ai == nil || state.Player1 == nil || state.Player2 == nil || state.Player2.Username != sim.ai.Username {
+		return
+	}
+	y, moved := sim.ai.Move(now, state, elapsed)
+	if !moved {
+		return
+	}
+	state.UpdatePlayer2Paddle(y)
+	sim.listener.OnPaddleMoved(sim.Code, sim.ai.Username, y)
+}
+
+// step advances the ball one fixed timestep, it returns false when the ball is not in play
+func (sim *Simulation) step() bool {
+	state := sim.state
+	if state.Ball == nil || state.Player1 == nil || state.Player2 == nil {
+		return false
+	}
+	// The ball waits for a player that dropped to come back
+	if !state.Player1.Connected || !state.Player2.Connected {
+		return false
+	}
+	if state.Ball.Direction == ball_direction_none {
+		return false
+	}
+
+	if state.Ball.is_collision(state.Player1.Paddle) {
+		state.Ball.handle_collision(state.Player1.Paddle)
+	} else if state.Ball.is_collision(state.Player2.Paddle) {
+		state.Ball.handle_collision(state.Player2.Paddle)
+	}
+
+	state.Ball.check_wall_collision(25, state.Canvas.Height+25)
+
+	if state.Ball.Position.X-state.Ball.Radius <= 0 {
+		state.Player2.Score += 1
+		sim.goal()
+		return true
+	}
+	if state.Ball.Position.X+state.Ball.Radius >= state.Canvas.Width {
+		state.Player1.Score += 1
+		sim.goal()
+		return true
+	}
+
+	state.Ball.LastPosition = state.Ball.Position
+	state.Ball.Position.X += state.Ball.Dx
+	state.Ball.Position.Y += state.Ball.Dy
+	return true
+}
+
+func (sim *Simulation) goal() {
+	state := sim.state
+	state.Ball.recenter(state.Canvas.Width, state.Canvas.Height+50)
+	sim.listener.OnGoal(sim.Code, state.Player1.Score, state.Player2.Score)
+
+	if state.Player1.Score >= points_to_win || state.Player2.Score >= points_to_win {
+		// The score stays on the board until the next game is served
+		state.Status = game_status_finished
+		sim.listener.OnGameFinished(sim.Code, *state.Player1, *state.Player2)
+	}
+}
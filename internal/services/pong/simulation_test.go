@@ -0,0 +1,234 @@
+package pong
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/models"
+)
+
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	ticker *fakeTicker
+}
+
+type fakeTicker struct {
+	c chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{
+		now:    time.Unix(0, 0),
+		ticker: &fakeTicker{c: make(chan time.Time)},
+	}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) Ticker {
+	return c.ticker
+}
+
+// Advance moves the clock forward and fires the ticker once
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	now := c.now
+	c.mu.Unlock()
+	c.ticker.c <- now
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTicker) Stop() {}
+
+type recordingListener struct {
+	mu      sync.Mutex
+	updates []models.Vector2D
+	goals   [][2]int
+	paddles []float64
+	results [][2]Player
+}
+
+func (l *recordingListener) OnBallUpdate(code string, ball Ball) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.updates = append(l.updates, ball.Position)
+}
+
+func (l *recordingListener) OnGoal(code string, player1Score int, player2Score int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.goals = append(l.goals, [2]int{player1Score, player2Score})
+}
+
+func (l *recordingListener) OnPaddleMoved(code string, username string, y float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.paddles = append(l.paddles, y)
+}
+
+func (l *recordingListener) OnGameFinished(code string, player1 Player, player2 Player) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.results = append(l.results, [2]Player{player1, player2})
+}
+
+func newTestSimulation(t *testing.T) (*Simulation, *fakeClock, *recordingListener) {
+	state := NewGameState(nil, 0, 0)
+	state.AddPlayer("p1", nil)
+	state.AddPlayer("p2", nil)
+
+	clock := newFakeClock()
+	listener := &recordingListener{}
+	sim := NewSimulation("abcd", state, clock, listener)
+	sim.Start()
+	t.Cleanup(sim.Stop)
+	return sim, clock, listener
+}
+
+func ballPosition(sim *Simulation) models.Vector2D {
+	var position models.Vector2D
+	sim.Do(func(state *GameState) {
+		position = state.Ball.Position
+	})
+	return position
+}
+
+func TestSimulationIdleBall(t *testing.T) {
+	sim, clock, listener := newTestSimulation(t)
+	start := ballPosition(sim)
+
+	clock.Advance(simulation_timestep)
+	clock.Advance(simulation_timestep)
+
+	if ballPosition(sim) != start {
+		t.Errorf("expected ball to stay still before being shot")
+	}
+	if len(listener.updates) != 0 {
+		t.Errorf("expected no ball updates, got %d", len(listener.updates))
+	}
+}
+
+func TestSimulationFixedTimestep(t *testing.T) {
+	sim, clock, listener := newTestSimulation(t)
+	start := ballPosition(sim)
+
+	sim.ShootBall("p1")
+	clock.Advance(simulation_timestep)
+
+	position := ballPosition(sim)
+	if position.X != start.X+default_ball_speed {
+		t.Errorf("expected ball x %v after one step, got %v", start.X+default_ball_speed, position.X)
+	}
+
+	// A late tick runs every step it missed but sends a single update
+	clock.Advance(3 * simulation_timestep)
+
+	position = ballPosition(sim)
+	if position.X != start.X+4*default_ball_speed {
+		t.Errorf("expected ball x %v after four steps, got %v", start.X+4*default_ball_speed, position.X)
+	}
+	if len(listener.updates) != 2 {
+		t.Errorf("expected 2 ball updates, got %d", len(listener.updates))
+	}
+
+	// Shooting a ball already in play does nothing
+	sim.ShootBall("p2")
+	clock.Advance(simulation_timestep)
+	if ballPosition(sim).X <= position.X {
+		t.Errorf("expected ball to keep going right")
+	}
+}
+
+func TestSimulationGoal(t *testing.T) {
+	sim, clock, listener := newTestSimulation(t)
+
+	sim.Do(func(state *GameState) {
+		state.Ball.Position.X = state.Canvas.Width - state.Ball.Radius - 1
+		state.Ball.Position.Y = 60
+		state.Ball.Direction = ball_direction_right
+		state.Ball.Dx = state.Ball.Speed
+	})
+	clock.Advance(simulation_timestep)
+	clock.Advance(simulation_timestep)
+
+	if len(listener.goals) != 1 {
+		t.Fatalf("expected exactly one goal, got %d", len(listener.goals))
+	}
+	if listener.goals[0] != [2]int{1, 0} {
+		t.Errorf("expected score 1-0, got %v", listener.goals[0])
+	}
+
+	sim.Do(func(state *GameState) {
+		if state.Ball.Direction != ball_direction_none {
+			t.Errorf("expected ball to wait for a new shot after the goal")
+		}
+	})
+}
+
+func TestSimulationGameFinished(t *testing.T) {
+	sim, clock, listener := newTestSimulation(t)
+
+	sim.Do(func(state *GameState) {
+		state.Player2.Score = points_to_win - 1
+		state.Ball.Position.X = state.Ball.Radius + 1
+		state.Ball.Position.Y = 60
+		state.Ball.Direction = ball_direction_left
+		state.Ball.Dx = -state.Ball.Speed
+	})
+	clock.Advance(simulation_timestep)
+	clock.Advance(simulation_timestep)
+
+	if len(listener.results) != 1 {
+		t.Fatalf("expected the game to finish once, got %d", len(listener.results))
+	}
+	result := listener.results[0]
+	if result[0].Username != "p1" || result[1].Score != points_to_win {
+		t.Errorf("unexpected result %v", result)
+	}
+
+	// Serving again starts a new game from zero
+	sim.ShootBall("p1")
+	sim.Do(func(state *GameState) {
+		if state.Status != game_status_running {
+			t.Errorf("expected a running game after serving")
+		}
+		if state.Player1.Score != 0 || state.Player2.Score != 0 {
+			t.Errorf("expected scores to reset, got %d-%d", state.Player1.Score, state.Player2.Score)
+		}
+	})
+}
+
+func TestSimulationPaddleMove(t *testing.T) {
+	sim, _, listener := newTestSimulation(t)
+
+	sim.MovePaddle("p2", 100)
+	sim.MovePaddle("someone", 50)
+
+	sim.Do(func(state *GameState) {
+		if state.Player2.Paddle.Position.Y != 100 {
+			t.Errorf("expected paddle at 100, got %v", state.Player2.Paddle.Position.Y)
+		}
+	})
+	if len(listener.paddles) != 1 {
+		t.Errorf("expected a single relayed paddle move, got %d", len(listener.paddles))
+	}
+}
+
+func TestSimulationStop(t *testing.T) {
+	sim, _, _ := newTestSimulation(t)
+	sim.Stop()
+
+	if sim.Do(func(state *GameState) {}) {
+		t.Errorf("expected commands to be refused after stop")
+	}
+}
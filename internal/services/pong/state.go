@@ -67,6 +67,8 @@ const (
 
 	default_ball_speed  = 5
 	default_ball_radius = 7
+
+	points_to_win = 5
 )
 
 func NewPaddle(position models.Vector2D, length float64, width float64, speed float64) *Paddle {
@@ -208,7 +210,7 @@ func (state *GameState) UpdatePlayer1Paddle(y float64) {
 
 func (state *GameState) UpdatePlayer2Paddle(y float64) {
 	if state.Player2 != nil {
-		state.Player2.Paddle.LastPosition = state.Player1.Paddle.Position
+		state.Player2.Paddle.LastPosition = state.Player2.Paddle.Position
 		state.Player2.Paddle.Position.Y = y
 	}
 }
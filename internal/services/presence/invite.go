@@ -0,0 +1,164 @@
+package presence
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/FredericoBento/HandGame/internal/utils"
+	"github.com/FredericoBento/HandGame/internal/ws"
+)
+
+const (
+	// EventTypeInvite reaches the lobby sockets of the invited user
+	EventTypeInvite = 2
+	// EventTypeInviteAnswer is sent by the invited user and passed on to the
+	// user that invited
+	EventTypeInviteAnswer = 3
+	// EventTypeInviteAnswered tells every lobby socket of the invited user
+	// that the invite was answered, along with the answer
+	EventTypeInviteAnswered = 4
+)
+
+var (
+	ErrNotOnline   = errors.New("user is not online")
+	ErrNotInRoom   = errors.New("you are not playing in a room of this game")
+	ErrRoomNotOpen = errors.New("room is full or closed")
+
+	ErrInvalidAnswer = ws.NewError(ws.ErrorCodeInvalidData, "invalid invite answer")
+)
+
+// Invite asks To to join the room From plays in
+type Invite struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Game string `json:"game"`
+	Code string `json:"code"`
+}
+
+type InviteAnswer struct {
+	Invite
+	Accepted bool `json:"accepted"`
+}
+
+func (i Invite) key() string {
+	return i.From + "/" + i.To + "/" + i.Game + "/" + i.Code
+}
+
+// Invite asks to to join the open room from plays in, whether the two
+// may invite each other is up to the caller
+func (s *Service) Invite(from string, to string, game string) (Invite, error) {
+	var invite Invite
+	var err error
+	s.Hub.Do(func() {
+		invite, err = s.invite(from, to, game)
+	})
+	return invite, err
+}
+
+// OpenRooms returns the open rooms the user plays in, the ones it can invite
+// someone to
+func (s Snapshot) OpenRooms(username string) []Room {
+	rooms := []Room{}
+	for _, user := range s.Users {
+		if user.Username != username {
+			continue
+		}
+		for _, activity := range user.Activities {
+			if activity.Spectating {
+				continue
+			}
+			for _, room := range s.Rooms {
+				if room.Game == activity.Game && room.Code == activity.Code {
+					rooms = append(rooms, room)
+				}
+			}
+		}
+	}
+	return rooms
+}
+
+// Online tells whether the user is in the snapshot
+func (s Snapshot) Online(username string) bool {
+	for _, user := range s.Users {
+		if user.Username == username {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) invite(from string, to string, game string) (Invite, error) {
+	u, ok := s.users[from]
+	if !ok {
+		return Invite{}, ErrNotInRoom
+	}
+	activity, ok := u.activities[game]
+	if !ok || activity.Spectating {
+		return Invite{}, ErrNotInRoom
+	}
+	invite := Invite{From: from, To: to, Game: game, Code: activity.Code}
+	if _, ok := s.rooms[game+"/"+activity.Code]; !ok {
+		return Invite{}, ErrRoomNotOpen
+	}
+	// Only the lobby sockets can show the invite
+	connections := s.Hub.Connections(to)
+	if len(connections) == 0 {
+		return Invite{}, ErrNotOnline
+	}
+
+	data, err := utils.EncodeJSON(invite)
+	if err != nil {
+		return Invite{}, err
+	}
+	s.invites[invite.key()] = invite
+	s.sendTo(to, EventTypeInvite, data)
+	return invite, nil
+}
+
+// answer passes the answer on if it matches an invite still pending
+func (s *Service) answer(answer InviteAnswer) {
+	invite, ok := s.invites[answer.key()]
+	if !ok {
+		return
+	}
+	delete(s.invites, answer.key())
+	answer.Invite = invite
+
+	data, err := utils.EncodeJSON(answer)
+	if err != nil {
+		s.Log.Error(err.Error())
+		return
+	}
+	s.sendTo(invite.From, EventTypeInviteAnswer, data)
+	s.sendTo(invite.To, EventTypeInviteAnswered, data)
+}
+
+func (s *Service) sendTo(username string, eventType ws.EventType, data json.RawMessage) {
+	event := ws.NewSimpleEvent(eventType)
+	event.Data = data
+	for _, client := range s.Hub.Connections(username) {
+		client.SendEvent(&event)
+	}
+}
+
+func (s *Service) handleInviteAnswer(client *ws.Client, event ws.Event) {
+	var answer InviteAnswer
+	if err := json.Unmarshal(event.Data, &answer); err != nil {
+		client.SendErrorEventWithError(&event, ErrInvalidAnswer)
+		return
+	}
+	// Nobody answers for somebody else
+	answer.To = client.Username
+	s.Hub.Post(func() {
+		s.answer(answer)
+	})
+}
+
+// dropInvites forgets the invites to a room that can no longer be joined
+func (s *Service) dropInvites(game string, code string) {
+	for key, invite := range s.invites {
+		if invite.Game == game && invite.Code == code {
+			delete(s.invites, key)
+		}
+	}
+}
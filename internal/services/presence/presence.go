@@ -0,0 +1,292 @@
+package presence
+
+import (
+	"log/slog"
+	"net/http"
+	"sort"
+
+	"github.com/FredericoBento/HandGame/internal/logger"
+	"github.com/FredericoBento/HandGame/internal/middleware"
+	"github.com/FredericoBento/HandGame/internal/utils"
+	"github.com/FredericoBento/HandGame/internal/ws"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// EventTypeLobbyUpdate carries the whole Snapshot to the lobby clients
+	// every time someone comes, goes or changes what they are doing
+	EventTypeLobbyUpdate = 1
+)
+
+var (
+	upgrader = websocket.Upgrader{
+		CheckOrigin:     func(r *http.Request) bool { return true },
+		ReadBufferSize:  512,
+		WriteBufferSize: 512,
+	}
+)
+
+// Activity is what a user does in one game, a user with no activity is idle
+type Activity struct {
+	Game       string `json:"game"`
+	Code       string `json:"code"`
+	Spectating bool   `json:"spectating,omitempty"`
+}
+
+// Room is an open room of a game that still has a free seat
+type Room struct {
+	Game       string `json:"game"`
+	Code       string `json:"code"`
+	Host       string `json:"host"`
+	Players    int    `json:"players"`
+	MaxPlayers int    `json:"max_players"`
+}
+
+// User is an online user and what it is doing, in every game it is in
+type User struct {
+	Username   string     `json:"username"`
+	Activities []Activity `json:"activities"`
+}
+
+func (u User) Idle() bool {
+	return len(u.Activities) == 0
+}
+
+// Snapshot is everyone online and every open room, sorted
+type Snapshot struct {
+	Users []User `json:"users"`
+	Rooms []Room `json:"rooms"`
+}
+
+type user struct {
+	// connections counts the lobby and game sockets of the user, it is
+	// online while there is at least one
+	connections int
+	activities  map[string]Activity
+}
+
+// Service knows who is online and which rooms can be joined. Games report to
+// it from any goroutine, everything runs on the goroutine of its hub, that
+// also serves the lobby clients
+type Service struct {
+	Hub *ws.Hub
+	Log *slog.Logger
+
+	users map[string]*user
+	rooms map[string]Room
+	// invites are the invites still waiting for an answer
+	invites map[string]Invite
+	// pending is true while an update of the lobby is queued, the changes
+	// posted before it runs go out together
+	pending bool
+}
+
+func NewService() *Service {
+	lo, err := logger.NewServiceLogger("PresenceService", "", true)
+	if err != nil {
+		lo = slog.Default()
+	}
+	s := &Service{
+		Hub:     ws.NewHub(),
+		Log:     lo,
+		users:   make(map[string]*user),
+		rooms:   make(map[string]Room),
+		invites: make(map[string]Invite),
+	}
+	go s.Hub.Run(s)
+	return s
+}
+
+func (s *Service) OnRegister(client *ws.Client) {
+	s.Hub.AddClient(client)
+	// The update queued by connect reaches the new client as well
+	s.connect(client.Username)
+}
+
+func (s *Service) OnUnregister(client *ws.Client) {
+	s.Hub.RemoveClient(client)
+	client.Close()
+	s.disconnect(client.Username)
+}
+
+// ReadMessageHandler answers pings and invites, lobby clients only listen
+// otherwise
+func (s *Service) ReadMessageHandler(client *ws.Client, event ws.Event) {
+	switch event.Type {
+	case ws.EventTypePing:
+		ws.HandleEventPing(&event, client)
+	case EventTypeInviteAnswer:
+		s.handleInviteAnswer(client, event)
+	}
+}
+
+func (s *Service) HandleWebSocketConnection() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, isLogged := middleware.GetUserFromContext(r)
+		if !isLogged {
+			s.Log.Error("Error User not logged:")
+			return
+		}
+
+		conn, err := ws.Accept(&upgrader, w, r)
+		if err != nil {
+			s.Log.Error("Error upgrading to WebSocket: " + err.Error())
+			return
+		}
+
+		client := ws.NewClient(conn, user.Username)
+		s.Hub.Register <- client
+
+		go client.ReadPump(s.Hub, s.ReadMessageHandler)
+		go client.WritePump()
+	}
+}
+
+// Connect counts a game socket of the user, it is online until every one of
+// them is gone, see Disconnect
+func (s *Service) Connect(username string) {
+	s.Hub.Post(func() {
+		s.connect(username)
+	})
+}
+
+func (s *Service) Disconnect(username string) {
+	s.Hub.Post(func() {
+		s.disconnect(username)
+	})
+}
+
+// SetActivity sets what the user does in the game of the activity, an
+// activity without a code clears it
+func (s *Service) SetActivity(username string, activity Activity) {
+	s.Hub.Post(func() {
+		u, ok := s.users[username]
+		if !ok {
+			return
+		}
+		if activity.Code == "" {
+			delete(u.activities, activity.Game)
+		} else {
+			u.activities[activity.Game] = activity
+		}
+		s.changed()
+	})
+}
+
+// OpenRoom lists the room as joinable, or updates it if it already is
+func (s *Service) OpenRoom(room Room) {
+	s.Hub.Post(func() {
+		s.rooms[room.Game+"/"+room.Code] = room
+		s.changed()
+	})
+}
+
+// CloseRoom takes the room out of the joinable ones, once full or closed
+func (s *Service) CloseRoom(game string, code string) {
+	s.Hub.Post(func() {
+		key := game + "/" + code
+		if _, ok := s.rooms[key]; !ok {
+			return
+		}
+		delete(s.rooms, key)
+		s.dropInvites(game, code)
+		s.changed()
+	})
+}
+
+// Snapshot returns who is online and the open rooms as they are now
+func (s *Service) Snapshot() Snapshot {
+	var snapshot Snapshot
+	s.Hub.Do(func() {
+		snapshot = s.snapshot()
+	})
+	return snapshot
+}
+
+func (s *Service) connect(username string) {
+	u, ok := s.users[username]
+	if !ok {
+		u = &user{activities: make(map[string]Activity)}
+		s.users[username] = u
+	}
+	u.connections++
+	s.changed()
+}
+
+func (s *Service) disconnect(username string) {
+	u, ok := s.users[username]
+	if !ok {
+		return
+	}
+	u.connections--
+	if u.connections <= 0 {
+		delete(s.users, username)
+	}
+	s.changed()
+}
+
+// changed queues an update of the lobby unless one is queued already
+func (s *Service) changed() {
+	if s.pending {
+		return
+	}
+	s.pending = true
+	s.Hub.Post(func() {
+		s.pending = false
+		s.broadcast()
+	})
+}
+
+func (s *Service) snapshot() Snapshot {
+	snapshot := Snapshot{
+		Users: make([]User, 0, len(s.users)),
+		Rooms: make([]Room, 0, len(s.rooms)),
+	}
+	for username, u := range s.users {
+		activities := make([]Activity, 0, len(u.activities))
+		for _, activity := range u.activities {
+			activities = append(activities, activity)
+		}
+		sort.Slice(activities, func(i, j int) bool {
+			return activities[i].Game < activities[j].Game
+		})
+		snapshot.Users = append(snapshot.Users, User{Username: username, Activities: activities})
+	}
+	sort.Slice(snapshot.Users, func(i, j int) bool {
+		return snapshot.Users[i].Username < snapshot.Users[j].Username
+	})
+	for _, room := range s.rooms {
+		snapshot.Rooms = append(snapshot.Rooms, room)
+	}
+	sort.Slice(snapshot.Rooms, func(i, j int) bool {
+		if snapshot.Rooms[i].Game != snapshot.Rooms[j].Game {
+			return snapshot.Rooms[i].Game < snapshot.Rooms[j].Game
+		}
+		return snapshot.Rooms[i].Code < snapshot.Rooms[j].Code
+	})
+	return snapshot
+}
+
+func (s *Service) snapshotEvent() (*ws.Event, error) {
+	event := ws.NewSimpleEvent(EventTypeLobbyUpdate)
+	data, err := utils.EncodeJSON(s.snapshot())
+	if err != nil {
+		return nil, err
+	}
+	event.Data = data
+	return &event, nil
+}
+
+func (s *Service) broadcast() {
+	if len(s.Hub.Clients) == 0 {
+		return
+	}
+	event, err := s.snapshotEvent()
+	if err != nil {
+		s.Log.Error(err.Error())
+		return
+	}
+	for _, client := range s.Hub.Clients {
+		client.SendEvent(event)
+	}
+}
@@ -0,0 +1,165 @@
+package presence
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/ws"
+)
+
+func newTestClient(s *Service, username string) *ws.Client {
+	client := &ws.Client{Username: username, Event: make(chan *ws.Event, 64)}
+	s.Hub.Register <- client
+	return client
+}
+
+// nextSnapshot waits for a lobby update that satisfies ok
+func nextSnapshot(t *testing.T, client *ws.Client, ok func(Snapshot) bool) Snapshot {
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-client.Event:
+			snapshot := Snapshot{}
+			if err := json.Unmarshal(event.Data, &snapshot); err != nil {
+				t.Fatal(err)
+			}
+			if ok(snapshot) {
+				return snapshot
+			}
+		case <-timeout:
+			t.Fatalf("expected %s to get the lobby update but got nothing", client.Username)
+			return Snapshot{}
+		}
+	}
+}
+
+func TestPresenceTracksActivity(t *testing.T) {
+	s := NewService()
+	s.Connect("fred")
+	s.SetActivity("fred", Activity{Game: "tictactoe", Code: "ABCD"})
+
+	snapshot := s.Snapshot()
+	if len(snapshot.Users) != 1 || snapshot.Users[0].Username != "fred" {
+		t.Fatalf("expected fred to be online but got: %+v", snapshot.Users)
+	}
+	if activities := snapshot.Users[0].Activities; len(activities) != 1 || activities[0].Code != "ABCD" {
+		t.Errorf("expected fred to be in room ABCD but got: %+v", activities)
+	}
+
+	s.SetActivity("fred", Activity{Game: "tictactoe"})
+	if snapshot := s.Snapshot(); !snapshot.Users[0].Idle() {
+		t.Errorf("expected fred to be idle but got: %+v", snapshot.Users[0])
+	}
+
+	s.Disconnect("fred")
+	if snapshot := s.Snapshot(); len(snapshot.Users) != 0 {
+		t.Errorf("expected nobody online but got: %+v", snapshot.Users)
+	}
+}
+
+func TestPresenceStaysOnlineWhileConnected(t *testing.T) {
+	s := NewService()
+	s.Connect("fred")
+	s.Connect("fred")
+	s.Disconnect("fred")
+
+	if snapshot := s.Snapshot(); len(snapshot.Users) != 1 {
+		t.Errorf("expected fred to still be online but got: %+v", snapshot.Users)
+	}
+}
+
+func TestLobbyGetsUpdates(t *testing.T) {
+	s := NewService()
+	lobby := newTestClient(s, "bento")
+	nextSnapshot(t, lobby, func(snapshot Snapshot) bool {
+		return len(snapshot.Users) == 1
+	})
+
+	s.Connect("fred")
+	s.OpenRoom(Room{Game: "pong", Code: "WXYZ", Host: "fred", Players: 1, MaxPlayers: 2})
+	snapshot := nextSnapshot(t, lobby, func(snapshot Snapshot) bool {
+		return len(snapshot.Rooms) == 1
+	})
+	if len(snapshot.Users) != 2 || snapshot.Rooms[0].Host != "fred" {
+		t.Errorf("expected fred and the room of fred in the lobby but got: %+v", snapshot)
+	}
+
+	s.CloseRoom("pong", "WXYZ")
+	nextSnapshot(t, lobby, func(snapshot Snapshot) bool {
+		return len(snapshot.Rooms) == 0
+	})
+}
+
+// nextEvent waits for an event of the type, skipping lobby updates
+func nextEvent(t *testing.T, client *ws.Client, eventType ws.EventType) *ws.Event {
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-client.Event:
+			if event.Type == eventType {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("expected %s to get event %d but got nothing", client.Username, eventType)
+			return nil
+		}
+	}
+}
+
+func TestInvite(t *testing.T) {
+	s := NewService()
+	inviter := newTestClient(s, "fred")
+	s.SetActivity("fred", Activity{Game: "tictactoe", Code: "ABCD"})
+
+	if _, err := s.Invite("fred", "bento", "tictactoe"); err != ErrRoomNotOpen {
+		t.Errorf("expected %v but got: %v", ErrRoomNotOpen, err)
+	}
+	s.OpenRoom(Room{Game: "tictactoe", Code: "ABCD", Host: "fred", Players: 1, MaxPlayers: 2})
+	if _, err := s.Invite("fred", "bento", "pong"); err != ErrNotInRoom {
+		t.Errorf("expected %v but got: %v", ErrNotInRoom, err)
+	}
+	if _, err := s.Invite("fred", "bento", "tictactoe"); err != ErrNotOnline {
+		t.Errorf("expected %v but got: %v", ErrNotOnline, err)
+	}
+
+	invitee := newTestClient(s, "bento")
+	if _, err := s.Invite("fred", "bento", "tictactoe"); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	invite := Invite{}
+	json.Unmarshal(nextEvent(t, invitee, EventTypeInvite).Data, &invite)
+	if invite.From != "fred" || invite.Code != "ABCD" {
+		t.Fatalf("expected an invite to room ABCD but got: %+v", invite)
+	}
+
+	data, _ := json.Marshal(InviteAnswer{Invite: invite, Accepted: false})
+	s.ReadMessageHandler(invitee, ws.Event{Type: EventTypeInviteAnswer, Data: data})
+	answer := InviteAnswer{}
+	json.Unmarshal(nextEvent(t, inviter, EventTypeInviteAnswer).Data, &answer)
+	if answer.To != "bento" || answer.Accepted {
+		t.Errorf("expected bento to decline but got: %+v", answer)
+	}
+	nextEvent(t, invitee, EventTypeInviteAnswered)
+}
+
+func TestInviteDroppedWithRoom(t *testing.T) {
+	s := NewService()
+	newTestClient(s, "fred")
+	newTestClient(s, "bento")
+	s.SetActivity("fred", Activity{Game: "pong", Code: "WXYZ"})
+	s.OpenRoom(Room{Game: "pong", Code: "WXYZ", Host: "fred", Players: 1, MaxPlayers: 2})
+
+	if _, err := s.Invite("fred", "bento", "pong"); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	s.CloseRoom("pong", "WXYZ")
+
+	var pending int
+	s.Hub.Do(func() {
+		pending = len(s.invites)
+	})
+	if pending != 0 {
+		t.Errorf("expected the invite to be dropped but got: %d pending", pending)
+	}
+}
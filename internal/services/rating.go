@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"math"
+	"sync"
+
+	"github.com/FredericoBento/HandGame/internal/database/repository"
+	"github.com/FredericoBento/HandGame/internal/logger"
+	"github.com/FredericoBento/HandGame/internal/models"
+)
+
+const (
+	// New players move faster until their rating settles
+	eloProvisionalGames = 30
+	eloProvisionalK     = 40
+	eloK                = 20
+)
+
+var (
+	ErrCouldNotCreateRatingLogger = errors.New("could not create rating_service logger")
+	ErrRatingNeedsTwoPlayers      = errors.New("ratings can only be updated for two player matches")
+	ErrCouldNotGetRating          = errors.New("could not retrieve rating from repository")
+	ErrCouldNotSaveRating         = errors.New("could not save ratings")
+)
+
+// RatingChange is sent to the players along with the result of a match
+type RatingChange struct {
+	Rating int `json:"rating"`
+	Delta  int `json:"delta"`
+}
+
+type RatingService struct {
+	repo repository.RatingRepository
+	log  *slog.Logger
+	// mu serializes updates, a player finishing two games at once would
+	// otherwise lose one of them
+	mu sync.Mutex
+}
+
+func NewRatingService(repo repository.RatingRepository) *RatingService {
+	lo, err := logger.NewServiceLogger("RatingService", "", false)
+	if err != nil {
+		slog.Error(ErrCouldNotCreateRatingLogger.Error() + " " + err.Error())
+		lo = slog.Default()
+	}
+
+	return &RatingService{
+		repo: repo,
+		log:  lo,
+	}
+}
+
+// GetRating returns the rating of the user in a game, users that never played
+// it start at models.DefaultRating
+func (rs *RatingService) GetRating(ctx context.Context, username string, game string) (*models.Rating, error) {
+	rating, err := rs.repo.Get(ctx, username, game)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.NewRating(username, game), nil
+		}
+		rs.log.Error(err.Error())
+		return nil, ErrCouldNotGetRating
+	}
+	return rating, nil
+}
+
+func (rs *RatingService) GetRatings(ctx context.Context, username string) ([]models.Rating, error) {
+	ratings, err := rs.repo.GetByUsername(ctx, username)
+	if err != nil {
+		rs.log.Error(err.Error())
+		return nil, ErrCouldNotGetRating
+	}
+	return ratings, nil
+}
+
+// UpdateRatings applies the result of a finished match to the ratings of both
+// participants, the changes are returned by username
+func (rs *RatingService) UpdateRatings(ctx context.Context, game string, participants []models.MatchParticipant) (map[string]RatingChange, error) {
+	if len(participants) != 2 {
+		return nil, ErrRatingNeedsTwoPlayers
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	a, err := rs.GetRating(ctx, participants[0].Username, game)
+	if err != nil {
+		return nil, err
+	}
+	b, err := rs.GetRating(ctx, participants[1].Username, game)
+	if err != nil {
+		return nil, err
+	}
+
+	scoreA := matchScore(participants[0].Result)
+	expectedA := ExpectedScore(a.Rating, b.Rating)
+	newA := a.Rating + eloKFactor(a.Games)*(scoreA-expectedA)
+	newB := b.Rating + eloKFactor(b.Games)*((1-scoreA)-(1-expectedA))
+
+	changes := map[string]RatingChange{
+		a.Username: newRatingChange(a.Rating, newA),
+		b.Username: newRatingChange(b.Rating, newB),
+	}
+
+	a.Rating, b.Rating = newA, newB
+	a.Games++
+	b.Games++
+	if err := rs.repo.Save(ctx, a, b); err != nil {
+		rs.log.Error(err.Error())
+		return nil, ErrCouldNotSaveRating
+	}
+	return changes, nil
+}
+
+// ExpectedScore is the Elo probability of a player rated a beating one rated b
+func ExpectedScore(a float64, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+func eloKFactor(games int) float64 {
+	if games < eloProvisionalGames {
+		return eloProvisionalK
+	}
+	return eloK
+}
+
+func matchScore(result models.MatchResult) float64 {
+	switch result {
+	case models.MatchResultWin:
+		return 1
+	case models.MatchResultTie:
+		return 0.5
+	default:
+		return 0
+	}
+}
+
+func newRatingChange(before float64, after float64) RatingChange {
+	return RatingChange{
+		Rating: int(math.Round(after)),
+		Delta:  int(math.Round(after) - math.Round(before)),
+	}
+}
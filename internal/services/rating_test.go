@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/FredericoBento/HandGame/internal/mock"
+	"github.com/FredericoBento/HandGame/internal/models"
+)
+
+func TestExpectedScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     float64
+		expected float64
+	}{
+		{"EqualRatings", 1200, 1200, 0.5},
+		{"400PointsAbove", 1600, 1200, 10.0 / 11.0},
+		{"400PointsBelow", 1200, 1600, 1.0 / 11.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExpectedScore(tt.a, tt.b)
+			if math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestUpdateRatings(t *testing.T) {
+	participants := func(result1, result2 models.MatchResult) []models.MatchParticipant {
+		return []models.MatchParticipant{
+			{Username: "p1", Result: result1},
+			{Username: "p2", Result: result2},
+		}
+	}
+
+	t.Run("WinBetweenNewPlayers", func(t *testing.T) {
+		repo := mock.NewMockRatingRepository()
+		rs := NewRatingService(repo)
+
+		changes, err := rs.UpdateRatings(context.TODO(), models.GamePong, participants(models.MatchResultWin, models.MatchResultLoss))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if changes["p1"] != (RatingChange{Rating: 1220, Delta: 20}) {
+			t.Errorf("unexpected change for the winner %+v", changes["p1"])
+		}
+		if changes["p2"] != (RatingChange{Rating: 1180, Delta: -20}) {
+			t.Errorf("unexpected change for the loser %+v", changes["p2"])
+		}
+
+		rating, err := rs.GetRating(context.TODO(), "p1", models.GamePong)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if rating.Rating != 1220 || rating.Games != 1 {
+			t.Errorf("expected the new rating to be saved, got %+v", rating)
+		}
+	})
+
+	t.Run("TieMovesTowardsEachOther", func(t *testing.T) {
+		repo := mock.NewMockRatingRepository()
+		repo.Ratings[models.GameTicTacToe+"/p1"] = models.Rating{Username: "p1", Game: models.GameTicTacToe, Rating: 1400, Games: 50}
+		rs := NewRatingService(repo)
+
+		changes, err := rs.UpdateRatings(context.TODO(), models.GameTicTacToe, participants(models.MatchResultTie, models.MatchResultTie))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if changes["p1"].Delta >= 0 || changes["p2"].Delta <= 0 {
+			t.Errorf("expected the stronger player to lose points on a tie, got %+v", changes)
+		}
+	})
+
+	t.Run("NeedsTwoPlayers", func(t *testing.T) {
+		rs := NewRatingService(mock.NewMockRatingRepository())
+		_, err := rs.UpdateRatings(context.TODO(), models.GamePong, participants(models.MatchResultWin, models.MatchResultLoss)[:1])
+		if !errors.Is(err, ErrRatingNeedsTwoPlayers) {
+			t.Errorf("expected %v, got %v", ErrRatingNeedsTwoPlayers, err)
+		}
+	})
+
+	t.Run("SaveFails", func(t *testing.T) {
+		repo := mock.NewMockRatingRepository()
+		repo.SaveError = errors.New("disk full")
+		rs := NewRatingService(repo)
+		_, err := rs.UpdateRatings(context.TODO(), models.GamePong, participants(models.MatchResultWin, models.MatchResultLoss))
+		if !errors.Is(err, ErrCouldNotSaveRating) {
+			t.Errorf("expected %v, got %v", ErrCouldNotSaveRating, err)
+		}
+	})
+}
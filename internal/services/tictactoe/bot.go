@@ -0,0 +1,233 @@
+package tictactoe
+
+import (
+	"math"
+	"math/rand/v2"
+
+	"github.com/FredericoBento/HandGame/internal/ws"
+)
+
+// Difficulty is how well a bot plays, it is picked when creating a game
+type Difficulty string
+
+const (
+	DifficultyRandom    Difficulty = "random"
+	DifficultyHeuristic Difficulty = "heuristic"
+	DifficultyMinimax   Difficulty = "minimax"
+)
+
+var (
+	ErrUnknownDifficulty = ws.NewError(ws.ErrorCodeInvalidOptions, "Unknown bot difficulty").WithField("bot")
+)
+
+// Bot picks the moves of a computer player, it plays through
+// GameState.MakePlay like anyone else
+type Bot struct {
+	Difficulty Difficulty
+	Username   string
+}
+
+func NewBot(difficulty Difficulty) (*Bot, error) {
+	switch difficulty {
+	case DifficultyRandom, DifficultyHeuristic, DifficultyMinimax:
+	default:
+		return nil, ErrUnknownDifficulty
+	}
+	return &Bot{
+		Difficulty: difficulty,
+		Username:   "Computer (" + string(difficulty) + ")",
+	}, nil
+}
+
+const (
+	// minimax searches the whole game once this few cells are empty, before
+	// that it looks minimax_depth moves ahead and scores the board
+	minimax_full_search = 9
+	minimax_depth       = 2
+	// minimax_win is the score of a won game, it is above any board score
+	minimax_win = 1 << 50
+)
+
+// Move returns the cell the bot plays as player me on a board where
+// winLength marks in a line win, ok is false if the board is full
+func (b *Bot) Move(board [][]int, winLength int, me int) (row int, col int, ok bool) {
+	empty := emptyCells(board)
+	if len(empty) == 0 {
+		return -1, -1, false
+	}
+	var cell Cell
+	switch b.Difficulty {
+	case DifficultyHeuristic:
+		cell = heuristicMove(board, winLength, me, empty)
+	case DifficultyMinimax:
+		cell = minimaxMove(board, winLength, me, empty)
+	default:
+		cell = empty[rand.IntN(len(empty))]
+	}
+	return cell.Row, cell.Col, true
+}
+
+func emptyCells(board [][]int) []Cell {
+	cells := make([]Cell, 0, len(board)*len(board))
+	for row := range board {
+		for col := range board[row] {
+			if board[row][col] == 0 {
+				cells = append(cells, Cell{Row: row, Col: col})
+			}
+		}
+	}
+	return cells
+}
+
+// nearbyCells are the empty cells next to a mark, on big boards the moves
+// far from the others are not worth looking at. The center is given on an
+// empty board
+func nearbyCells(board [][]int, empty []Cell) []Cell {
+	size := len(board)
+	cells := make([]Cell, 0, len(empty))
+	for _, cell := range empty {
+		near := false
+		for r := max(cell.Row-1, 0); r <= min(cell.Row+1, size-1) && !near; r++ {
+			for c := max(cell.Col-1, 0); c <= min(cell.Col+1, size-1) && !near; c++ {
+				near = board[r][c] != 0
+			}
+		}
+		if near {
+			cells = append(cells, cell)
+		}
+	}
+	if len(cells) == 0 {
+		return []Cell{{Row: size / 2, Col: size / 2}}
+	}
+	return cells
+}
+
+func opponent(player int) int {
+	return 3 - player
+}
+
+// winner returns the player with winLength marks in a line, or 0
+func winner(board [][]int, winLength int) int {
+	_, player := winningLine(board, winLength)
+	return player
+}
+
+// finishingMove returns the cell that wins the game for me, or else the one
+// the opponent would win with
+func finishingMove(board [][]int, winLength int, me int, empty []Cell) (Cell, bool) {
+	for _, player := range []int{me, opponent(me)} {
+		for _, cell := range empty {
+			board[cell.Row][cell.Col] = player
+			won := winner(board, winLength) == player
+			board[cell.Row][cell.Col] = 0
+			if won {
+				return cell, true
+			}
+		}
+	}
+	return Cell{}, false
+}
+
+// heuristicMove wins if it can, blocks the opponent if it must, and prefers
+// the center then the cells next to other marks otherwise
+func heuristicMove(board [][]int, winLength int, me int, empty []Cell) Cell {
+	if cell, ok := finishingMove(board, winLength, me, empty); ok {
+		return cell
+	}
+	center := len(board) / 2
+	if board[center][center] == 0 {
+		return Cell{Row: center, Col: center}
+	}
+	near := nearbyCells(board, empty)
+	return near[rand.IntN(len(near))]
+}
+
+// minimaxMove searches the whole game on small boards, where it never
+// loses. Bigger boards are searched a few moves ahead
+func minimaxMove(board [][]int, winLength int, me int, empty []Cell) Cell {
+	if cell, ok := finishingMove(board, winLength, me, empty); ok {
+		return cell
+	}
+	depth := minimax_depth
+	if len(empty) <= minimax_full_search {
+		depth = len(empty)
+	}
+	best, bestScore := empty[0], math.MinInt
+	for _, cell := range candidateCells(board, empty) {
+		board[cell.Row][cell.Col] = me
+		score := -minimax(board, winLength, opponent(me), 1, depth-1, -minimax_win*2, minimax_win*2)
+		board[cell.Row][cell.Col] = 0
+		if score > bestScore {
+			best, bestScore = cell, score
+		}
+	}
+	return best
+}
+
+// candidateCells are the moves searched, all of them on small boards
+func candidateCells(board [][]int, empty []Cell) []Cell {
+	if len(empty) <= minimax_full_search {
+		return empty
+	}
+	return nearbyCells(board, empty)
+}
+
+// minimax scores the board for player, whose turn it is, quicker wins and
+// slower losses score higher. Once depth moves are searched the board is
+// scored by evaluate, alpha and beta cut the moves that cannot matter
+func minimax(board [][]int, winLength int, player int, ply int, depth int, alpha int, beta int) int {
+	switch winner(board, winLength) {
+	case player:
+		return minimax_win - ply
+	case opponent(player):
+		return ply - minimax_win
+	}
+	empty := emptyCells(board)
+	if len(empty) == 0 {
+		return 0
+	}
+	if depth == 0 {
+		return evaluate(board, winLength, player)
+	}
+	best := math.MinInt
+	for _, cell := range candidateCells(board, empty) {
+		board[cell.Row][cell.Col] = player
+		best = max(best, -minimax(board, winLength, opponent(player), ply+1, depth-1, -beta, -alpha))
+		board[cell.Row][cell.Col] = 0
+		alpha = max(alpha, best)
+		if alpha >= beta {
+			break
+		}
+	}
+	return best
+}
+
+// evaluate scores a board that is not finished for player, every line of
+// winLength cells that only one player has marks in counts for that player,
+// more so the more marks it has
+func evaluate(board [][]int, winLength int, player int) int {
+	size := len(board)
+	directions := [4][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}}
+	score := 0
+	for row := range board {
+		for col := range board[row] {
+			for _, d := range directions {
+				endRow, endCol := row+d[0]*(winLength-1), col+d[1]*(winLength-1)
+				if endRow < 0 || endRow >= size || endCol < 0 || endCol >= size {
+					continue
+				}
+				var marks [3]int
+				for i := range winLength {
+					marks[board[row+d[0]*i][col+d[1]*i]]++
+				}
+				switch {
+				case marks[player] > 0 && marks[opponent(player)] == 0:
+					score += 1 << (2 * marks[player])
+				case marks[opponent(player)] > 0 && marks[player] == 0:
+					score -= 1 << (2 * marks[opponent(player)])
+				}
+			}
+		}
+	}
+	return score
+}
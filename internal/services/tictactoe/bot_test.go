@@ -0,0 +1,76 @@
+package tictactoe
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+func TestBotCompletesAndBlocksLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		board    [][]int
+		expected Cell
+	}{
+		{"Wins", [][]int{{2, 2, 0}, {1, 1, 0}, {1, 0, 0}}, Cell{Row: 0, Col: 2}},
+		{"Blocks", [][]int{{1, 1, 0}, {0, 2, 0}, {0, 0, 0}}, Cell{Row: 0, Col: 2}},
+	}
+
+	for _, difficulty := range []Difficulty{DifficultyHeuristic, DifficultyMinimax} {
+		bot, _ := NewBot(difficulty)
+		for _, tt := range tests {
+			t.Run(string(difficulty)+tt.name, func(t *testing.T) {
+				row, col, ok := bot.Move(tt.board, 3, 2)
+				if !ok || (Cell{Row: row, Col: col}) != tt.expected {
+					t.Errorf("expected %v but got: %d %d", tt.expected, row, col)
+				}
+			})
+		}
+	}
+}
+
+func TestMinimaxNeverLoses(t *testing.T) {
+	bot, _ := NewBot(DifficultyMinimax)
+	for game := 0; game < 50; game++ {
+		board := NewBoard(3)
+		for turn := 0; winner(board, 3) == 0 && len(emptyCells(board)) > 0; turn++ {
+			if turn%2 == 0 {
+				empty := emptyCells(board)
+				cell := empty[rand.IntN(len(empty))]
+				board[cell.Row][cell.Col] = 1
+				continue
+			}
+			row, col, _ := bot.Move(board, 3, 2)
+			board[row][col] = 2
+		}
+		if winner(board, 3) == 1 {
+			t.Fatalf("expected minimax to never lose but got: %v", board)
+		}
+	}
+}
+
+func TestMinimaxOnABigBoard(t *testing.T) {
+	bot, _ := NewBot(DifficultyMinimax)
+	board := NewBoard(15)
+	// Three in a row open at both ends, it has to block one end
+	for col := 5; col < 8; col++ {
+		board[7][col] = 1
+	}
+	board[6][6] = 2
+	board[8][6] = 2
+
+	start := time.Now()
+	row, col, ok := bot.Move(board, 5, 2)
+	if !ok || row != 7 || (col != 4 && col != 8) {
+		t.Errorf("expected the bot to block the row but got: %d %d", row, col)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected a move in reasonable time but took: %v", elapsed)
+	}
+}
+
+func TestUnknownDifficulty(t *testing.T) {
+	if _, err := NewBot("impossible"); err != ErrUnknownDifficulty {
+		t.Errorf("expected %v but got: %v", ErrUnknownDifficulty, err)
+	}
+}
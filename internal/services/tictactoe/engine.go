@@ -0,0 +1,61 @@
+package tictactoe
+
+import (
+	"encoding/json"
+
+	"github.com/FredericoBento/HandGame/internal/services/gameroom"
+)
+
+// newEngine plays the game of one room on a gameroom.Duel, the board and its
+// moves are in state.go
+func (s *TicTacToeService) newEngine(room *gameroom.Room, options json.RawMessage) (gameroom.Engine, error) {
+	opts := EventDataCreateGame{}
+	if len(options) > 0 {
+		if err := json.Unmarshal(options, &opts); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Size == 0 {
+		opts.Size = default_board_size
+	}
+	if opts.WinLength == 0 {
+		opts.WinLength = default_win_length
+	}
+	if err := ValidateDimensions(opts.Size, opts.WinLength); err != nil {
+		return nil, err
+	}
+	state := NewGameState(room.Code, opts.Size, opts.WinLength)
+	duel := &gameroom.Duel{
+		State:    &state.DuelState,
+		Rules:    state,
+		Events:   duelEvents,
+		Matches:  s.recorder,
+		BotDelay: s.botDelay,
+	}
+	if opts.Bot != "" {
+		bot, err := NewBot(opts.Bot)
+		if err != nil {
+			return nil, err
+		}
+		duel.Bot = &botPlayer{Bot: bot, state: state}
+	}
+	return duel, nil
+}
+
+// botPlayer is the bot of a game against the computer, see gameroom.DuelBot
+type botPlayer struct {
+	*Bot
+	state *GameState
+}
+
+func (b *botPlayer) Name() string {
+	return b.Username
+}
+
+func (b *botPlayer) Play() (gameroom.DuelMove, error) {
+	row, col, ok := b.Move(b.state.Board, b.state.WinLength, 2)
+	if !ok {
+		return gameroom.DuelMove{}, ErrCouldNotPlay
+	}
+	return b.state.play(2, row, col)
+}
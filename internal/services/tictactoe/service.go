@@ -1,25 +1,40 @@
 package tictactoe
 
 import (
-	"errors"
 	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/FredericoBento/HandGame/internal/database/repository"
 	"github.com/FredericoBento/HandGame/internal/logger"
 	"github.com/FredericoBento/HandGame/internal/middleware"
+	"github.com/FredericoBento/HandGame/internal/models"
 	"github.com/FredericoBento/HandGame/internal/services"
+	"github.com/FredericoBento/HandGame/internal/services/gameroom"
+	"github.com/FredericoBento/HandGame/internal/services/presence"
 	"github.com/FredericoBento/HandGame/internal/ws"
 	"github.com/gorilla/websocket"
 )
 
 type TicTacToeService struct {
-	Name       string
-	Status     *services.Status
-	Log        *slog.Logger
-	Hub        *ws.Hub
-	GameStates map[string]*GameState
+	Name    string
+	Status  *services.Status
+	Log     *slog.Logger
+	rooms   *gameroom.Manager
+	matches repository.MatchRepository
+	ratings *services.RatingService
+	// recorder records the finished games with matches and ratings
+	recorder *gameroom.MatchRecorder
+	// presence is told who plays where, it is optional
+	presence *presence.Service
+
+	reconnectGrace time.Duration
+	// botDelay is how long the bot waits before it moves
+	botDelay time.Duration
 }
 
+type TicTacToeServiceOption func(*TicTacToeService)
+
 var (
 	upgrader = websocket.Upgrader{
 		CheckOrigin:     func(r *http.Request) bool { return true },
@@ -27,40 +42,116 @@ var (
 		WriteBufferSize: 512,
 	}
 
-	ErrInvalidCode = errors.New("Invalid Code, Room does not exists")
+	events = gameroom.Events{
+		Create:           EventTypeCreateGame,
+		Join:             EventTypeJoinGame,
+		Spectate:         EventTypeSpectateGame,
+		SpectatorsUpdate: EventTypeSpectatorsUpdate,
+		FindMatch:        EventTypeFindMatch,
+		CancelFindMatch:  EventTypeCancelFindMatch,
+		Countdown:        EventTypeReconnectCountdown,
+		Chat:             EventTypePlayerSendMessage,
+		ChatHistory:      EventTypeChatHistory,
+	}
+
+	duelEvents = gameroom.DuelEvents{
+		Joined:             EventTypeJoinedGame,
+		OtherPlayerJoined:  EventTypeOtherPlayerJoined,
+		StateUpdate:        EventTypeStateUpdate,
+		PlayerDisconnected: EventTypePlayerDisconnected,
+		PlayerReconnected:  EventTypePlayerReconnected,
+		Play:               EventTypeMakePlay,
+		Moved:              EventTypeBoardCellUpdate,
+		Tie:                EventTypeTie,
+		Victory:            EventTypeVictory,
+		Defeat:             EventTypeDefeat,
+		Forfeit:            EventTypeForfeit,
+	}
 )
 
-func NewTicTacToeService() *TicTacToeService {
+const (
+	default_reconnect_grace = 30 * time.Second
+	default_bot_delay       = 600 * time.Millisecond
+)
+
+// WithMatchRepository makes the service record every finished game
+func WithMatchRepository(repo repository.MatchRepository) TicTacToeServiceOption {
+	return func(s *TicTacToeService) {
+		s.matches = repo
+	}
+}
+
+// WithRatingService makes the service update the ratings of the players of
+// every finished game
+func WithRatingService(ratings *services.RatingService) TicTacToeServiceOption {
+	return func(s *TicTacToeService) {
+		s.ratings = ratings
+	}
+}
+
+// WithPresence makes the service tell the lobby who plays where and which
+// rooms have a free seat
+func WithPresence(p *presence.Service) TicTacToeServiceOption {
+	return func(s *TicTacToeService) {
+		s.presence = p
+	}
+}
+
+// WithReconnectGrace sets how long a player that dropped has to come back
+// before the game is given to its opponent
+func WithReconnectGrace(grace time.Duration) TicTacToeServiceOption {
+	return func(s *TicTacToeService) {
+		s.reconnectGrace = grace
+	}
+}
+
+// WithBotDelay sets how long the bot waits before it moves in games against
+// the computer
+func WithBotDelay(delay time.Duration) TicTacToeServiceOption {
+	return func(s *TicTacToeService) {
+		s.botDelay = delay
+	}
+}
+
+func NewTicTacToeService(opts ...TicTacToeServiceOption) *TicTacToeService {
 	lo, err := logger.NewServiceLogger("TicTacToeService", "", true)
 	if err != nil {
 		lo = slog.Default()
 	}
 	service := &TicTacToeService{
-		Name:       "TicTacToeService",
-		Status:     services.NewStatus(),
-		Log:        lo,
-		Hub:        ws.NewHub(),
-		GameStates: make(map[string]*GameState),
+		Name:   "TicTacToeService",
+		Status: services.NewStatus(),
+		Log:    lo,
+
+		reconnectGrace: default_reconnect_grace,
+		botDelay:       default_bot_delay,
 	}
-	go service.Run(service.Hub)
+	for _, option := range opts {
+		option(service)
+	}
+	service.recorder = &gameroom.MatchRecorder{
+		Game:    models.GameTicTacToe,
+		Matches: service.matches,
+		Ratings: service.ratings,
+		Log:     lo,
+	}
+	service.rooms = gameroom.NewManager(gameroom.Config{
+		Game:           models.GameTicTacToe,
+		MaxPlayers:     2,
+		ReconnectGrace: service.reconnectGrace,
+		Events:         events,
+		NewEngine:      service.newEngine,
+		Ratings:        service.ratings,
+		Presence:       service.presence,
+	}, lo)
+	service.rooms.Run()
 	return service
 }
 
+// ReadMessageHandler hands the event over to the rooms, the rules of the
+// game are in state.go
 func (s *TicTacToeService) ReadMessageHandler(client *ws.Client, event ws.Event) {
-	switch event.Type {
-	case EventTypeCreateGame:
-		s.HandleEventCreateGame(&event, client)
-		break
-	case EventTypeJoinGame:
-		s.HandleEventJoinGame(&event, client)
-		break
-	case EventTypeMakePlay:
-		s.HandleEventMakePlay(&event, client)
-		break
-	default:
-		slog.Error("Unknown event received")
-		return
-	}
+	s.rooms.ReadMessageHandler(client, event)
 }
 
 func (s *TicTacToeService) HandleWebSocketConnection() http.HandlerFunc {
@@ -71,16 +162,16 @@ func (s *TicTacToeService) HandleWebSocketConnection() http.HandlerFunc {
 			return
 		}
 
-		conn, err := upgrader.Upgrade(w, r, nil)
+		conn, err := ws.Accept(&upgrader, w, r)
 		if err != nil {
-			s.Log.Error("Error upgrading to WebSocket:", err)
+			s.Log.Error("Error upgrading to WebSocket: " + err.Error())
 			return
 		}
 
 		client := ws.NewClient(conn, user.Username)
-		s.Hub.Register <- client
+		s.rooms.Register(client)
 
-		go client.ReadPump(s.Hub, s.ReadMessageHandler)
+		go client.ReadPump(s.rooms.Hub, s.ReadMessageHandler)
 		go client.WritePump()
 	}
 }
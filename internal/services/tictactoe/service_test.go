@@ -0,0 +1,398 @@
+package tictactoe
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FredericoBento/HandGame/internal/mock"
+	"github.com/FredericoBento/HandGame/internal/roomtest"
+	"github.com/FredericoBento/HandGame/internal/services"
+	"github.com/FredericoBento/HandGame/internal/services/chat"
+	"github.com/FredericoBento/HandGame/internal/services/gameroom"
+	"github.com/FredericoBento/HandGame/internal/ws"
+)
+
+type Play struct {
+	Row int `json:"row"`
+	Col int `json:"col"`
+}
+
+func startGame(t *testing.T, s *TicTacToeService) (string, *ws.Client, *ws.Client) {
+	p1 := roomtest.NewClient(s.rooms, "p1")
+	p2 := roomtest.NewClient(s.rooms, "p2")
+	roomtest.Send(t, s.rooms, p1, EventTypeCreateGame, nil)
+	code := roomtest.WaitForEvent(t, p1, EventTypeJoinedGame).RoomCode
+	roomtest.Send(t, s.rooms, p2, EventTypeJoinGame, gameroom.EventDataCode{Code: code})
+	roomtest.WaitForEvent(t, p2, EventTypeJoinedGame)
+	return code, p1, p2
+}
+
+// TestConcurrentGame is meant to be run with -race, players and spectators
+// all talk to the service at once
+func TestConcurrentGame(t *testing.T) {
+	s := NewTicTacToeService()
+	code, p1, p2 := startGame(t, s)
+
+	var wg sync.WaitGroup
+	for _, player := range []*ws.Client{p1, p2} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 30; i++ {
+				type Play struct {
+					Row int `json:"row"`
+					Col int `json:"col"`
+				}
+				roomtest.Send(t, s.rooms, player, EventTypeMakePlay, Play{Row: i % 3, Col: (i / 3) % 3})
+			}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			spectator := roomtest.NewClient(s.rooms, fmt.Sprintf("spectator%d", i))
+			roomtest.Send(t, s.rooms, spectator, EventTypeSpectateGame, gameroom.EventDataCode{Code: code})
+			s.rooms.Hub.Unregister <- spectator
+		}()
+	}
+	wg.Wait()
+
+	var players, spectators int
+	found := false
+	s.rooms.Do(func() {
+		room, ok := s.rooms.Room(code)
+		if !ok {
+			return
+		}
+		found = room.Do(func() {
+			players = len(room.Clients)
+			spectators = room.SpectatorCount()
+		})
+	})
+	if !found {
+		t.Fatalf("expected room %s to be open", code)
+	}
+	if players != 2 {
+		t.Errorf("expected 2 players but got: %d", players)
+	}
+	if spectators != 0 {
+		t.Errorf("expected no spectators left but got: %d", spectators)
+	}
+}
+
+func TestDisconnectClosesEmptyRoom(t *testing.T) {
+	s := NewTicTacToeService(WithReconnectGrace(0))
+	code, p1, p2 := startGame(t, s)
+
+	s.rooms.Hub.Unregister <- p1
+	roomtest.WaitForEvent(t, p2, EventTypePlayerDisconnected)
+	s.rooms.Hub.Unregister <- p2
+
+	var roomExists bool
+	s.rooms.Do(func() {
+		_, roomExists = s.rooms.Room(code)
+	})
+	if roomExists {
+		t.Errorf("expected room %s to be closed", code)
+	}
+}
+
+func TestReconnectWithinGrace(t *testing.T) {
+	s := NewTicTacToeService(WithReconnectGrace(time.Minute))
+	code, p1, p2 := startGame(t, s)
+	roomtest.Send(t, s.rooms, p1, EventTypeMakePlay, Play{Row: 1, Col: 1})
+	roomtest.WaitForEvent(t, p2, EventTypeBoardCellUpdate)
+
+	s.rooms.Hub.Unregister <- p2
+	event := roomtest.WaitForEvent(t, p1, EventTypeReconnectCountdown)
+	countdown := gameroom.EventDataCountdown{}
+	if err := json.Unmarshal(event.Data, &countdown); err != nil {
+		t.Fatal(err)
+	}
+	if countdown.Username != "p2" || countdown.Seconds != 60 {
+		t.Errorf("expected p2 to have 60 seconds to come back but got: %+v", countdown)
+	}
+
+	p2 = roomtest.NewClient(s.rooms, "p2")
+	roomtest.Send(t, s.rooms, p2, EventTypeJoinGame, gameroom.EventDataCode{Code: code})
+	event = roomtest.WaitForEvent(t, p2, EventTypeStateUpdate)
+	state := GameState{}
+	if err := json.Unmarshal(event.Data, &state); err != nil {
+		t.Fatal(err)
+	}
+	if state.Board[1][1] != 1 || state.Turn != 1 {
+		t.Errorf("expected the game to be kept but got: %+v", state)
+	}
+	roomtest.WaitForEvent(t, p1, EventTypePlayerReconnected)
+}
+
+func TestForfeitAfterGrace(t *testing.T) {
+	s := NewTicTacToeService(WithReconnectGrace(50 * time.Millisecond))
+	_, p1, p2 := startGame(t, s)
+
+	s.rooms.Hub.Unregister <- p2
+	event := roomtest.WaitForEvent(t, p1, EventTypeForfeit)
+	type Data struct {
+		Winner    int    `json:"winner"`
+		Forfeited string `json:"forfeited"`
+	}
+	data := Data{}
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Winner != 1 || data.Forfeited != "p2" {
+		t.Errorf("expected p1 to win by forfeit but got: %+v", data)
+	}
+}
+
+func TestReconnectResumesMissedPlays(t *testing.T) {
+	s := NewTicTacToeService(WithReconnectGrace(time.Minute))
+	code, p1, p2 := startGame(t, s)
+	roomtest.Send(t, s.rooms, p1, EventTypeMakePlay, Play{Row: 0, Col: 0})
+	roomtest.WaitForEvent(t, p2, EventTypeBoardCellUpdate)
+	roomtest.Send(t, s.rooms, p2, EventTypeMakePlay, Play{Row: 1, Col: 1})
+	lastSeq := roomtest.WaitForEvent(t, p2, EventTypeBoardCellUpdate).Seq
+
+	s.rooms.Hub.Unregister <- p2
+	roomtest.WaitForEvent(t, p1, EventTypePlayerDisconnected)
+	roomtest.Send(t, s.rooms, p1, EventTypeMakePlay, Play{Row: 2, Col: 2})
+
+	p2 = roomtest.NewClient(s.rooms, "p2")
+	roomtest.Send(t, s.rooms, p2, EventTypeJoinGame, gameroom.EventDataJoin{Code: code, LastSeq: lastSeq})
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-p2.Event:
+			switch event.Type {
+			case EventTypeStateUpdate:
+				t.Fatalf("expected p2 to resume without the whole state but got: %s", event.Data)
+			case EventTypeBoardCellUpdate:
+				type Cell struct {
+					Row int `json:"row"`
+					Col int `json:"col"`
+				}
+				cell := Cell{}
+				if err := json.Unmarshal(event.Data, &cell); err != nil {
+					t.Fatal(err)
+				}
+				if event.Seq <= lastSeq || cell.Row != 2 || cell.Col != 2 {
+					t.Errorf("expected the play p2 missed but got: %+v, %d", cell, event.Seq)
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatalf("expected p2 to get the play it missed")
+		}
+	}
+}
+
+func TestChatMessageHasSender(t *testing.T) {
+	s := NewTicTacToeService()
+	_, p1, p2 := startGame(t, s)
+
+	roomtest.Send(t, s.rooms, p2, EventTypePlayerSendMessage, gameroom.EventDataChat{Text: "gg"})
+	event := roomtest.WaitForEvent(t, p1, EventTypePlayerSendMessage)
+	message := chat.Message{}
+	if err := json.Unmarshal(event.Data, &message); err != nil {
+		t.Fatal(err)
+	}
+	if message.From != "p2" || message.Text != "gg" {
+		t.Errorf("expected the message of p2 but got: %+v", message)
+	}
+}
+
+func TestBotOpponent(t *testing.T) {
+	s := NewTicTacToeService(WithBotDelay(0))
+	p1 := roomtest.NewClient(s.rooms, "p1")
+	roomtest.Send(t, s.rooms, p1, EventTypeCreateGame, EventDataCreateGame{Bot: DifficultyMinimax})
+	event := roomtest.WaitForEvent(t, p1, EventTypeJoinedGame)
+	state := GameState{}
+	if err := json.Unmarshal(event.Data, &state); err != nil {
+		t.Fatal(err)
+	}
+	if state.Player2 == nil || state.Player2.Username != "Computer (minimax)" {
+		t.Fatalf("expected the bot to be player2 but got: %+v", state.Player2)
+	}
+
+	roomtest.Send(t, s.rooms, p1, EventTypeMakePlay, Play{Row: 0, Col: 0})
+	type Cell struct {
+		Value int `json:"value"`
+	}
+	// Events of the room may come in any order
+	moved := map[int]bool{}
+	for range 2 {
+		cell := Cell{}
+		if err := json.Unmarshal(roomtest.WaitForEvent(t, p1, EventTypeBoardCellUpdate).Data, &cell); err != nil {
+			t.Fatal(err)
+		}
+		moved[cell.Value] = true
+	}
+	if !moved[1] || !moved[2] {
+		t.Errorf("expected the player and the bot to move but got: %v", moved)
+	}
+
+	p2 := roomtest.NewClient(s.rooms, "p2")
+	roomtest.Send(t, s.rooms, p2, EventTypeJoinGame, gameroom.EventDataCode{Code: event.RoomCode})
+	select {
+	case event := <-p2.Event:
+		if !event.IsError {
+			t.Errorf("expected the room to be full but got: %+v", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Errorf("expected p2 to be turned away")
+	}
+}
+
+func TestBotGamesAreRecordedUnrated(t *testing.T) {
+	matches := &mock.MockMatchRepository{}
+	ratings := mock.NewMockRatingRepository()
+	s := NewTicTacToeService(WithBotDelay(0), WithMatchRepository(matches),
+		WithRatingService(services.NewRatingService(ratings)))
+	p1 := roomtest.NewClient(s.rooms, "p1")
+	roomtest.Send(t, s.rooms, p1, EventTypeCreateGame, EventDataCreateGame{Bot: DifficultyMinimax})
+	roomtest.WaitForEvent(t, p1, EventTypeJoinedGame)
+
+	// p1 goes through the cells in order, the taken ones are turned away,
+	// until the game is over
+	type Cell struct {
+		Value int `json:"value"`
+	}
+	next := 0
+	roomtest.Send(t, s.rooms, p1, EventTypeMakePlay, Play{Row: 0, Col: 0})
+	timeout := time.After(2 * time.Second)
+	for finished := false; !finished; {
+		select {
+		case event := <-p1.Event:
+			cell := Cell{}
+			switch {
+			case event.Type == EventTypeVictory || event.Type == EventTypeDefeat || event.Type == EventTypeTie:
+				finished = true
+			case event.Type == EventTypeBoardCellUpdate && !event.IsError:
+				if err := json.Unmarshal(event.Data, &cell); err != nil {
+					t.Fatal(err)
+				}
+				if cell.Value != 2 {
+					continue
+				}
+				fallthrough
+			case event.IsError:
+				next++
+				roomtest.Send(t, s.rooms, p1, EventTypeMakePlay, Play{Row: next / 3, Col: next % 3})
+			}
+		case <-timeout:
+			t.Fatalf("expected the game against the bot to finish")
+		}
+	}
+
+	created := matches.GetCreated()
+	if len(created) != 1 || created[0].Participants[1].Username != "Computer (minimax)" {
+		t.Fatalf("expected the game against the bot to be recorded but got: %+v", created)
+	}
+	if len(ratings.Ratings) != 0 {
+		t.Errorf("expected no ratings to change but got: %+v", ratings.Ratings)
+	}
+}
+
+func TestBoardDimensions(t *testing.T) {
+	s := NewTicTacToeService()
+	p1 := roomtest.NewClient(s.rooms, "p1")
+	roomtest.Send(t, s.rooms, p1, EventTypeCreateGame, EventDataCreateGame{Size: 5, WinLength: 4})
+	event := roomtest.WaitForEvent(t, p1, EventTypeJoinedGame)
+	state := GameState{}
+	if err := json.Unmarshal(event.Data, &state); err != nil {
+		t.Fatal(err)
+	}
+	if state.Size != 5 || state.WinLength != 4 || len(state.Board) != 5 || len(state.Board[4]) != 5 {
+		t.Fatalf("expected a 5x5 board with 4 in a row but got: %+v", state)
+	}
+
+	p2 := roomtest.NewClient(s.rooms, "p2")
+	roomtest.Send(t, s.rooms, p2, EventTypeJoinGame, gameroom.EventDataCode{Code: event.RoomCode})
+	roomtest.WaitForEvent(t, p2, EventTypeJoinedGame)
+	roomtest.Send(t, s.rooms, p1, EventTypeMakePlay, Play{Row: 4, Col: 4})
+	type Cell struct {
+		Row       int `json:"row"`
+		Col       int `json:"col"`
+		Size      int `json:"size"`
+		WinLength int `json:"win_length"`
+	}
+	cell := Cell{}
+	if err := json.Unmarshal(roomtest.WaitForEvent(t, p2, EventTypeBoardCellUpdate).Data, &cell); err != nil {
+		t.Fatal(err)
+	}
+	if cell.Row != 4 || cell.Col != 4 || cell.Size != 5 || cell.WinLength != 4 {
+		t.Errorf("expected the play with the dimensions but got: %+v", cell)
+	}
+
+	p3 := roomtest.NewClient(s.rooms, "p3")
+	roomtest.Send(t, s.rooms, p3, EventTypeCreateGame, EventDataCreateGame{Size: 5, WinLength: 6})
+	select {
+	case event := <-p3.Event:
+		if !event.IsError {
+			t.Errorf("expected a win length over the size to be refused but got: %+v", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Errorf("expected p3 to be refused")
+	}
+}
+
+func TestPlayValidation(t *testing.T) {
+	s := NewTicTacToeService()
+	p1 := roomtest.NewClient(s.rooms, "p1")
+	roomtest.Send(t, s.rooms, p1, EventTypeCreateGame, nil)
+	code := roomtest.WaitForEvent(t, p1, EventTypeJoinedGame).RoomCode
+
+	roomtest.Send(t, s.rooms, p1, EventTypeMakePlay, Play{Row: 0, Col: 0})
+	if event := roomtest.WaitForError(t, p1); event.Error == nil || event.Error.Code != ws.ErrorCodeGameNotRunning {
+		t.Errorf("expected %s alone in the room but got: %+v", ws.ErrorCodeGameNotRunning, event.Error)
+	}
+
+	p2 := roomtest.NewClient(s.rooms, "p2")
+	roomtest.Send(t, s.rooms, p2, EventTypeJoinGame, gameroom.EventDataCode{Code: code})
+	roomtest.WaitForEvent(t, p2, EventTypeJoinedGame)
+	spectator := roomtest.NewClient(s.rooms, "spectator")
+	roomtest.Send(t, s.rooms, spectator, EventTypeSpectateGame, gameroom.EventDataCode{Code: code})
+	roomtest.WaitForEvent(t, spectator, EventTypeSpectateGame)
+
+	roomtest.Send(t, s.rooms, p1, EventTypeMakePlay, Play{Row: 1, Col: 1})
+	roomtest.WaitForEvent(t, p2, EventTypeBoardCellUpdate)
+
+	tests := []struct {
+		name     string
+		client   *ws.Client
+		data     any
+		expected ws.ErrorCode
+		field    string
+	}{
+		{"InvalidPlay", p2, "nowhere", ws.ErrorCodeInvalidPlay, ""},
+		{"NotAPlayer", spectator, Play{Row: 0, Col: 0}, ws.ErrorCodeNotAPlayer, ""},
+		{"NotYourTurn", p1, Play{Row: 0, Col: 0}, ws.ErrorCodeNotYourTurn, ""},
+		{"OutOfBoundsRow", p2, Play{Row: 3, Col: 0}, ws.ErrorCodeOutOfBounds, "row"},
+		{"OutOfBoundsCol", p2, Play{Row: 0, Col: -1}, ws.ErrorCodeOutOfBounds, "col"},
+		{"CellOccupied", p2, Play{Row: 1, Col: 1}, ws.ErrorCodeCellOccupied, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roomtest.Send(t, s.rooms, tt.client, EventTypeMakePlay, tt.data)
+			event := roomtest.WaitForError(t, tt.client)
+			if event.Error == nil || event.Error.Code != tt.expected || event.Error.Field != tt.field {
+				t.Errorf("expected %s on %q but got: %+v", tt.expected, tt.field, event.Error)
+			}
+		})
+	}
+
+	// None of it took the turn of p2
+	roomtest.Send(t, s.rooms, p2, EventTypeMakePlay, Play{Row: 0, Col: 0})
+	type Cell struct {
+		Value int `json:"value"`
+	}
+	for cell := (Cell{}); cell.Value != 2; {
+		if err := json.Unmarshal(roomtest.WaitForEvent(t, p1, EventTypeBoardCellUpdate).Data, &cell); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -1,181 +1,160 @@
 package tictactoe
 
 import (
-	"errors"
-	"log/slog"
-	"strconv"
+	"encoding/json"
+
+	"github.com/FredericoBento/HandGame/internal/services/gameroom"
+	"github.com/FredericoBento/HandGame/internal/ws"
 )
 
-type Player struct {
-	Username  string `json:"username"`
-	Connected bool   `json:"connected"`
-	Wins      int    `json:"wins"`
-}
+// Cell aliases gameroom.Cell
+type Cell = gameroom.Cell
 
+// GameState is the board of a game, the players and the score are kept by
+// the gameroom.Duel playing it
 type GameState struct {
-	Code    string     `json:"code"`
-	Board   [3][3]int  `json:"board"`
-	Player1 *Player    `json:"player1"`
-	Player2 *Player    `json:"player2"`
-	Turn    int        `json:"current_turn"` //odd number player2 even player1
-	Status  GameStatus `json:"status"`
-	Ties    int        `json:"ties"`
-	Winner  int        `json:"winner"`
+	gameroom.DuelState
+	// Size is the number of rows and columns of the board, WinLength how
+	// many marks in a line win
+	Size      int     `json:"size"`
+	WinLength int     `json:"win_length"`
+	Board     [][]int `json:"board"`
 }
 
-type GameStatus int
-
 const (
-	game_status_paused   = 0
-	game_status_running  = 1
-	game_status_finished = 2
+	default_board_size = 3
+	default_win_length = 3
+	max_board_size     = 15
 )
 
 var (
-	ErrInvalidClient   = errors.New("Invalid client")
-	ErrNoPlayerRemoved = errors.New("No player was removed")
-
-	ErrPlayerNotFound = errors.New("Player was not found")
-	ErrInvalidCell    = errors.New("Invalid Cell to make play")
+	ErrInvalidBoardSize = ws.NewError(ws.ErrorCodeInvalidOptions, "Board size must be between 3 and 15").WithField("size")
+	ErrInvalidWinLength = ws.NewError(ws.ErrorCodeInvalidOptions, "Win length must be between 3 and the board size").WithField("win_length")
 )
 
-func NewGameState(code string) *GameState {
+// NewGameState makes a game on a size by size board where winLength marks in
+// a line win, see ValidateDimensions
+func NewGameState(code string, size int, winLength int) *GameState {
 	return &GameState{
-		Code:    code,
-		Player1: nil,
-		Player2: nil,
-		Turn:    0,
-		Status:  game_status_paused,
-		Winner:  0,
+		DuelState: gameroom.NewDuelState(code),
+		Size:      size,
+		WinLength: winLength,
+		Board:     NewBoard(size),
 	}
 }
 
-func NewPlayer(username string) *Player {
-	return &Player{
-		Username:  username,
-		Connected: true,
+// ValidateDimensions checks the board size and win length asked for when
+// creating a game
+func ValidateDimensions(size int, winLength int) error {
+	if size < default_board_size || size > max_board_size {
+		return ErrInvalidBoardSize
 	}
-}
-
-func (state *GameState) AddPlayer(username string) error {
-	if state.Player1 == nil {
-		state.Player1 = NewPlayer(username)
-	} else {
-		state.Player2 = NewPlayer(username)
+	if winLength < default_win_length || winLength > size {
+		return ErrInvalidWinLength
 	}
-
 	return nil
 }
 
-func (state *GameState) RemovePlayer(username string) error {
-	if state.Player1 != nil {
-		if state.Player1.Username == username {
-			state.Player1 = nil
-			return nil
-		}
+func NewBoard(size int) [][]int {
+	board := make([][]int, size)
+	for row := range board {
+		board[row] = make([]int, size)
 	}
+	return board
+}
 
-	if state.Player2 != nil {
-		if state.Player2.Username == username {
-			state.Player2 = nil
-			return nil
-		}
+// Play marks the cell of the event for the player, see gameroom.DuelRules
+func (state *GameState) Play(player_num int, event *ws.Event) (gameroom.DuelMove, error) {
+	play := EventDataPlay{}
+	if err := json.Unmarshal(event.Data, &play); err != nil {
+		return gameroom.DuelMove{}, ErrCouldNotPlay
 	}
+	return state.play(player_num, play.Row, play.Col)
+}
 
-	return ErrNoPlayerRemoved
+func (state *GameState) play(player_num int, row int, col int) (gameroom.DuelMove, error) {
+	if err := state.MakePlay(player_num, row, col); err != nil {
+		return gameroom.DuelMove{}, err
+	}
+	line, _ := state.CheckWin()
+	return gameroom.DuelMove{
+		Row:  row,
+		Col:  col,
+		Line: line,
+		Full: state.Full(),
+		Data: EventDataCellUpdate{Row: row, Col: col, Value: player_num, Size: state.Size, WinLength: state.WinLength},
+	}, nil
 }
 
 func (state *GameState) MakePlay(player_num int, row int, col int) error {
-	var player *Player
-	if player_num == 1 {
-		player = state.Player1
-	} else {
-		player = state.Player2
+	if row < 0 || row >= state.Size {
+		return ErrOutOfBounds.WithField("row")
 	}
-
-	if player == nil {
-		return ErrPlayerNotFound
+	if col < 0 || col >= state.Size {
+		return ErrOutOfBounds.WithField("col")
 	}
-	if state.Board[row][col] == 0 {
-		state.Board[row][col] = player_num
-	} else {
-		slog.Info("Row: "+strconv.Itoa(row)+" Col: "+strconv.Itoa(col), state.Board[row][col])
-		return ErrInvalidCell
+	if state.Board[row][col] != 0 {
+		return ErrCellOcuppied
 	}
-
+	state.Board[row][col] = player_num
 	return nil
 }
 
-func (state *GameState) CheckWin() (int, int, int) {
-	// Check rows
-	for row := 0; row < 3; row++ {
-		if state.Board[row][0] != 0 && state.Board[row][0] == state.Board[row][1] && state.Board[row][1] == state.Board[row][2] {
-			state.Status = game_status_finished
-			state.Winner = state.Board[row][0]
-			return row, 0, state.Board[row][0] // winning row, column, and value
-		}
-	}
-
-	// Check columns
-	for col := 0; col < 3; col++ {
-		if state.Board[0][col] != 0 && state.Board[0][col] == state.Board[1][col] && state.Board[1][col] == state.Board[2][col] {
-			state.Status = game_status_finished
-			state.Winner = state.Board[0][col]
-			return 0, col, state.Board[0][col] // winning row, column, and value
-		}
-	}
-
-	// Check diagonal (top-left to bottom-right)
-	if state.Board[0][0] != 0 && state.Board[0][0] == state.Board[1][1] && state.Board[1][1] == state.Board[2][2] {
-		state.Status = game_status_finished
-		state.Winner = state.Board[0][0]
-		return 0, 0, state.Board[0][0] // winning row, column, and value
-	}
-
-	// Check diagonal (top-right to bottom-left)
-	if state.Board[0][2] != 0 && state.Board[0][2] == state.Board[1][1] && state.Board[1][1] == state.Board[2][0] {
-		state.Status = game_status_finished
-		state.Winner = state.Board[0][2]
-		return 0, 2, state.Board[0][2] // winning row, column, and value
-	}
+// CheckWin looks for WinLength marks in a line, it returns the cells of the
+// line and the player that made it, or no cells and 0
+func (state *GameState) CheckWin() ([]Cell, int) {
+	return winningLine(state.Board, state.WinLength)
+}
 
-	// No winner, check for tie
-	for row := 0; row < 3; row++ {
-		for col := 0; col < 3; col++ {
+// Full tells if every cell of the board is marked, the game is a tie then
+// unless it was won
+func (state *GameState) Full() bool {
+	for row := range state.Board {
+		for col := range state.Board[row] {
 			if state.Board[row][col] == 0 {
-				// If there is an empty cell, the game is still ongoing
-				return -1, -1, 0
+				return false
 			}
 		}
 	}
-
-	state.Status = game_status_finished
-	state.Winner = 0
-	state.Ties += 1
-	return -1, -1, 0
+	return true
 }
 
-func (state *GameState) ClearBoard() {
-	for row := range state.Board {
-		for col := range state.Board[row] {
-			state.Board[row][col] = 0
+// winningLine finds winLength equal marks in a row, column or diagonal of
+// the board, it returns the cells of the first line found and its player
+func winningLine(board [][]int, winLength int) ([]Cell, int) {
+	size := len(board)
+	// Each line is found from its first cell, walking right, down and along
+	// both diagonals
+	directions := [4][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}}
+	for row := range board {
+		for col := range board[row] {
+			player := board[row][col]
+			if player == 0 {
+				continue
+			}
+			for _, d := range directions {
+				endRow, endCol := row+d[0]*(winLength-1), col+d[1]*(winLength-1)
+				if endRow < 0 || endRow >= size || endCol < 0 || endCol >= size {
+					continue
+				}
+				line := make([]Cell, 0, winLength)
+				for i := range winLength {
+					r, c := row+d[0]*i, col+d[1]*i
+					if board[r][c] != player {
+						break
+					}
+					line = append(line, Cell{Row: r, Col: c})
+				}
+				if len(line) == winLength {
+					return line, player
+				}
+			}
 		}
 	}
+	return nil, 0
 }
 
-func (state *GameState) Restart(resetScoreBoard bool) {
-	state.ClearBoard()
-	state.Winner = 0
-	state.Turn = 0
-	if resetScoreBoard {
-		if state.Player1 != nil {
-			state.Player1.Wins = 0
-		}
-		if state.Player2 != nil {
-			state.Player2.Wins = 0
-		}
-
-		state.Ties = 0
-	}
-	state.Status = game_status_running
+// Clear empties the board for the next game, see gameroom.DuelRules
+func (state *GameState) Clear() {
+	state.Board = NewBoard(state.Size)
 }
@@ -0,0 +1,78 @@
+package tictactoe
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/FredericoBento/HandGame/internal/services/gameroom"
+)
+
+func TestCheckWinOnBiggerBoards(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int
+		winLength int
+		marks     []Cell
+		winner    int
+	}{
+		{"Row", 5, 4, []Cell{{Row: 2, Col: 0}, {Row: 2, Col: 1}, {Row: 2, Col: 2}, {Row: 2, Col: 3}}, 1},
+		{"ShortRow", 5, 4, []Cell{{Row: 2, Col: 0}, {Row: 2, Col: 1}, {Row: 2, Col: 2}}, 0},
+		{"Column", 5, 4, []Cell{{Row: 1, Col: 4}, {Row: 2, Col: 4}, {Row: 3, Col: 4}, {Row: 4, Col: 4}}, 1},
+		{"Diagonal", 7, 5, []Cell{{Row: 1, Col: 2}, {Row: 2, Col: 3}, {Row: 3, Col: 4}, {Row: 4, Col: 5}, {Row: 5, Col: 6}}, 1},
+		{"AntiDiagonal", 15, 5, []Cell{{Row: 10, Col: 4}, {Row: 11, Col: 3}, {Row: 12, Col: 2}, {Row: 13, Col: 1}, {Row: 14, Col: 0}}, 1},
+		{"Gap", 15, 5, []Cell{{Row: 0, Col: 0}, {Row: 0, Col: 1}, {Row: 0, Col: 2}, {Row: 0, Col: 3}, {Row: 0, Col: 5}}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := NewGameState("abcd", tt.size, tt.winLength)
+			for _, cell := range tt.marks {
+				state.Board[cell.Row][cell.Col] = 1
+			}
+			line, winner := state.CheckWin()
+			if winner != tt.winner {
+				t.Fatalf("expected winner %d but got: %d", tt.winner, winner)
+			}
+			if winner != 0 && len(line) != tt.winLength {
+				t.Errorf("expected a line of %d but got: %v", tt.winLength, line)
+			}
+		})
+	}
+}
+
+func TestValidateDimensions(t *testing.T) {
+	tests := []struct {
+		size      int
+		winLength int
+		expected  error
+	}{
+		{3, 3, nil},
+		{15, 5, nil},
+		{2, 2, ErrInvalidBoardSize},
+		{16, 5, ErrInvalidBoardSize},
+		{5, 6, ErrInvalidWinLength},
+		{5, 2, ErrInvalidWinLength},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateDimensions(tt.size, tt.winLength); err != tt.expected {
+			t.Errorf("expected %v for %dx%d with %d but got: %v", tt.expected, tt.size, tt.size, tt.winLength, err)
+		}
+	}
+}
+
+func TestMakePlayOutOfBounds(t *testing.T) {
+	state := NewGameState("abcd", 3, 3)
+	state.Player1 = gameroom.NewDuelPlayer("p1")
+	for _, cell := range []Cell{{Row: -1, Col: 0}, {Row: 3, Col: 0}, {Row: 0, Col: -1}, {Row: 0, Col: 3}} {
+		if err := state.MakePlay(1, cell.Row, cell.Col); !errors.Is(err, ErrOutOfBounds) {
+			t.Errorf("expected %v for %v but got: %v", ErrOutOfBounds, cell, err)
+		}
+	}
+	if err := state.MakePlay(1, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := state.MakePlay(1, 0, 0); err != ErrCellOcuppied {
+		t.Errorf("expected %v but got: %v", ErrCellOcuppied, err)
+	}
+}
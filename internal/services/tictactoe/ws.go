@@ -1,31 +0,0 @@
-package tictactoe
-
-import (
-	"github.com/FredericoBento/HandGame/internal/ws"
-)
-
-func (s *TicTacToeService) Run(hub *ws.Hub) {
-	for {
-		select {
-		case client := <-hub.Register:
-			hub.Clients[client.Username] = client
-			s.Log.Info("User " + client.Username + " has connected")
-			break
-
-		case client := <-hub.Unregister:
-			s.Log.Info("User " + client.Username + " has disconnected")
-			s.PlayerDisconnect(client)
-			break
-
-		case event := <-hub.Broadcast:
-			if event.RoomCode != "" {
-				if _, ok := hub.Rooms[event.RoomCode]; ok {
-					for _, client := range hub.Rooms[event.RoomCode].Clients {
-						client.Event <- event
-					}
-				}
-			}
-			break
-		}
-	}
-}
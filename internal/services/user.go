@@ -26,6 +26,8 @@ var (
 	ErrInvalidLogger        = errors.New("invalid logger passed")
 	ErrCouldNotContactDB    = errors.New("call to repository resulted in a error, could not contact db")
 	ErrCouldNotHashPassword = errors.New("could not hash password")
+	ErrInvalidRole          = errors.New("invalid role")
+	ErrCouldNotUpdateRole   = errors.New("could not update user role")
 )
 
 type UserService struct {
@@ -128,6 +130,47 @@ func (us *UserService) CreateUser(ctx context.Context, user *models.User) error
 	return nil
 }
 
+// SetRole changes the role of the user and returns it updated
+func (us *UserService) SetRole(ctx context.Context, username string, role models.Role) (*models.User, error) {
+	if !role.IsValid() {
+		return nil, ErrInvalidRole
+	}
+
+	err := us.repo.UpdateRole(ctx, username, role)
+	if err != nil {
+		us.log.Error(err.Error())
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCouldNotFindUser
+		}
+		return nil, ErrCouldNotUpdateRole
+	}
+
+	// The cached user would keep the old role until evicted
+	us.cache.Delete(username)
+	return us.GetUserByUsername(ctx, username)
+}
+
+// BootstrapAdmins makes the users admins while there is no admin yet, roles
+// changed from the admin page afterwards are kept across restarts. Users that
+// do not exist yet are granted once they do, on a later startup
+func (us *UserService) BootstrapAdmins(ctx context.Context, usernames []string) error {
+	users, err := us.GetAllUsers()
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		if user.IsAdmin() {
+			return nil
+		}
+	}
+	for _, username := range usernames {
+		if _, err := us.SetRole(ctx, username, models.RoleAdmin); err != nil {
+			us.log.Error("could not grant admin role to "+username, "err", err.Error())
+		}
+	}
+	return nil
+}
+
 func (us *UserService) ComparePassword(hashedPassword string, password string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 	return err == nil
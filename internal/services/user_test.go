@@ -147,12 +147,13 @@ func TestComparePassword(t *testing.T) {
 
 			s := NewUserService(mockRepo, 2*time.Minute)
 
-			equal, err := s.ComparePassword(tt.password, tt.hashedPassword)
-
+			hashed, err := s.HashPassword(tt.hashedPassword)
 			if err != tt.expectedErr {
 				t.Errorf("expected error %v, got %v", tt.expectedErr, err)
 			}
 
+			equal := s.ComparePassword(hashed, tt.password)
+
 			if equal != tt.expectedResult {
 				t.Errorf("expected result %v, got %v", tt.expectedResult, equal)
 			}
@@ -252,3 +253,81 @@ func TestGetAllUsers(t *testing.T) {
 	}
 
 }
+
+func TestSetRole(t *testing.T) {
+	t.Run("Promote", func(t *testing.T) {
+		mockRepo := &mock.MockUserRepository{
+			GetByUsernameResult: &models.User{Username: "member", Role: models.RolePlayer},
+		}
+		userService := NewUserService(mockRepo, 2*time.Minute)
+
+		// Cache the user first, the new role must not be hidden by it
+		if _, err := userService.GetUserByUsername(context.TODO(), "member"); err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+
+		user, err := userService.SetRole(context.TODO(), "member", models.RolePlayer.Promoted())
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if user.Role != models.RoleModerator {
+			t.Errorf("expected %s but got: %s", models.RoleModerator, user.Role)
+		}
+	})
+
+	t.Run("InvalidRole", func(t *testing.T) {
+		userService := NewUserService(&mock.MockUserRepository{}, 2*time.Minute)
+
+		_, err := userService.SetRole(context.TODO(), "member", models.Role("owner"))
+		if !errors.Is(err, ErrInvalidRole) {
+			t.Errorf("expected %v but got: %v", ErrInvalidRole, err)
+		}
+	})
+
+	t.Run("UserNotFound", func(t *testing.T) {
+		mockRepo := &mock.MockUserRepository{UpdateRoleError: sql.ErrNoRows}
+		userService := NewUserService(mockRepo, 2*time.Minute)
+
+		_, err := userService.SetRole(context.TODO(), "missing", models.RoleAdmin)
+		if !errors.Is(err, ErrCouldNotFindUser) {
+			t.Errorf("expected %v but got: %v", ErrCouldNotFindUser, err)
+		}
+	})
+}
+
+func TestBootstrapAdmins(t *testing.T) {
+	tests := []struct {
+		name     string
+		users    []models.User
+		expected models.Role
+	}{
+		{"NoAdminYet", []models.User{{Username: "fred", Role: models.RolePlayer}}, models.RoleAdmin},
+		{"AdminExists", []models.User{{Username: "fred", Role: models.RolePlayer}, {Username: "bento", Role: models.RoleAdmin}}, models.RolePlayer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fred := &models.User{Username: "fred", Role: models.RolePlayer}
+			mockRepo := &mock.MockUserRepository{GetByUsernameResult: fred, GetAllResult: tt.users}
+			userService := NewUserService(mockRepo, 2*time.Minute)
+
+			if err := userService.BootstrapAdmins(context.TODO(), []string{"fred"}); err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+			if fred.Role != tt.expected {
+				t.Errorf("expected fred to be %s but got: %s", tt.expected, fred.Role)
+			}
+		})
+	}
+}
+
+func TestRolePromotedDemoted(t *testing.T) {
+	if models.RoleAdmin.Promoted() != models.RoleAdmin {
+		t.Errorf("expected admin to stay admin when promoted")
+	}
+	if models.RolePlayer.Demoted() != models.RolePlayer {
+		t.Errorf("expected player to stay player when demoted")
+	}
+	if models.RoleAdmin.Demoted() != models.RoleModerator {
+		t.Errorf("expected admin to be demoted to moderator but got: %s", models.RoleAdmin.Demoted())
+	}
+}
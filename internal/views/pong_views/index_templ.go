@@ -8,7 +8,10 @@ package pong_views
 import "github.com/a-h/templ"
 import templruntime "github.com/a-h/templ/runtime"
 
-import "github.com/FredericoBento/HandGame/internal/models"
+import (
+	"github.com/FredericoBento/HandGame/internal/models"
+	"github.com/FredericoBento/HandGame/internal/views/components"
+)
 
 func Home() templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
@@ -36,6 +39,10 @@ func Home() templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
+		templ_7745c5c3_Err = components.Chat("pong").Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("</div></section>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
@@ -62,7 +69,7 @@ func Menu() templ.Component {
 			templ_7745c5c3_Var2 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("<script>var exports = {};</script><div class=\"field has-addons has-addons-centered\" id=\"room-menu\"><div class=\"control\"><input class=\"input\" id=\"code\" name=\"code\" type=\"text\" placeholder=\"Code\"></div><div class=\"control\"><button id=\"joinBtn\" class=\"button is-info\">Join</button></div><div class=\"control\"><button class=\"button is-success\" id=\"createBtn\">Create Game\t\t\t\t\t</button></div></div><div class=\"painel is-flex is-justify-content-center\" id=\"roomInfo\"></div><div id=\"canvasDiv\" class=\"container is-flex is-justify-content-center\"><br>")
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("<script>var exports = {};</script><div class=\"field has-addons has-addons-centered\" id=\"room-menu\"><div class=\"control\"><input class=\"input\" id=\"code\" name=\"code\" type=\"text\" placeholder=\"Code\"></div><div class=\"control\"><button id=\"joinBtn\" class=\"button is-info\">Join</button></div><div class=\"control\"><button class=\"button is-success\" id=\"createBtn\">Create Game\t\t\t\t\t</button></div><div class=\"control\"><button class=\"button is-warning\" id=\"findBtn\">Find Match</button></div><div class=\"control\"><button class=\"button is-link is-light\" id=\"spectateBtn\">Spectate</button></div></div><div class=\"field is-flex is-justify-content-center\" id=\"roomOptions\"><label class=\"checkbox mr-4\"><input type=\"checkbox\" id=\"allowSpectators\" checked> Allow spectators</label><div class=\"select is-small\"><select id=\"aiLevel\"><option value=\"\">Play vs a player</option> <option value=\"easy\">Practice vs computer (easy)</option> <option value=\"normal\">Practice vs computer (normal)</option> <option value=\"hard\">Practice vs computer (hard)</option></select></div></div><div class=\"painel is-flex is-justify-content-center\" id=\"roomInfo\"></div><div class=\"is-flex is-justify-content-center\"><p class=\"is-size-7 has-text-grey\" id=\"spectatorsLabel\"></p></div><div id=\"canvasDiv\" class=\"container is-flex is-justify-content-center\"><br>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -160,7 +167,7 @@ func RoomsTable(rooms []models.Room) templ.Component {
 			var templ_7745c5c3_Var6 string
 			templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(room.Name)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pong_views/index.templ`, Line: 78, Col: 20}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `index.templ`, Line: 109, Col: 20}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var6))
 			if templ_7745c5c3_Err != nil {
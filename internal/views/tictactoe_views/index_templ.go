@@ -8,6 +8,8 @@ package tictactoe_views
 import "github.com/a-h/templ"
 import templruntime "github.com/a-h/templ/runtime"
 
+import "github.com/FredericoBento/HandGame/internal/views/components"
+
 func Home() templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
@@ -34,6 +36,10 @@ func Home() templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
+		templ_7745c5c3_Err = components.Chat("ttt").Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("</div></section>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
@@ -60,7 +66,7 @@ func Menu() templ.Component {
 			templ_7745c5c3_Var2 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("<div class=\"field has-addons has-addons-centered\" id=\"room-menu\"><div class=\"control\"><input class=\"input\" id=\"ttt_code\" name=\"code\" type=\"text\" placeholder=\"Code\"></div><div class=\"control\"><button id=\"tictactoe_join_btn\" class=\"button is-info\">Join</button></div><div class=\"control\"><button class=\"button is-success\" id=\"tictactoe_create_btn\">Create Game\t\t\t\t\t</button></div></div><div class=\"block painel is-flex is-justify-content-center\"><p class=\"subtitle is-4\" id=\"ttt_code_label\"></p></div>")
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("<div class=\"field has-addons has-addons-centered\" id=\"room-menu\"><div class=\"control\"><input class=\"input\" id=\"ttt_code\" name=\"code\" type=\"text\" placeholder=\"Code\"></div><div class=\"control\"><button id=\"tictactoe_join_btn\" class=\"button is-info\">Join</button></div><div class=\"control\"><button class=\"button is-success\" id=\"tictactoe_create_btn\">Create Game\t\t\t\t\t</button></div><div class=\"control\"><button class=\"button is-warning\" id=\"tictactoe_find_btn\">Find Match</button></div><div class=\"control\"><button class=\"button is-link is-light\" id=\"tictactoe_spectate_btn\">Spectate</button></div></div><div class=\"field is-flex is-justify-content-center\" id=\"ttt_room_options\"><label class=\"checkbox mr-4\"><input type=\"checkbox\" id=\"ttt_allow_spectators\" checked> Allow spectators</label><div class=\"select is-small\"><select id=\"ttt_bot\"><option value=\"\">Play vs a player</option> <option value=\"random\">Play vs computer (easy)</option> <option value=\"heuristic\">Play vs computer (medium)</option> <option value=\"minimax\">Play vs computer (perfect)</option></select></div><div class=\"select is-small ml-4\"><select id=\"ttt_dimensions\"><option value=\"3:3\">3x3, 3 in a row</option> <option value=\"5:4\">5x5, 4 in a row</option> <option value=\"7:5\">7x7, 5 in a row</option> <option value=\"15:5\">15x15 gomoku, 5 in a row</option></select></div></div><div class=\"block painel is-flex is-justify-content-center\"><p class=\"subtitle is-4\" id=\"ttt_code_label\"></p></div><div class=\"block is-flex is-justify-content-center\"><p class=\"is-size-7 has-text-grey\" id=\"ttt_spectators_label\"></p></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -76,6 +82,8 @@ func Menu() templ.Component {
 	})
 }
 
+// Board starts as 3 by 3, the scripts lay out the cells again for the size
+// of the game joined
 func Board() templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
@@ -94,33 +102,17 @@ func Board() templ.Component {
 			templ_7745c5c3_Var3 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("<div id=\"ttt_board\" class=\"block\"><div class=\"grid ttt_board_body\"><div class=\"cell\"></div><div class=\"cell\"></div><div class=\"cell\"></div><div class=\"cell\"></div><div class=\"cell\"></div><div class=\"cell\"></div><div class=\"cell\"></div><div class=\"cell\"></div><div class=\"cell\"></div></div><div id=\"game-result-overlay\" class=\"block is-hidden\"><p class=\"result-text\" id=\"result-text\"></p></div></div><div id=\"scoreboard\" class=\"block is-flex is-justify-content-center has-text-centered has-text-white\"><div id=\"scoreboard-content\" class=\"columns is-centered is-vcentered\"><div class=\"column is-narrow\"><p class=\"subtitle is-6\" id=\"player1_label\"></p><p class=\"subtitle is-4\" id=\"player1_wins\"></p></div><div class=\"column is-narrow\"><p class=\"subtitle is-6\">TIE</p><p class=\"subtitle is-4\" id=\"ties\"></p></div><div class=\"column is-narrow\"><p class=\"subtitle is-6\" id=\"player2_label\"></p><p class=\"subtitle is-4\" id=\"player2_wins\"></p></div></div><div id=\"game-result-message\" class=\"is-hidden\"><p class=\"result-text\" id=\"result-text\"></p></div></div>")
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("<div id=\"ttt_board\" class=\"block\"><div class=\"grid ttt_board_body\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		return templ_7745c5c3_Err
-	})
-}
-
-func Chat() templ.Component {
-	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
-		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
-		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
-		if !templ_7745c5c3_IsBuffer {
-			defer func() {
-				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
-				if templ_7745c5c3_Err == nil {
-					templ_7745c5c3_Err = templ_7745c5c3_BufErr
-				}
-			}()
+		for range 9 {
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("<div class=\"cell\"></div>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
 		}
-		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var4 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var4 == nil {
-			templ_7745c5c3_Var4 = templ.NopComponent
-		}
-		ctx = templ.ClearChildren(ctx)
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("<div class=\"container is-max-desktop box\"><p class=\"subtitle is-4\">Chat</p><hr class=\"has-background-dark\"><div id=\"tictactoe_users-online\"></div><div id=\"tictactoe_messages\"></div></div>")
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("</div><div id=\"game-result-overlay\" class=\"block is-hidden\"><p class=\"result-text\" id=\"result-text\"></p></div></div><div id=\"scoreboard\" class=\"block is-flex is-justify-content-center has-text-centered has-text-white\"><div id=\"scoreboard-content\" class=\"columns is-centered is-vcentered\"><div class=\"column is-narrow\"><p class=\"subtitle is-6\" id=\"player1_label\"></p><p class=\"subtitle is-4\" id=\"player1_wins\"></p></div><div class=\"column is-narrow\"><p class=\"subtitle is-6\">TIE</p><p class=\"subtitle is-4\" id=\"ties\"></p></div><div class=\"column is-narrow\"><p class=\"subtitle is-6\" id=\"player2_label\"></p><p class=\"subtitle is-4\" id=\"player2_wins\"></p></div></div><div id=\"game-result-message\" class=\"is-hidden\"><p class=\"result-text\" id=\"result-text\"></p></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
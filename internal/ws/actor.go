@@ -0,0 +1,123 @@
+package ws
+
+import (
+	"sync"
+)
+
+// actor runs commands one at a time on the goroutine that owns some state,
+// the state needs no locks as long as it is only touched from its commands.
+// The queue is unbounded so posting never blocks and never reorders, an
+// owner goroutine can post to another one that may be waiting on it
+type actor struct {
+	mu      sync.Mutex
+	queue   []func()
+	stopped bool
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newActor() actor {
+	return actor{
+		wake: make(chan struct{}, 1),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+}
+
+// do runs f on the owner goroutine and waits for it. It returns false if the
+// actor stopped before running it. It must never be called from the owner
+// goroutine itself, that would wait forever
+func (a *actor) do(f func()) bool {
+	finished := make(chan struct{})
+	command := func() {
+		f()
+		close(finished)
+	}
+	if !a.post(command) {
+		return false
+	}
+	select {
+	case <-finished:
+		return true
+	case <-a.done:
+		// Commands are never interrupted, f either ran whole or not at all
+		select {
+		case <-finished:
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// post queues f without waiting for it, commands run in the order they were
+// posted
+func (a *actor) post(f func()) bool {
+	a.mu.Lock()
+	if a.stopped {
+		a.mu.Unlock()
+		return false
+	}
+	a.queue = append(a.queue, f)
+	a.mu.Unlock()
+
+	select {
+	case a.wake <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// runQueued executes the commands posted so far, it stops early if the
+// actor is told to stop
+func (a *actor) runQueued() {
+	a.mu.Lock()
+	commands := a.queue
+	a.queue = nil
+	a.mu.Unlock()
+
+	for _, command := range commands {
+		select {
+		case <-a.stop:
+			return
+		default:
+		}
+		command()
+	}
+}
+
+// finish drops the commands still queued and refuses new ones
+func (a *actor) finish() {
+	a.mu.Lock()
+	a.stopped = true
+	a.queue = nil
+	a.mu.Unlock()
+	close(a.done)
+}
+
+// run executes the commands until the actor is stopped
+func (a *actor) run() {
+	defer a.finish()
+	for {
+		select {
+		case <-a.wake:
+			a.runQueued()
+		case <-a.stop:
+			return
+		}
+	}
+}
+
+// halt stops the loop and waits for it to exit
+func (a *actor) halt() {
+	a.mu.Lock()
+	select {
+	case <-a.stop:
+	default:
+		close(a.stop)
+	}
+	a.mu.Unlock()
+	<-a.done
+}
@@ -0,0 +1,108 @@
+package ws
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestRoomRunsCommandsInOrder(t *testing.T) {
+	room := NewRoom("ABCD", 2)
+	room.Start()
+	defer room.Stop()
+
+	got := []int{}
+	for i := 0; i < 100; i++ {
+		room.Post(func() {
+			got = append(got, i)
+		})
+	}
+	room.Do(func() {})
+
+	if len(got) != 100 {
+		t.Fatalf("expected 100 commands to run but got: %d", len(got))
+	}
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("expected command %d to run in order but got: %d", i, v)
+		}
+	}
+}
+
+func TestRoomSerialisesConcurrentCommands(t *testing.T) {
+	room := NewRoom("ABCD", 100)
+	room.Start()
+	defer room.Stop()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client := &Client{Username: string(rune('A' + i))}
+			room.Do(func() {
+				room.AddClient(client)
+			})
+		}()
+	}
+	wg.Wait()
+
+	count := 0
+	room.Do(func() {
+		count = len(room.Clients)
+	})
+	if count != 50 {
+		t.Errorf("expected 50 clients but got: %d", count)
+	}
+}
+
+func TestRoomStopped(t *testing.T) {
+	room := NewRoom("ABCD", 2)
+	room.Start()
+	room.Stop()
+	// Stopping twice is harmless
+	room.Stop()
+
+	if room.Do(func() {}) {
+		t.Errorf("expected Do to fail on a stopped room")
+	}
+	if room.Post(func() {}) {
+		t.Errorf("expected Post to fail on a stopped room")
+	}
+}
+
+type recordingHandler struct {
+	hub        *Hub
+	registered []string
+}
+
+func (h *recordingHandler) OnRegister(client *Client) {
+	h.hub.Clients[client.Username] = client
+	h.registered = append(h.registered, client.Username)
+}
+
+func (h *recordingHandler) OnUnregister(client *Client) {
+	delete(h.hub.Clients, client.Username)
+}
+
+func TestHubRunsHandlerAndCommands(t *testing.T) {
+	hub := NewHub()
+	handler := &recordingHandler{hub: hub}
+	go hub.Run(handler)
+
+	hub.Register <- &Client{Username: "fred"}
+	hub.Register <- &Client{Username: "bento"}
+	hub.Unregister <- &Client{Username: "fred"}
+
+	var clients int
+	var registered []string
+	hub.Do(func() {
+		clients = len(hub.Clients)
+		registered = append(registered, handler.registered...)
+	})
+	if clients != 1 {
+		t.Errorf("expected 1 client but got: %d", clients)
+	}
+	if len(registered) != 2 {
+		t.Errorf("expected 2 registrations but got: %v", registered)
+	}
+}
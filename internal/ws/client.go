@@ -3,17 +3,32 @@ package ws
 import (
 	"encoding/json"
 	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/FredericoBento/HandGame/internal/utils"
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
 type Client struct {
-	Conn     *websocket.Conn
+	// ID tells apart the connections of a user, one per tab
+	ID   string
+	Conn *websocket.Conn
+	// Event is the queue of the events waiting for the WritePump, which
+	// writes them in the order they were sent, see SendEvent
 	Event    chan *Event
 	Username string
 	RoomCode string
+	// Binary is set when the client negotiated a binary subprotocol, events
+	// carrying a binary payload are then sent as binary frames
+	Binary bool
+	// Spectating is set while the client watches RoomCode without playing
+	Spectating bool
+
+	// mu guards closing Event against the senders
+	mu     sync.Mutex
+	closed bool
 }
 
 type ReadMessageHandler func(*Client, []byte)
@@ -21,23 +36,56 @@ type ReadMessageHandler func(*Client, []byte)
 type ReadEventHandler func(*Client, Event)
 
 const (
-	writeWait      = 34 * time.Millisecond
-	pongWait       = 500 * time.Millisecond
-	pingPeriod     = (pongWait * 9) / 20
-	maxMessageSize = 128
+	writeWait  = 34 * time.Millisecond
+	pongWait   = 500 * time.Millisecond
+	pingPeriod = (pongWait * 9) / 20
+	// maxMessageSize fits the largest event clients send, a chat message of
+	// chat.default_max_length characters even with every one of them escaped
+	maxMessageSize = 4096
+	// sendQueueSize is how many events may wait for the WritePump, a client
+	// that falls this far behind is disconnected
+	sendQueueSize = 256
 )
 
 func NewClient(conn *websocket.Conn, username string) *Client {
 	return &Client{
+		ID:       uuid.NewString(),
 		Conn:     conn,
-		Event:    make(chan *Event),
+		Event:    make(chan *Event, sendQueueSize),
 		Username: username,
 		RoomCode: "",
 	}
 }
 
+// SendEvent queues the event for the WritePump without waiting, events sent
+// from the same goroutine reach the client in order. A client whose queue is
+// full is too slow to keep up, it is closed instead of holding up the sender.
+// Events sent to a closed client are dropped
 func (client *Client) SendEvent(e *Event) {
-	client.Event <- e
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if client.closed {
+		return
+	}
+	select {
+	case client.Event <- e:
+	default:
+		slog.Warn("Closing slow client", "username", client.Username, "type", e.Type)
+		client.closed = true
+		close(client.Event)
+	}
+}
+
+// Close closes the queue of the client once its connection is gone, the
+// WritePump writes a close frame and stops. It may be called more than once
+func (client *Client) Close() {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if client.closed {
+		return
+	}
+	client.closed = true
+	close(client.Event)
 }
 
 func (client *Client) SendErrorEvent(e *Event) {
@@ -47,19 +95,31 @@ func (client *Client) SendErrorEvent(e *Event) {
 	client.SendEvent(e)
 }
 
-func (client *Client) SendErrorEventWithMessage(e *Event, message string) {
+func (client *Client) SendErrorEventWithError(e *Event, err error) {
+	if client.ErrorEventWithError(e, err) {
+		client.SendEvent(e)
+	}
+}
+
+// ErrorEventWithError turns e into an error event for the client without
+// sending it, see AsError. It reads the room of the client, so services call
+// it from the goroutine owning the client and only hand the sending to
+// another one
+func (client *Client) ErrorEventWithError(e *Event, err error) bool {
+	eventErr := AsError(err)
 	type Message struct {
 		Message string `json:"message"`
 	}
-	m, err := utils.EncodeJSON(Message{Message: message})
-	if err != nil {
-		slog.Error("Could not send message in error event with message")
-		return
+	m, encodeErr := utils.EncodeJSON(Message{Message: eventErr.Message})
+	if encodeErr != nil {
+		slog.Error("Could not send message in error event with error")
+		return false
 	}
 	e.IsError = true
+	e.Error = eventErr
 	e.Data = m
 	e.RoomCode = client.RoomCode
-	client.SendEvent(e)
+	return true
 }
 
 func (client *Client) ReadPump(hub *Hub, handler ReadEventHandler) {
@@ -106,35 +166,10 @@ func (client *Client) WritePump() {
 				client.Conn.WriteMessage(websocket.CloseMessage, []byte{})
 				return
 			}
-			w, err := client.Conn.NextWriter(websocket.TextMessage)
-			if err != nil {
+			if err := client.writeEvent(event); err != nil {
+				slog.Error("Error while writing event: "+err.Error(), "type", event.Type)
 				return
 			}
-			eventBytes, err := utils.EncodeJSON(event)
-			if err != nil {
-				slog.Error("Error while marshiling: "+err.Error(), event.Type)
-				return
-			}
-			w.Write(eventBytes)
-
-			n := len(client.Event)
-			for i := 0; i < n; i++ {
-				e, ok := <-client.Event
-				if !ok {
-					client.Conn.WriteMessage(websocket.CloseMessage, []byte{})
-					return
-				}
-				eventBytes, err := utils.EncodeJSON(e)
-				if err != nil {
-					slog.Error("Error while marshiling: " + err.Error())
-					return
-				}
-				w.Write(eventBytes)
-
-				if err := w.Close(); err != nil {
-					return
-				}
-			}
 		case <-ticker.C:
 			client.Conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if err := client.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
@@ -143,3 +178,14 @@ func (client *Client) WritePump() {
 		}
 	}
 }
+
+func (client *Client) writeEvent(event *Event) error {
+	if client.Binary && event.Binary != nil {
+		return client.Conn.WriteMessage(websocket.BinaryMessage, event.Binary)
+	}
+	eventBytes, err := utils.EncodeJSON(event)
+	if err != nil {
+		return err
+	}
+	return client.Conn.WriteMessage(websocket.TextMessage, eventBytes)
+}
@@ -0,0 +1,112 @@
+package ws
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/websocket"
+)
+
+// chat_max_length is chat.default_max_length, the chat package can not be
+// imported from here
+const chat_max_length = 280
+
+func TestReadPumpTakesTheLongestChatMessage(t *testing.T) {
+	hub := NewHub()
+	go func() {
+		for range hub.Unregister {
+		}
+	}()
+	received := make(chan Event, 1)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		client := NewClient(conn, "p1")
+		client.ReadPump(hub, func(_ *Client, event Event) {
+			received <- event
+		})
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// Every character escaped as a surrogate pair is as long as it gets
+	text := strings.Repeat(`\ud83d\ude00`, chat_max_length)
+	message := `{"type":4,"data":{"text":"` + text + `"}}`
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(message)); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case event := <-received:
+		data := struct {
+			Text string `json:"text"`
+		}{}
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			t.Fatal(err)
+		}
+		if n := utf8.RuneCountInString(data.Text); n != chat_max_length {
+			t.Errorf("expected %d characters but got: %d", chat_max_length, n)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected the %d bytes message to be read", len(message))
+	}
+}
+
+func TestRoomEventsArriveInOrder(t *testing.T) {
+	room, p1, _ := newReplayTestRoom(t)
+
+	event := NewEvent(1, room.Code)
+	for range 100 {
+		room.SendAll(&event)
+	}
+	for i, event := range receive(t, p1, 100) {
+		if event.Seq != uint64(i+1) {
+			t.Fatalf("expected event %d but got: %d", i+1, event.Seq)
+		}
+	}
+}
+
+func TestSlowClientIsClosed(t *testing.T) {
+	client := NewClient(nil, "slow")
+	event := NewSimpleEvent(1)
+	// Nobody drains the queue, the sender must not wait on it
+	for range sendQueueSize + 1 {
+		client.SendEvent(&event)
+	}
+
+	queued := 0
+	for range client.Event {
+		queued++
+	}
+	if queued != sendQueueSize {
+		t.Errorf("expected %d queued events but got: %d", sendQueueSize, queued)
+	}
+
+	// Sending to or closing a closed client does nothing
+	client.SendEvent(&event)
+	client.Close()
+}
+
+func TestClosedClientDropsEvents(t *testing.T) {
+	client := NewClient(nil, "gone")
+	client.Close()
+	event := NewSimpleEvent(1)
+	client.SendEvent(&event)
+
+	if _, ok := <-client.Event; ok {
+		t.Errorf("expected the queue to be closed and empty")
+	}
+}
@@ -0,0 +1,117 @@
+package ws
+
+import (
+	"errors"
+)
+
+// ErrorCode tells clients apart the errors they can react to, the message of
+// an error may change but its code does not
+type ErrorCode string
+
+// The codes are shared by every game, a code means the same thing whatever
+// the game that sent it, so a client handles it once
+const (
+	ErrorCodeInternal     ErrorCode = "internal"
+	ErrorCodeUnknownEvent ErrorCode = "unknown_event"
+	ErrorCodeInvalidData  ErrorCode = "invalid_data"
+
+	ErrorCodeRoomNotFound       ErrorCode = "room_not_found"
+	ErrorCodeRoomFull           ErrorCode = "room_full"
+	ErrorCodeAlreadyInRoom      ErrorCode = "already_in_room"
+	ErrorCodeNotInRoom          ErrorCode = "not_in_room"
+	ErrorCodeSpectatingDisabled ErrorCode = "spectating_disabled"
+	ErrorCodeAlreadyQueued      ErrorCode = "already_queued"
+
+	ErrorCodeNotAPlayer     ErrorCode = "not_a_player"
+	ErrorCodeGameNotRunning ErrorCode = "game_not_running"
+	ErrorCodeNotYourTurn    ErrorCode = "not_your_turn"
+	ErrorCodeInvalidOptions ErrorCode = "invalid_options"
+	ErrorCodeInvalidPlay    ErrorCode = "invalid_play"
+	ErrorCodeAlreadyPlayed  ErrorCode = "already_played"
+	ErrorCodeOutOfBounds    ErrorCode = "out_of_bounds"
+	ErrorCodeCellOccupied   ErrorCode = "cell_occupied"
+	ErrorCodeColumnFull     ErrorCode = "column_full"
+
+	ErrorCodeInvalidMessage ErrorCode = "invalid_message"
+	ErrorCodeRateLimited    ErrorCode = "rate_limited"
+)
+
+// error_codes is the registry of the codes, one missing from it is reported
+// as ErrorCodeInternal
+var error_codes = []ErrorCode{
+	ErrorCodeInternal,
+	ErrorCodeUnknownEvent,
+	ErrorCodeInvalidData,
+	ErrorCodeRoomNotFound,
+	ErrorCodeRoomFull,
+	ErrorCodeAlreadyInRoom,
+	ErrorCodeNotInRoom,
+	ErrorCodeSpectatingDisabled,
+	ErrorCodeAlreadyQueued,
+	ErrorCodeNotAPlayer,
+	ErrorCodeGameNotRunning,
+	ErrorCodeNotYourTurn,
+	ErrorCodeInvalidOptions,
+	ErrorCodeInvalidPlay,
+	ErrorCodeAlreadyPlayed,
+	ErrorCodeOutOfBounds,
+	ErrorCodeCellOccupied,
+	ErrorCodeColumnFull,
+	ErrorCodeInvalidMessage,
+	ErrorCodeRateLimited,
+}
+
+// ErrorCodes returns every code clients may get
+func ErrorCodes() []ErrorCode {
+	return append([]ErrorCode(nil), error_codes...)
+}
+
+// Registered tells if the code is part of the registry
+func (c ErrorCode) Registered() bool {
+	for _, code := range error_codes {
+		if code == c {
+			return true
+		}
+	}
+	return false
+}
+
+// Error is the error of an event as clients get it. Field names the part of
+// the data of the event that was wrong, if it was only one
+type Error struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+	Field   string    `json:"field,omitempty"`
+}
+
+func NewError(code ErrorCode, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is leaves the field out, so a copy made by WithField still is the error it
+// came from
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code && t.Message == e.Message
+}
+
+// WithField returns a copy of the error about field
+func (e *Error) WithField(field string) *Error {
+	copy := *e
+	copy.Field = field
+	return &copy
+}
+
+// AsError returns the Error in err, an error without one keeps its message
+// under ErrorCodeInternal
+func AsError(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) && e.Code.Registered() {
+		return e
+	}
+	return NewError(ErrorCodeInternal, err.Error())
+}
@@ -0,0 +1,74 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorEventEnvelope(t *testing.T) {
+	client := &Client{Username: "p1", RoomCode: "ABCD"}
+	event := NewSimpleEvent(3)
+	err := NewError(ErrorCodeOutOfBounds, "Not on the board").WithField("col")
+	if !client.ErrorEventWithError(&event, fmt.Errorf("playing: %w", err)) {
+		t.Fatal("expected the error event to be made")
+	}
+
+	bytes, jsonErr := json.Marshal(event)
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	type Envelope struct {
+		IsError  bool   `json:"isError"`
+		RoomCode string `json:"roomCode"`
+		Error    Error  `json:"error"`
+		Data     struct {
+			Message string `json:"message"`
+		} `json:"data"`
+	}
+	envelope := Envelope{}
+	if jsonErr := json.Unmarshal(bytes, &envelope); jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	expected := Error{Code: ErrorCodeOutOfBounds, Message: "Not on the board", Field: "col"}
+	if !envelope.IsError || envelope.RoomCode != "ABCD" || envelope.Error != expected {
+		t.Errorf("expected %+v in room ABCD but got: %s", expected, bytes)
+	}
+	if envelope.Data.Message != expected.Message {
+		t.Errorf("expected the message to be kept in the data but got: %s", bytes)
+	}
+}
+
+func TestAsError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected ErrorCode
+	}{
+		{"Coded", NewError(ErrorCodeRoomFull, "full"), ErrorCodeRoomFull},
+		{"Wrapped", fmt.Errorf("joining: %w", NewError(ErrorCodeRoomFull, "full")), ErrorCodeRoomFull},
+		{"Plain", errors.New("something broke"), ErrorCodeInternal},
+		{"Unregistered", NewError("made_up", "made up"), ErrorCodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := AsError(tt.err).Code; code != tt.expected {
+				t.Errorf("expected %s but got: %s", tt.expected, code)
+			}
+		})
+	}
+}
+
+func TestErrorIsLeavesTheFieldOut(t *testing.T) {
+	err := NewError(ErrorCodeOutOfBounds, "Not on the board")
+	if !errors.Is(err.WithField("row"), err) {
+		t.Errorf("expected a copy with a field to be the same error")
+	}
+	if errors.Is(NewError(ErrorCodeOutOfBounds, "Another message"), err) {
+		t.Errorf("expected errors with other messages to be told apart")
+	}
+	if err.Field != "" {
+		t.Errorf("expected WithField to leave the error untouched but got: %q", err.Field)
+	}
+}
@@ -14,13 +14,38 @@ type Event struct {
 	Data     json.RawMessage `json:"data,omitempty"`
 	RoomCode string          `json:"roomCode,omitempty"`
 	IsError  bool            `json:"isError,omitempty"`
+	// Error is set along IsError, the message is also kept in the data for
+	// clients that do not read it yet
+	Error *Error `json:"error,omitempty"`
+	// Seq orders the events sent to a room, a client that lost its
+	// connection gives back the last one it got to resume, see Room.Replay.
+	// It is zero for events that are not part of the history of a room
+	Seq uint64 `json:"seq,omitempty"`
+	// Binary is an optional encoding of the same event for clients using a
+	// binary subprotocol, it is never part of the json
+	Binary []byte `json:"-"`
+}
+
+// ErrInvalidPing is the error of a ping without a timestamp to pong back
+var ErrInvalidPing = NewError(ErrorCodeInvalidData, "Error pinging").WithField("timestamp")
+
+// EventDataUser is the user an event is about, like the one that
+// disconnected
+type EventDataUser struct {
+	Username string `json:"username"`
 }
 
 type EventPingPongData struct {
 	Timestamp string `json:"timestamp"`
 }
 
+// Events from EventTypeFirstShared up are shared by every socket, the events
+// of a game are numbered below them
 const (
+	EventTypeFirstShared = 90
+
+	EventTypeUserDisconnected = 97
+
 	EventTypePing = 98
 	EventTypePong = 99
 )
@@ -48,7 +73,7 @@ func HandleEventPing(event *Event, client *Client) {
 	err := json.Unmarshal(event.Data, &data)
 	if err != nil {
 		slog.Error("could not pong")
-		client.SendErrorEventWithMessage(event, "Error pinging")
+		client.SendErrorEventWithError(event, ErrInvalidPing)
 		return
 	}
 	eventPong := NewSimpleEvent(EventTypePong)
@@ -3,31 +3,58 @@ package ws
 import (
 	"errors"
 	"log/slog"
+	"slices"
 
 	"github.com/FredericoBento/HandGame/internal/utils"
+	"github.com/google/uuid"
 )
 
 type Room struct {
-	Code       string
+	Code string
+	// Clients are the players of the room by username, each through the
+	// first of its connections that joined, see Connections for all of them
 	Clients    map[string]*Client
 	MaxClients int
+	// Spectators receive the broadcasts of the room but do not take any of
+	// its MaxClients slots, they are kept by connection ID
+	Spectators      map[string]*Client
+	AllowSpectators bool
+
+	// connections are every connection of the players by username, a player
+	// stays in the room until the last one is gone
+	connections map[string][]*Client
+
+	// The members, the history and whatever game state the service keeps
+	// for the room belong to the room goroutine, see Start
+	history *replayBuffer
+	actor
 }
 
+// Hub is owned by the goroutine running Run, Clients and Rooms are only
+// touched from there
 type Hub struct {
+	// Clients are the connections by ID, a user may have several of them,
+	// one per tab, see Connections
 	Clients    map[string]*Client
 	Rooms      map[string]*Room
 	Register   chan *Client
 	Unregister chan *Client
 	Broadcast  chan *Event
+
+	users map[string]map[string]*Client
+	actor
+}
+
+// HubHandler is called by Hub.Run from the hub goroutine
+type HubHandler interface {
+	OnRegister(client *Client)
+	OnUnregister(client *Client)
 }
 
 var (
 	ErrClientAlreadyInRoom = errors.New("Client is already in room")
-	ErrRoomIsFull          = errors.New("Room Is full of clients")
-)
-
-const (
-	EventTypeUserDisconnected = 4
+	ErrRoomIsFull          = NewError(ErrorCodeRoomFull, "Room Is full of clients")
+	ErrSpectatingDisabled  = NewError(ErrorCodeSpectatingDisabled, "Spectating is disabled in this room")
 )
 
 func NewHub() *Hub {
@@ -37,52 +64,187 @@ func NewHub() *Hub {
 		Register:   make(chan *Client),
 		Unregister: make(chan *Client),
 		Broadcast:  make(chan *Event),
+		users:      make(map[string]map[string]*Client),
+		actor:      newActor(),
 	}
 }
 
 func NewRoom(code string, maxClients int) *Room {
 	return &Room{
-		Code:       code,
-		Clients:    make(map[string]*Client),
-		MaxClients: maxClients,
+		Code:            code,
+		Clients:         make(map[string]*Client),
+		MaxClients:      maxClients,
+		Spectators:      make(map[string]*Client),
+		AllowSpectators: true,
+		connections:     make(map[string][]*Client),
+		history:         newReplayBuffer(replay_buffer_size),
+		actor:           newActor(),
 	}
 }
 
+// Start runs the room goroutine, from then on the room must only be touched
+// from commands given to Do and Post
+func (room *Room) Start() {
+	go room.run()
+}
+
+// Stop ends the room goroutine and waits for it, the commands still queued
+// are dropped
+func (room *Room) Stop() {
+	room.halt()
+}
+
+// Do runs f on the room goroutine and waits for it, it returns false once the
+// room is stopped. It must not be called from the room goroutine
+func (room *Room) Do(f func()) bool {
+	return room.do(f)
+}
+
+// Post queues f on the room goroutine without waiting for it
+func (room *Room) Post(f func()) bool {
+	return room.post(f)
+}
+
+// Broadcast sends the event to every member of the room but the usernames in
+// except, it can be called from any goroutine. See SendAll
+func (room *Room) Broadcast(event *Event, except ...string) {
+	room.Post(func() {
+		room.SendAll(event, except...)
+	})
+}
+
+// AddClient makes the client a player of the room, the connection is added
+// to the others of the player if it is already in
 func (room *Room) AddClient(client *Client) error {
+	if conns, ok := room.connections[client.Username]; ok {
+		if slices.Contains(conns, client) {
+			return ErrClientAlreadyInRoom
+		}
+		room.connections[client.Username] = append(conns, client)
+		client.RoomCode = room.Code
+		return nil
+	}
 	if len(room.Clients) >= room.MaxClients {
 		return ErrRoomIsFull
 	}
-	if _, ok := room.Clients[client.Username]; ok {
+	if room.isSpectating(client.Username) {
 		return ErrClientAlreadyInRoom
 	}
 	room.Clients[client.Username] = client
+	room.connections[client.Username] = []*Client{client}
 	client.RoomCode = room.Code
 	return nil
 }
 
+// RemoveClient takes the connection out of the room, the player is only
+// gone with its last one, see HasPlayer
 func (room *Room) RemoveClient(client *Client) error {
 	if len(room.Clients) <= 0 {
 		err := errors.New("Removing client of empty room")
 		slog.Error(err.Error())
 		return err
 	}
-	if _, ok := room.Clients[client.Username]; !ok {
+	conns := room.connections[client.Username]
+	i := slices.Index(conns, client)
+	if i < 0 {
 		err := errors.New("Removing client from wrong room")
 		slog.Error(err.Error())
 		return err
 	}
 	client.RoomCode = ""
-	delete(room.Clients, client.Username)
+	conns = slices.Delete(conns, i, i+1)
+	if len(conns) == 0 {
+		delete(room.connections, client.Username)
+		delete(room.Clients, client.Username)
+		return nil
+	}
+	room.connections[client.Username] = conns
+	room.Clients[client.Username] = conns[0]
+	return nil
+}
+
+// HasPlayer tells if the username plays in the room through any connection
+func (room *Room) HasPlayer(username string) bool {
+	_, ok := room.Clients[username]
+	return ok
+}
+
+// HasClient tells if the connection is one of a player of the room
+func (room *Room) HasClient(client *Client) bool {
+	return slices.Contains(room.connections[client.Username], client)
+}
+
+// Connections returns every connection of the player in the room
+func (room *Room) Connections(username string) []*Client {
+	return slices.Clone(room.connections[username])
+}
+
+func (room *Room) isSpectating(username string) bool {
+	for _, c := range room.Spectators {
+		if c.Username == username {
+			return true
+		}
+	}
+	return false
+}
+
+// AddSpectator subscribes the client to the room broadcasts without letting
+// it play
+func (room *Room) AddSpectator(client *Client) error {
+	if !room.AllowSpectators {
+		return ErrSpectatingDisabled
+	}
+	if _, ok := room.Clients[client.Username]; ok {
+		return ErrClientAlreadyInRoom
+	}
+	if _, ok := room.Spectators[client.ID]; ok {
+		return ErrClientAlreadyInRoom
+	}
+	room.Spectators[client.ID] = client
+	client.RoomCode = room.Code
+	client.Spectating = true
+	return nil
+}
+
+func (room *Room) RemoveSpectator(client *Client) error {
+	if c, ok := room.Spectators[client.ID]; !ok || c != client {
+		err := errors.New("Removing spectator from wrong room")
+		slog.Error(err.Error())
+		return err
+	}
+	client.RoomCode = ""
+	client.Spectating = false
+	delete(room.Spectators, client.ID)
 	return nil
 }
 
+// SpectatorCount is the number of users watching, whatever the number of
+// connections they watch from
+func (room *Room) SpectatorCount() int {
+	usernames := make(map[string]bool, len(room.Spectators))
+	for _, c := range room.Spectators {
+		usernames[c.Username] = true
+	}
+	return len(usernames)
+}
+
+// Members returns every connection of the players and the spectators of
+// the room, everyone a room broadcast goes to
+func (room *Room) Members() []*Client {
+	members := make([]*Client, 0, len(room.Clients)+len(room.Spectators))
+	for _, conns := range room.connections {
+		members = append(members, conns...)
+	}
+	for _, c := range room.Spectators {
+		members = append(members, c)
+	}
+	return members
+}
+
 func (hub *Hub) RemoveClientBroadcast(client *Client) error {
 	event := NewSimpleEvent(EventTypeUserDisconnected)
 	event.RoomCode = client.RoomCode
-	type UsernameData struct {
-		Username string `json:"username"`
-	}
-	data := UsernameData{
+	data := EventDataUser{
 		Username: client.Username,
 	}
 	bytes, err := utils.EncodeJSON(data)
@@ -92,73 +254,78 @@ func (hub *Hub) RemoveClientBroadcast(client *Client) error {
 	}
 	event.Data = bytes
 	hub.Broadcast <- &event
-	slog.Info("broadcast here3")
 	return nil
 }
 
-func (hub *Hub) Run() {
+// AddClient indexes the connection by its ID and by its user, a connection
+// without an ID is given one
+func (hub *Hub) AddClient(client *Client) {
+	if client.ID == "" {
+		client.ID = uuid.NewString()
+	}
+	hub.Clients[client.ID] = client
+	conns, ok := hub.users[client.Username]
+	if !ok {
+		conns = make(map[string]*Client)
+		hub.users[client.Username] = conns
+	}
+	conns[client.ID] = client
+}
+
+// RemoveClient drops the connection, it returns true if its user has no
+// other one left
+func (hub *Hub) RemoveClient(client *Client) bool {
+	if c, ok := hub.Clients[client.ID]; ok && c == client {
+		delete(hub.Clients, client.ID)
+		delete(hub.users[client.Username], client.ID)
+	}
+	if len(hub.users[client.Username]) > 0 {
+		return false
+	}
+	delete(hub.users, client.Username)
+	return true
+}
+
+// Connections returns the live connections of the user
+func (hub *Hub) Connections(username string) []*Client {
+	conns := make([]*Client, 0, len(hub.users[username]))
+	for _, c := range hub.users[username] {
+		conns = append(conns, c)
+	}
+	return conns
+}
+
+// Run is the hub goroutine, registrations, broadcasts and the commands given
+// to Do and Post are handled one at a time. It never returns
+func (hub *Hub) Run(handler HubHandler) {
+	defer hub.finish()
 	for {
 		select {
 		case client := <-hub.Register:
-			hub.Clients[client.Username] = client
-			slog.Info("User " + client.Username + " has connected")
-
-			if _, ok := hub.Rooms[client.RoomCode]; ok {
-				room := hub.Rooms[client.RoomCode]
-
-				if _, ok := room.Clients[client.Username]; !ok {
-					room.Clients[client.Username] = client
-				}
-			}
+			handler.OnRegister(client)
 
 		case client := <-hub.Unregister:
-			slog.Info("User " + client.Username + " has disconnected")
-			if _, ok := hub.Rooms[client.RoomCode]; ok {
-				if _, ok := hub.Rooms[client.RoomCode].Clients[client.Username]; ok {
-					if len(hub.Rooms[client.RoomCode].Clients) != 0 {
-						room := hub.Rooms[client.RoomCode]
-						err := room.RemoveClient(client)
-						if err != nil {
-							slog.Error("CRITICAL ERROR WHEN REMOVING CLIENT")
-							return
-						}
-						delete(hub.Rooms[room.Code].Clients, client.Username)
-						// hub.RemoveClientBroadcast(client)
-
-						// for _, c := range room.Clients {
-						// 	event := NewSimpleEvent(EventTypeUserDisconnected)
-						// 	event.RoomCode = client.RoomCode
-						// 	type UsernameData struct {
-						// 		Username string `json:"username"`
-						// 	}
-						// 	data := UsernameData{
-						// 		Username: client.Username,
-						// 	}
-						// 	bytes, err := utils.EncodeJSON(data)
-						// 	if err != nil {
-						// 		slog.Error("Could not encode json while broadcasting cliennt disconnect")
-						// 	} else {
-						// 		event.Data = bytes
-						// 		c.SendEvent(&event)
-						// 	}
-						// }
-					}
-				}
-			}
-			if client.Conn.Close() != nil {
-				slog.Error("Could not close connection")
-			}
-			delete(hub.Clients, client.Username)
-			close(client.Event)
+			handler.OnUnregister(client)
 
 		case event := <-hub.Broadcast:
-			if event.RoomCode != "" {
-				if _, ok := hub.Rooms[event.RoomCode]; ok {
-					for _, client := range hub.Rooms[event.RoomCode].Clients {
-						client.Event <- event
-					}
-				}
+			if room, ok := hub.Rooms[event.RoomCode]; ok {
+				room.Broadcast(event)
 			}
+
+		case <-hub.wake:
+			hub.runQueued()
 		}
 	}
 }
+
+// Do runs f on the hub goroutine and waits for it. It must not be called from
+// the hub goroutine, nor from a room goroutine the hub may be waiting on
+func (hub *Hub) Do(f func()) bool {
+	return hub.do(f)
+}
+
+// Post queues f on the hub goroutine without waiting for it, room goroutines
+// use it to reach the hub
+func (hub *Hub) Post(f func()) bool {
+	return hub.post(f)
+}
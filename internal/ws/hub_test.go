@@ -0,0 +1,31 @@
+package ws
+
+import "testing"
+
+func TestHubIndexesConnectionsByUser(t *testing.T) {
+	hub := NewHub()
+	first := &Client{Username: "fred"}
+	second := &Client{Username: "fred"}
+	hub.AddClient(first)
+	hub.AddClient(second)
+
+	if first.ID == "" || first.ID == second.ID {
+		t.Fatalf("expected connections to get their own IDs but got: %q and %q", first.ID, second.ID)
+	}
+	if len(hub.Clients) != 2 {
+		t.Errorf("expected 2 clients but got: %d", len(hub.Clients))
+	}
+	if conns := hub.Connections("fred"); len(conns) != 2 {
+		t.Errorf("expected 2 connections but got: %d", len(conns))
+	}
+
+	if hub.RemoveClient(first) {
+		t.Errorf("expected fred to still be connected")
+	}
+	if !hub.RemoveClient(second) {
+		t.Errorf("expected fred to be gone with the last connection")
+	}
+	if len(hub.Clients) != 0 || len(hub.Connections("fred")) != 0 {
+		t.Errorf("expected no connections left but got: %v", hub.Clients)
+	}
+}
@@ -0,0 +1,54 @@
+package ws
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ProtocolVersion is the version of the events sent over the sockets, it goes
+// up whenever an event changes in a way older clients can not read. The
+// schema of the events is in the protocol package
+const ProtocolVersion = 1
+
+const (
+	// ProtocolVersionParam is the query parameter clients give the version
+	// they speak in when opening a socket
+	ProtocolVersionParam = "v"
+
+	// CloseProtocolMismatch closes the socket of a client speaking another
+	// version, it has to reload to get the current one
+	CloseProtocolMismatch = 4001
+)
+
+// CheckProtocolVersion returns an error unless the request was made by a
+// client speaking ProtocolVersion
+func CheckProtocolVersion(r *http.Request) error {
+	param := r.URL.Query().Get(ProtocolVersionParam)
+	version, err := strconv.Atoi(param)
+	if err != nil || version != ProtocolVersion {
+		return fmt.Errorf("protocol version %d expected but got: %q", ProtocolVersion, param)
+	}
+	return nil
+}
+
+// Accept upgrades the request to a websocket if the client speaks
+// ProtocolVersion. The socket of one that does not is closed with
+// CloseProtocolMismatch, browsers can not read the status of a refused
+// upgrade but they do get the close code
+func Accept(upgrader *websocket.Upgrader, w http.ResponseWriter, r *http.Request) (*websocket.Conn, error) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckProtocolVersion(r); err != nil {
+		message := websocket.FormatCloseMessage(CloseProtocolMismatch, err.Error())
+		conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
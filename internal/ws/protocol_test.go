@@ -0,0 +1,59 @@
+package ws
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestAcceptChecksTheProtocolVersion(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Accept(&upgrader, w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.WriteMessage(websocket.TextMessage, []byte("welcome"))
+	}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	tests := []struct {
+		name     string
+		query    string
+		accepted bool
+	}{
+		{"Current", "?v=" + strconv.Itoa(ProtocolVersion), true},
+		{"Older", "?v=" + strconv.Itoa(ProtocolVersion-1), false},
+		{"Missing", "", false},
+		{"Garbage", "?v=latest", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, _, err := websocket.DefaultDialer.Dial(url+tt.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+			_, message, err := conn.ReadMessage()
+
+			var closeErr *websocket.CloseError
+			switch {
+			case tt.accepted && err != nil:
+				t.Errorf("expected the socket to be accepted but got: %v", err)
+			case tt.accepted && string(message) != "welcome":
+				t.Errorf("expected welcome but got: %s", message)
+			case !tt.accepted && (!errors.As(err, &closeErr) || closeErr.Code != CloseProtocolMismatch):
+				t.Errorf("expected the socket to be closed with %d but got: %v", CloseProtocolMismatch, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package ws
+
+import (
+	"slices"
+)
+
+const (
+	// replay_buffer_size is how many of its last events a room keeps for the
+	// clients that lost their connection
+	replay_buffer_size = 256
+	// max_replay_events is the most events a client is replayed, more would
+	// fill its send queue and get it disconnected again, see sendQueueSize
+	max_replay_events = sendQueueSize / 2
+)
+
+// replayBuffer numbers the events sent to a room and keeps the last ones, it
+// belongs to the room goroutine
+type replayBuffer struct {
+	entries []replayEntry
+	start   int
+	seq     uint64
+}
+
+// replayEntry is a sent event and who it was for, to is empty for the whole
+// room
+type replayEntry struct {
+	event  Event
+	to     string
+	except []string
+}
+
+func newReplayBuffer(size int) *replayBuffer {
+	return &replayBuffer{entries: make([]replayEntry, 0, size)}
+}
+
+// add returns a copy of the event with the next sequence number
+func (b *replayBuffer) add(event *Event, to string, except []string) *Event {
+	b.seq++
+	stamped := *event
+	stamped.Seq = b.seq
+
+	entry := replayEntry{event: stamped, to: to, except: except}
+	if len(b.entries) < cap(b.entries) {
+		b.entries = append(b.entries, entry)
+	} else {
+		b.entries[b.start] = entry
+		b.start = (b.start + 1) % len(b.entries)
+	}
+	return &stamped
+}
+
+// since returns the events after seq that were sent to username, ok is false
+// if some of them are no longer kept
+func (b *replayBuffer) since(seq uint64, username string) (events []*Event, ok bool) {
+	if seq > b.seq {
+		return nil, false
+	}
+	oldest := b.seq + 1 - uint64(len(b.entries))
+	if seq+1 < oldest {
+		return nil, false
+	}
+	for i := range len(b.entries) {
+		entry := b.entries[(b.start+i)%len(b.entries)]
+		if entry.event.Seq <= seq {
+			continue
+		}
+		if entry.to != "" && entry.to != username {
+			continue
+		}
+		if slices.Contains(entry.except, username) {
+			continue
+		}
+		event := entry.event
+		events = append(events, &event)
+	}
+	return events, true
+}
+
+// Seq is the sequence number of the last event sent to the room
+func (room *Room) Seq() uint64 {
+	return room.history.seq
+}
+
+// SendTo sends the event to every connection of the player if it is in the
+// room, the event is numbered and kept for replay. Every connection gets the
+// events of the room in the order they are numbered, so the last sequence
+// number a client got is where it resumes from, see Replay
+func (room *Room) SendTo(username string, event *Event) bool {
+	conns, ok := room.connections[username]
+	if !ok {
+		return false
+	}
+	event = room.history.add(event, username, nil)
+	for _, c := range conns {
+		c.SendEvent(event)
+	}
+	return true
+}
+
+// SendAll sends the event to the players and the spectators of the room but
+// the usernames in except, the event is numbered and kept for replay
+func (room *Room) SendAll(event *Event, except ...string) {
+	event = room.history.add(event, "", except)
+	for _, c := range room.Members() {
+		if !slices.Contains(except, c.Username) {
+			c.SendEvent(event)
+		}
+	}
+}
+
+// Reply sends the event to the client alone, it is not kept for replay. It
+// carries the sequence number of the last event of the room, replies with
+// the whole state of the game let the client resume from there
+func (room *Room) Reply(client *Client, event *Event) {
+	reply := *event
+	reply.Seq = room.history.seq
+	client.SendEvent(&reply)
+}
+
+// Stream sends the event to every member of the room but the usernames in
+// except, it can be called from any goroutine. It is meant for events that
+// the next of their kind outdates, like positions, so they are neither
+// numbered nor kept for replay
+func (room *Room) Stream(event *Event, except ...string) {
+	room.Post(func() {
+		for _, c := range room.Members() {
+			if !slices.Contains(except, c.Username) {
+				c.SendEvent(event)
+			}
+		}
+	})
+}
+
+// Replay sends the client the events it missed after seq, in order. It
+// returns false if some of them are no longer kept or there are more than
+// max_replay_events, the client needs the whole state of the game then
+func (room *Room) Replay(client *Client, seq uint64) bool {
+	events, ok := room.history.since(seq, client.Username)
+	if !ok || len(events) > max_replay_events {
+		return false
+	}
+	if len(events) == 0 {
+		return true
+	}
+	for _, event := range events {
+		client.SendEvent(event)
+	}
+	return true
+}
@@ -0,0 +1,139 @@
+package ws
+
+import (
+	"testing"
+	"time"
+)
+
+func newReplayTestRoom(t *testing.T) (*Room, *Client, *Client) {
+	room := NewRoom("ABCD", 2)
+	p1 := &Client{Username: "p1", Event: make(chan *Event, replay_buffer_size*2)}
+	p2 := &Client{Username: "p2", Event: make(chan *Event, replay_buffer_size*2)}
+	for _, c := range []*Client{p1, p2} {
+		if err := room.AddClient(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return room, p1, p2
+}
+
+func receive(t *testing.T, client *Client, n int) []*Event {
+	events := make([]*Event, 0, n)
+	timeout := time.After(2 * time.Second)
+	for len(events) < n {
+		select {
+		case event := <-client.Event:
+			events = append(events, event)
+		case <-timeout:
+			t.Fatalf("expected %s to get %d events but got: %d", client.Username, n, len(events))
+		}
+	}
+	return events
+}
+
+func TestRoomNumbersEvents(t *testing.T) {
+	room, p1, p2 := newReplayTestRoom(t)
+
+	event := NewEvent(1, room.Code)
+	room.SendAll(&event)
+	room.SendTo("p2", &event)
+	room.SendAll(&event, "p2")
+
+	if room.Seq() != 3 {
+		t.Errorf("expected the room to be at 3 but got: %d", room.Seq())
+	}
+	if event.Seq != 0 {
+		t.Errorf("expected the event given to be left alone but got: %d", event.Seq)
+	}
+	seen := map[uint64]bool{}
+	for _, e := range receive(t, p1, 2) {
+		seen[e.Seq] = true
+	}
+	if !seen[1] || !seen[3] {
+		t.Errorf("expected p1 to get events 1 and 3 but got: %v", seen)
+	}
+	if e := receive(t, p2, 2); e[0].Seq+e[1].Seq != 3 {
+		t.Errorf("expected p2 to get events 1 and 2 but got: %d, %d", e[0].Seq, e[1].Seq)
+	}
+}
+
+func TestRoomReplaysGap(t *testing.T) {
+	room, _, p2 := newReplayTestRoom(t)
+	for i := 0; i < 5; i++ {
+		event := NewEvent(EventType(i), room.Code)
+		if i == 3 {
+			room.SendTo("p1", &event)
+			continue
+		}
+		room.SendAll(&event)
+	}
+	receive(t, p2, 4)
+
+	if !room.Replay(p2, 2) {
+		t.Fatalf("expected the gap to be replayed")
+	}
+	events := receive(t, p2, 2)
+	if events[0].Seq != 3 || events[1].Seq != 5 {
+		t.Errorf("expected events 3 and 5 in order but got: %d, %d", events[0].Seq, events[1].Seq)
+	}
+
+	if room.Replay(p2, 6) {
+		t.Errorf("expected a sequence the room never reached to be refused")
+	}
+}
+
+func TestRoomForgetsOldEvents(t *testing.T) {
+	room, _, p2 := newReplayTestRoom(t)
+	for i := 0; i < replay_buffer_size+10; i++ {
+		event := NewEvent(1, room.Code)
+		room.SendAll(&event)
+	}
+	receive(t, p2, replay_buffer_size+10)
+
+	if room.Replay(p2, 5) {
+		t.Errorf("expected a gap bigger than the buffer to be refused")
+	}
+	if room.Replay(p2, 10) {
+		t.Errorf("expected more than %d events to be refused", max_replay_events)
+	}
+	last := uint64(replay_buffer_size + 10)
+	if !room.Replay(p2, last-max_replay_events) {
+		t.Fatalf("expected the last %d events to be replayed", max_replay_events)
+	}
+	events := receive(t, p2, max_replay_events)
+	if events[0].Seq != last-max_replay_events+1 || events[len(events)-1].Seq != last {
+		t.Errorf("expected events %d to %d but got: %d to %d", last-max_replay_events+1, last, events[0].Seq, events[len(events)-1].Seq)
+	}
+}
+
+func TestResumeFromTheLastEventGot(t *testing.T) {
+	room, p1, p2 := newReplayTestRoom(t)
+
+	event := NewEvent(1, room.Code)
+	for i := range 40 {
+		switch i % 3 {
+		case 0:
+			room.SendAll(&event)
+		case 1:
+			room.SendTo("p2", &event)
+		default:
+			room.SendAll(&event, "p2")
+		}
+	}
+
+	// p1 loses its connection after half of what it was sent, the rest is
+	// still on its way and never read
+	got := receive(t, p1, 27)
+	lastSeq := got[13].Seq
+	back := &Client{Username: "p1", Event: make(chan *Event, replay_buffer_size)}
+	if !room.Replay(back, lastSeq) {
+		t.Fatalf("expected events after %d to be kept", lastSeq)
+	}
+	replayed := receive(t, back, 13)
+	for i, event := range replayed {
+		if event.Seq != got[14+i].Seq {
+			t.Fatalf("expected event %d to be replayed but got: %d", got[14+i].Seq, event.Seq)
+		}
+	}
+	receive(t, p2, 27)
+}
@@ -0,0 +1,109 @@
+package ws
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRoomAddSpectator(t *testing.T) {
+	room := NewRoom("ABCD", 2)
+	player := &Client{Username: "player"}
+	spectator := &Client{Username: "spectator"}
+
+	if err := room.AddClient(player); err != nil {
+		t.Fatalf("expected player to join but got: %v", err)
+	}
+	if err := room.AddSpectator(spectator); err != nil {
+		t.Fatalf("expected spectator to join but got: %v", err)
+	}
+	if !spectator.Spectating || spectator.RoomCode != room.Code {
+		t.Errorf("expected spectator to be watching %s but got: %+v", room.Code, spectator)
+	}
+	if room.SpectatorCount() != 1 {
+		t.Errorf("expected 1 spectator but got: %d", room.SpectatorCount())
+	}
+	if len(room.Members()) != 2 {
+		t.Errorf("expected 2 members but got: %d", len(room.Members()))
+	}
+
+	if err := room.AddSpectator(player); !errors.Is(err, ErrClientAlreadyInRoom) {
+		t.Errorf("expected ErrClientAlreadyInRoom for a player but got: %v", err)
+	}
+	if err := room.AddClient(spectator); !errors.Is(err, ErrClientAlreadyInRoom) {
+		t.Errorf("expected ErrClientAlreadyInRoom for a spectator but got: %v", err)
+	}
+
+	if err := room.RemoveSpectator(spectator); err != nil {
+		t.Fatalf("expected spectator to leave but got: %v", err)
+	}
+	if spectator.Spectating || spectator.RoomCode != "" || room.SpectatorCount() != 0 {
+		t.Errorf("expected spectator to be gone but got: %+v", spectator)
+	}
+}
+
+func TestRoomSpectatorsDoNotFillRoom(t *testing.T) {
+	room := NewRoom("ABCD", 1)
+	if err := room.AddSpectator(&Client{Username: "spectator"}); err != nil {
+		t.Fatalf("expected spectator to join but got: %v", err)
+	}
+	if err := room.AddClient(&Client{Username: "player"}); err != nil {
+		t.Errorf("expected player to join but got: %v", err)
+	}
+}
+
+func TestRoomSpectatingDisabled(t *testing.T) {
+	room := NewRoom("ABCD", 2)
+	room.AllowSpectators = false
+
+	err := room.AddSpectator(&Client{Username: "spectator"})
+	if !errors.Is(err, ErrSpectatingDisabled) {
+		t.Errorf("expected ErrSpectatingDisabled but got: %v", err)
+	}
+}
+
+func TestRoomKeepsPlayerWhileConnected(t *testing.T) {
+	room := NewRoom("ABCD", 1)
+	first := &Client{ID: "1", Username: "player", Event: make(chan *Event, 4)}
+	second := &Client{ID: "2", Username: "player", Event: make(chan *Event, 4)}
+
+	if err := room.AddClient(first); err != nil {
+		t.Fatalf("expected player to join but got: %v", err)
+	}
+	if err := room.AddClient(second); err != nil {
+		t.Fatalf("expected another connection of the player to join a full room but got: %v", err)
+	}
+	if err := room.AddClient(second); !errors.Is(err, ErrClientAlreadyInRoom) {
+		t.Errorf("expected ErrClientAlreadyInRoom for the same connection but got: %v", err)
+	}
+	if len(room.Members()) != 2 {
+		t.Errorf("expected 2 members but got: %d", len(room.Members()))
+	}
+
+	event := NewEvent(EventTypePong, room.Code)
+	room.SendTo("player", &event)
+	for _, c := range []*Client{first, second} {
+		select {
+		case <-c.Event:
+		case <-time.After(time.Second):
+			t.Errorf("expected connection %s to get the event", c.ID)
+		}
+	}
+
+	if err := room.RemoveClient(first); err != nil {
+		t.Fatalf("expected connection to leave but got: %v", err)
+	}
+	if !room.HasPlayer("player") || room.Clients["player"] != second {
+		t.Errorf("expected player to stay through its other connection but got: %+v", room.Clients)
+	}
+	if room.HasClient(first) || first.RoomCode != "" {
+		t.Errorf("expected first connection to be gone but got: %+v", first)
+	}
+
+	if err := room.RemoveClient(second); err != nil {
+		t.Fatalf("expected connection to leave but got: %v", err)
+	}
+	if room.HasPlayer("player") {
+		t.Errorf("expected player to be gone with its last connection")
+	}
+}